@@ -0,0 +1,377 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sibellavia/dory/internal/doryfile"
+	"gopkg.in/yaml.v3"
+)
+
+// ADR styles.
+const (
+	adrMADR   = "madr"
+	adrNygard = "nygard"
+)
+
+var (
+	adrFileName  = regexp.MustCompile(`^(\d+)-.+\.md$`)
+	adrTitleNum  = regexp.MustCompile(`^\d+\.\s+`)
+	adrDoryID    = regexp.MustCompile(`(?m)^[ \t]*<!--\s*dory-id:\s*(\S+)\s*-->[ \t]*\n?`)
+	adrMetaLine  = regexp.MustCompile(`^[*-]\s+([A-Za-z][A-Za-z ]*):\s*(.*)$`)
+	adrDateLine  = regexp.MustCompile(`^Date:\s*(\S+)`)
+	adrLink      = regexp.MustCompile(`\((?:\./)?0*(\d+)-[^)\s]*\.md\)|\bADR[- ]?0*(\d+)\b`)
+	adrRelLine   = regexp.MustCompile(`(?i)supersed|amend|replace`)
+	adrSlugStrip = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// adrRecord is one ADR file read as a decision.
+type adrRecord struct {
+	File   string
+	Number int
+	Style  string
+	Title  string
+	Status string
+	Date   time.Time
+	DoryID string
+	// Body is the decision body: the title heading, a Status section, and
+	// the ADR's own sections.
+	Body string
+	// Links are the numbers of the ADRs this one supersedes, amends, or is
+	// superseded by.
+	Links []int
+}
+
+// readADRDir parses every numbered ADR file (NNNN-title.md) in dir, in
+// number order. A missing directory has no ADRs.
+func readADRDir(dir string) ([]*adrRecord, error) {
+	files, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var records []*adrRecord
+	for _, f := range files {
+		if f.IsDir() || !adrFileName.MatchString(f.Name()) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, f.Name()))
+		if err != nil {
+			return nil, err
+		}
+		record, err := parseADR(f.Name(), string(data))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Name(), err)
+		}
+		records = append(records, record)
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].Number < records[j].Number })
+	return records, nil
+}
+
+// parseADR reads an ADR in MADR (YAML frontmatter or "* Status:" bullets)
+// or Nygard ("Date:" line and a Status section) format.
+func parseADR(name, content string) (*adrRecord, error) {
+	record := &adrRecord{File: name}
+	if m := adrFileName.FindStringSubmatch(name); m != nil {
+		record.Number, _ = strconv.Atoi(m[1])
+	}
+
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	if m := adrDoryID.FindStringSubmatch(content); m != nil {
+		record.DoryID = m[1]
+		content = adrDoryID.ReplaceAllString(content, "")
+	}
+	front, rest, err := parseFrontmatter(content)
+	if err != nil {
+		return nil, err
+	}
+
+	var meta []string
+	if len(front) > 0 {
+		record.Style = adrMADR
+		keys := make([]string, 0, len(front))
+		for key := range front {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			value := adrFrontValue(front[key])
+			switch strings.ToLower(key) {
+			case "status":
+				record.Status = value
+			case "date":
+				record.Date = adrDate(value)
+			case "dory-id":
+				record.DoryID = value
+			default:
+				// Keep other MADR metadata (deciders, consulted, ...) in the body.
+				meta = append(meta, fmt.Sprintf("* %s: %s", strings.ToUpper(key[:1])+key[1:], value))
+			}
+		}
+	}
+
+	lines := strings.Split(rest, "\n")
+	var body []string
+	titled, inHeader := false, true
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if !titled {
+			if strings.HasPrefix(trimmed, "# ") {
+				record.Title = adrTitleNum.ReplaceAllString(strings.TrimSpace(trimmed[2:]), "")
+				titled = true
+			}
+			continue
+		}
+		if inHeader {
+			if strings.HasPrefix(trimmed, "## ") {
+				inHeader = false
+			} else if m := adrDateLine.FindStringSubmatch(trimmed); m != nil && !adrDate(m[1]).IsZero() {
+				record.Date = adrDate(m[1])
+				if record.Style == "" {
+					record.Style = adrNygard
+				}
+				continue
+			} else if m := adrMetaLine.FindStringSubmatch(trimmed); m != nil {
+				switch strings.ToLower(m[1]) {
+				case "status":
+					record.Status = strings.TrimSpace(m[2])
+					record.Style = adrMADR
+					continue
+				case "date":
+					if date := adrDate(m[2]); !date.IsZero() {
+						record.Date = date
+						record.Style = adrMADR
+						continue
+					}
+				}
+			}
+		}
+		body = append(body, line)
+	}
+	if !titled {
+		return nil, fmt.Errorf("no '# Title' heading")
+	}
+
+	text := strings.TrimSpace(strings.Join(body, "\n"))
+	if len(meta) > 0 {
+		text = strings.TrimSpace(strings.Join(meta, "\n") + "\n\n" + text)
+	}
+	if section, ok := adrSection(text, "Status"); ok {
+		if record.Status == "" {
+			record.Status = adrFirstParagraph(section)
+		}
+		if record.Style == "" {
+			record.Style = adrNygard
+		}
+		record.Links = adrLinks(section, record.Number)
+	} else if record.Status != "" {
+		text = adrInsertSection(text, "## Status\n\n"+record.Status)
+	}
+	if record.Style == "" {
+		record.Style = adrMADR
+	}
+	for _, line := range strings.Split(text, "\n") {
+		if adrRelLine.MatchString(line) {
+			record.Links = append(record.Links, adrLinks(line, record.Number)...)
+		}
+	}
+	record.Links = adrUniqueInts(record.Links)
+	record.Body = "# " + record.Title + "\n\n" + text + "\n"
+	return record, nil
+}
+
+// renderADR writes a decision as an ADR file. The Status section of the
+// body becomes MADR frontmatter, or stays a section in Nygard style; the
+// dory ID is recorded so later exports and imports find the same file.
+func renderADR(entry *doryfile.Entry, number int, style string) string {
+	content := adrStripTitle(entry.Body)
+	section, hasStatus := adrSection(content, "Status")
+	status := adrFirstParagraph(section)
+	date := entry.Created.UTC().Format("2006-01-02")
+
+	var b strings.Builder
+	if style == adrNygard {
+		fmt.Fprintf(&b, "# %d. %s\n\nDate: %s\n\n", number, entry.Oneliner, date)
+		if !hasStatus {
+			b.WriteString("## Status\n\nAccepted\n\n")
+		}
+		if content != "" {
+			b.WriteString(content + "\n\n")
+		}
+		fmt.Fprintf(&b, "<!-- dory-id: %s -->\n", entry.ID)
+		return b.String()
+	}
+
+	if status == "" {
+		status = "accepted"
+	}
+	if hasStatus && !strings.Contains(strings.TrimSpace(section), "\n\n") {
+		content = adrRemoveSection(content, "Status")
+	}
+	fmt.Fprintf(&b, "---\nstatus: %s\ndate: %s\ndory-id: %s\n---\n\n# %s\n", adrYAMLValue(status), date, entry.ID, entry.Oneliner)
+	if content != "" {
+		b.WriteString("\n" + content + "\n")
+	}
+	return b.String()
+}
+
+// adrFileFor names the file for ADR number n.
+func adrFileFor(number int, title string) string {
+	slug := strings.Trim(adrSlugStrip.ReplaceAllString(strings.ToLower(title), "-"), "-")
+	if len(slug) > 60 {
+		slug = strings.TrimRight(slug[:60], "-")
+		if i := strings.LastIndex(slug, "-"); i > 30 {
+			slug = slug[:i]
+		}
+	}
+	if slug == "" {
+		slug = "decision"
+	}
+	return fmt.Sprintf("%04d-%s.md", number, slug)
+}
+
+// adrSection returns the text under a "## name" heading, up to the next
+// heading of the same or higher level.
+func adrSection(text, name string) (string, bool) {
+	start, end, ok := adrSectionBounds(text, name)
+	if !ok {
+		return "", false
+	}
+	lines := strings.Split(text, "\n")
+	return strings.TrimSpace(strings.Join(lines[start+1:end], "\n")), true
+}
+
+func adrRemoveSection(text, name string) string {
+	start, end, ok := adrSectionBounds(text, name)
+	if !ok {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	return strings.TrimSpace(strings.Join(append(lines[:start:start], lines[end:]...), "\n"))
+}
+
+func adrSectionBounds(text, name string) (int, int, bool) {
+	lines := strings.Split(text, "\n")
+	start := -1
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if start < 0 {
+			if strings.HasPrefix(trimmed, "## ") && strings.EqualFold(strings.TrimSpace(trimmed[3:]), name) {
+				start = i
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "# ") || strings.HasPrefix(trimmed, "## ") {
+			return start, i, true
+		}
+	}
+	return start, len(lines), start >= 0
+}
+
+// adrInsertSection puts section before the first "##" heading, after any
+// leading metadata, so re-importing an exported file gives the same body.
+func adrInsertSection(text, section string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "## ") {
+			head := strings.TrimSpace(strings.Join(lines[:i], "\n"))
+			tail := strings.Join(lines[i:], "\n")
+			if head == "" {
+				return section + "\n\n" + tail
+			}
+			return head + "\n\n" + section + "\n\n" + tail
+		}
+	}
+	if strings.TrimSpace(text) == "" {
+		return section
+	}
+	return strings.TrimSpace(text) + "\n\n" + section
+}
+
+func adrStripTitle(body string) string {
+	body = strings.TrimSpace(body)
+	if strings.HasPrefix(body, "# ") {
+		_, rest, _ := strings.Cut(body, "\n")
+		return strings.TrimSpace(rest)
+	}
+	return body
+}
+
+func adrFirstParagraph(text string) string {
+	first, _, _ := strings.Cut(strings.TrimSpace(text), "\n\n")
+	return strings.Join(strings.Fields(first), " ")
+}
+
+func adrLinks(text string, self int) []int {
+	var links []int
+	for _, m := range adrLink.FindAllStringSubmatch(text, -1) {
+		digits := m[1]
+		if digits == "" {
+			digits = m[2]
+		}
+		if n, err := strconv.Atoi(digits); err == nil && n != self {
+			links = append(links, n)
+		}
+	}
+	return links
+}
+
+func adrUniqueInts(values []int) []int {
+	seen := make(map[int]bool, len(values))
+	var out []int
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func adrDate(value string) time.Time {
+	value = strings.TrimSpace(value)
+	if len(value) >= 10 {
+		if date, err := time.Parse("2006-01-02", value[:10]); err == nil {
+			return date
+		}
+	}
+	return time.Time{}
+}
+
+func adrFrontValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(v)
+	case time.Time:
+		return v.UTC().Format("2006-01-02")
+	case []interface{}:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			parts = append(parts, adrFrontValue(item))
+		}
+		return strings.Join(parts, ", ")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// adrYAMLValue quotes s only when YAML would not read it back as the same
+// plain string.
+func adrYAMLValue(s string) string {
+	out, err := yaml.Marshal(s)
+	if err != nil {
+		return s
+	}
+	return strings.TrimSuffix(string(out), "\n")
+}
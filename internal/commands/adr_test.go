@@ -0,0 +1,147 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sibellavia/dory/internal/store"
+)
+
+const nygardADR = `# 2. Use MySQL
+
+Date: 2023-05-01
+
+## Status
+
+Superseded by [3. Use Postgres](0003-use-postgres.md)
+
+## Context
+
+Need a database.
+`
+
+const madrADR = `---
+status: accepted
+date: 2024-01-05
+deciders: alice, bob
+---
+# Use Postgres
+
+## Context and Problem Statement
+
+MySQL hurt us.
+`
+
+func TestParseADR(t *testing.T) {
+	nygard, err := parseADR("0002-use-mysql.md", nygardADR)
+	if err != nil {
+		t.Fatalf("parse nygard: %v", err)
+	}
+	if nygard.Style != adrNygard || nygard.Number != 2 || nygard.Title != "Use MySQL" || nygard.Date.Format("2006-01-02") != "2023-05-01" {
+		t.Fatalf("unexpected nygard record %+v", nygard)
+	}
+	if len(nygard.Links) != 1 || nygard.Links[0] != 3 {
+		t.Fatalf("expected a link to ADR 3, got %v", nygard.Links)
+	}
+
+	madr, err := parseADR("0003-use-postgres.md", madrADR)
+	if err != nil {
+		t.Fatalf("parse madr: %v", err)
+	}
+	want := "# Use Postgres\n\n* Deciders: alice, bob\n\n## Status\n\naccepted\n\n## Context and Problem Statement\n\nMySQL hurt us.\n"
+	if madr.Style != adrMADR || madr.Status != "accepted" || madr.Body != want {
+		t.Fatalf("unexpected madr record %+v\nbody:\n%s", madr, madr.Body)
+	}
+
+	if _, err := parseADR("0004-empty.md", "no title here\n"); err == nil {
+		t.Fatal("expected an error for an ADR without a title")
+	}
+}
+
+func TestADRRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	s := store.New(filepath.Join(dir, ".dory"))
+	defer s.Close()
+	if err := s.Init("project", ""); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	adrDir := filepath.Join(dir, "adr")
+	if err := os.MkdirAll(adrDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{"0002-use-mysql.md": nygardADR, "0003-use-postgres.md": madrADR} {
+		if err := os.WriteFile(filepath.Join(adrDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	importDir := func() *ADRResult {
+		t.Helper()
+		records, err := readADRDir(adrDir)
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		result, err := importADRs(s, records, "adr")
+		if err != nil {
+			t.Fatalf("import: %v", err)
+		}
+		return result
+	}
+	exportDir := func() *ADRResult {
+		t.Helper()
+		items, err := s.List(store.ListFilter{Type: "decision"})
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		result, err := exportADRs(s, items, adrDir, "")
+		if err != nil {
+			t.Fatalf("export: %v", err)
+		}
+		return result
+	}
+	statuses := func(result *ADRResult) string {
+		var out []string
+		for _, file := range result.Files {
+			out = append(out, file.Status)
+		}
+		return strings.Join(out, ",")
+	}
+
+	first := importDir()
+	if got := statuses(first); got != "created,created" {
+		t.Fatalf("expected two created decisions, got %s", got)
+	}
+	mysql, err := s.GetEntry(first.Files[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mysql.Refs) != 1 || mysql.Refs[0] != first.Files[1].ID {
+		t.Fatalf("expected the superseded ADR to ref its successor, got %v", mysql.Refs)
+	}
+	if got := statuses(importDir()); got != "unchanged,unchanged" {
+		t.Fatalf("expected a second import to change nothing, got %s", got)
+	}
+
+	if _, err := s.Decide("Adopt gRPC", "api", "", "", nil); err != nil {
+		t.Fatalf("decide: %v", err)
+	}
+	exported := exportDir()
+	if got := statuses(exported); got != "written,written,written" {
+		t.Fatalf("expected every file written, got %s", got)
+	}
+	if exported.Files[2].File != "0004-adopt-grpc.md" {
+		t.Fatalf("expected the new decision to take the next number, got %s", exported.Files[2].File)
+	}
+	data, err := os.ReadFile(filepath.Join(adrDir, "0002-use-mysql.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "# 2. Use MySQL\n\nDate: 2023-05-01\n") || !strings.Contains(string(data), "dory-id: "+first.Files[0].ID) {
+		t.Fatalf("expected the Nygard file to keep its style and gain its dory ID:\n%s", data)
+	}
+	if got := statuses(exportDir()); got != "unchanged,unchanged,unchanged" {
+		t.Fatalf("expected a second export to change nothing, got %s", got)
+	}
+}
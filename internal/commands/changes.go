@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// gitChangedPaths returns the paths git reports as changed in the working
+// tree: staged, unstaged and untracked files, plus files changed since base
+// when base is set. Outside a git work tree it returns no paths.
+func gitChangedPaths(base string) ([]string, error) {
+	if _, err := runGit("rev-parse", "--is-inside-work-tree"); err != nil {
+		if base != "" {
+			return nil, fmt.Errorf("--base requires a git work tree")
+		}
+		return nil, nil
+	}
+
+	status, err := runGit("status", "--porcelain", "-z", "--untracked-files=all")
+	if err != nil {
+		return nil, err
+	}
+	paths := parsePorcelain(status)
+
+	if base != "" {
+		diff, err := runGit("diff", "--name-only", "-z", base+"...HEAD")
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, splitNUL(diff)...)
+	}
+	return paths, nil
+}
+
+// parsePorcelain extracts paths from `git status --porcelain -z` output,
+// where paths are NUL-terminated and never quoted. Renames and copies report
+// the new path, which comes first; the original path follows as its own
+// field and is skipped.
+func parsePorcelain(out string) []string {
+	var paths []string
+	fields := splitNUL(out)
+	for i := 0; i < len(fields); i++ {
+		entry := fields[i]
+		if len(entry) < 4 {
+			continue
+		}
+		paths = append(paths, entry[3:])
+		if entry[0] == 'R' || entry[0] == 'C' {
+			i++
+		}
+	}
+	return paths
+}
+
+// splitNUL splits NUL-terminated git output into its non-empty fields.
+func splitNUL(out string) []string {
+	var fields []string
+	for _, field := range strings.Split(out, "\x00") {
+		if field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+func runGit(args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return stdout.String(), nil
+}
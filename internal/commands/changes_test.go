@@ -0,0 +1,14 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePorcelain(t *testing.T) {
+	out := " M internal/auth/token.go\x00A  docs/new.md\x00R  pkg/new.go\x00old.go\x00?? with space.txt\x00 M caf\u00e9 \"quoted\".md\x00?? a -> b.txt\x00"
+	want := []string{"internal/auth/token.go", "docs/new.md", "pkg/new.go", "with space.txt", "caf\u00e9 \"quoted\".md", "a -> b.txt"}
+	if got := parsePorcelain(out); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,171 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sibellavia/dory/internal/plugin"
+	"github.com/sibellavia/dory/internal/store"
+	"github.com/spf13/cobra"
+)
+
+// Dynamic shell completion. Completion runs on every tab press, so these
+// helpers never print or exit: a missing store or broken plugin just means
+// no candidates.
+
+// completionStack opens the stores the command would read, or returns nil
+// when there are none.
+func completionStack() *store.Stack {
+	if validateScope(scopeFlag) != nil || !loadStores() || doryRoot == "" {
+		return nil
+	}
+	return openStack()
+}
+
+// completeIDs completes item IDs and aliases, described by their oneliners,
+// for the first max positional arguments (0 means any number).
+func completeIDs(max int) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		if max > 0 && len(args) >= max {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return itemCompletions("", toComplete, args), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeRefs completes the last ID in a comma-separated ref list.
+func completeRefs(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	cut := strings.LastIndex(toComplete, ",") + 1
+	done := strings.Split(toComplete[:cut], ",")
+	return itemCompletions(toComplete[:cut], toComplete[cut:], done), cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+}
+
+// itemCompletions lists "<prefix><id>\t<oneliner>" for items whose ID or
+// alias starts with partial, skipping IDs already given.
+func itemCompletions(prefix, partial string, skip []string) []cobra.Completion {
+	st := completionStack()
+	if st == nil {
+		return nil
+	}
+	defer st.Close()
+	items, err := st.List(store.ListFilter{})
+	if err != nil {
+		return nil
+	}
+
+	given := make(map[string]bool, len(skip))
+	for _, s := range skip {
+		given[strings.ToUpper(s)] = true
+	}
+	upper := strings.ToUpper(partial)
+	var completions []cobra.Completion
+	for _, item := range items {
+		if given[strings.ToUpper(item.ID)] || (item.Alias != "" && given[strings.ToUpper(item.Alias)]) {
+			continue
+		}
+		desc := truncateOneliner(item.Oneliner, 60)
+		if strings.HasPrefix(strings.ToUpper(item.ID), upper) {
+			completions = append(completions, cobra.CompletionWithDesc(prefix+item.ID, desc))
+		} else if item.Alias != "" && strings.HasPrefix(strings.ToUpper(item.Alias), upper) {
+			completions = append(completions, cobra.CompletionWithDesc(prefix+item.Alias, desc))
+		}
+	}
+	return completions
+}
+
+// completeTags completes existing tags with their item counts.
+func completeTags(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	st := completionStack()
+	if st == nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	defer st.Close()
+	topics, err := st.Topics()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var completions []cobra.Completion
+	for _, topic := range topics {
+		if strings.HasPrefix(strings.ToLower(topic.Name), strings.ToLower(toComplete)) {
+			completions = append(completions, cobra.CompletionWithDesc(topic.Name, fmt.Sprintf("%d items", topic.Count)))
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeCustomTypes completes plugin-defined types for `type create`.
+func completeCustomTypes(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 || validateScope(scopeFlag) != nil || !loadStores() {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	types, _, err := plugin.DiscoverCustomTypes(doryRoot)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var completions []cobra.Completion
+	for _, t := range types {
+		if !strings.HasPrefix(t.Name, toComplete) {
+			continue
+		}
+		desc := "from plugin " + t.Plugin
+		if !t.Enabled {
+			desc += " (disabled)"
+		}
+		completions = append(completions, cobra.CompletionWithDesc(t.Name, desc))
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completePlugins completes plugin names for the first argument.
+func completePlugins(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return pluginCompletions(args, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completePluginRun completes the plugin name, then that plugin's commands.
+func completePluginRun(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 1 {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+	return pluginCompletions(args, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// pluginCompletions lists plugin names when args is empty, otherwise the
+// commands of the plugin named by args[0].
+func pluginCompletions(args []string, toComplete string) []cobra.Completion {
+	if validateScope(scopeFlag) != nil || !loadStores() {
+		return nil
+	}
+	plugins, _, err := plugin.Discover(doryRoot)
+	if err != nil {
+		return nil
+	}
+
+	var completions []cobra.Completion
+	if len(args) == 0 {
+		for _, p := range plugins {
+			if !strings.HasPrefix(p.Name, toComplete) {
+				continue
+			}
+			desc := p.Description
+			if !p.Enabled {
+				desc = strings.TrimSpace(desc + " (disabled)")
+			}
+			completions = append(completions, cobra.CompletionWithDesc(p.Name, desc))
+		}
+		return completions
+	}
+	if selected := findPluginByName(plugins, args[0]); selected != nil {
+		for _, command := range selected.Capabilities.Commands {
+			if !strings.HasPrefix(command, toComplete) {
+				continue
+			}
+			completions = append(completions, cobra.CompletionWithDesc(command, "command of "+selected.Name))
+		}
+	}
+	return completions
+}
@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sibellavia/dory/internal/models"
+	"github.com/sibellavia/dory/internal/store"
+)
+
+func TestCompleteIDsAndTags(t *testing.T) {
+	dir := t.TempDir()
+	s := store.New(filepath.Join(dir, ".dory"))
+	if err := s.Init("project", ""); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	lesson, err := s.Learn("Retry on 429", "api", models.SeverityNormal, "", nil)
+	if err != nil {
+		t.Fatalf("learn: %v", err)
+	}
+	decision, err := s.Decide("Use Postgres", "db", "", "", nil)
+	if err != nil {
+		t.Fatalf("decide: %v", err)
+	}
+	s.Close()
+	t.Chdir(dir)
+
+	got, _ := completeIDs(1)(showCmd, nil, "l-")
+	if len(got) != 1 || got[0] != lesson+"\tRetry on 429" {
+		t.Fatalf("expected lesson completion, got %q", got)
+	}
+	if got, _ := completeIDs(1)(showCmd, []string{lesson}, ""); len(got) != 0 {
+		t.Fatalf("expected no completions after the ID, got %q", got)
+	}
+
+	got, _ = completeRefs(createCmd, nil, lesson+",")
+	if len(got) != 1 || !strings.HasPrefix(got[0], lesson+","+decision+"\t") {
+		t.Fatalf("expected the remaining ref after the comma, got %q", got)
+	}
+
+	got, _ = completeTags(listCmd, nil, "A")
+	if len(got) != 1 || got[0] != "api\t1 items" {
+		t.Fatalf("expected api tag, got %q", got)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/sibellavia/dory/internal/config"
 	"github.com/sibellavia/dory/internal/store"
 	"github.com/spf13/cobra"
 )
@@ -15,6 +16,7 @@ var contextCmd = &cobra.Command{
 
 READ MODE (no state flags):
   Returns essential context for starting an agent session:
+  - Pinned items (see dory pin)
   - Current session state (goal, progress, blockers, next steps)
   - Critical and high severity lessons
   - Recent items (last 7 days by default)
@@ -26,6 +28,11 @@ WRITE MODE (with state flags):
 Examples:
   dory context                              # Get context (read)
   dory context --tag auth                   # Include auth-related items
+  dory context --filter 'severity>=high'    # Only items matching an expression (see dory query)
+  dory context --budget 2000                # Best-ranked items that fit ~2000 tokens
+  dory context --budget 4000 --bodies       # Include bodies while they fit
+  dory context --for-changes                # Items relevant to working files and git changes
+  dory context --for-changes --base main    # Also files changed since main
   dory context --goal "Add auth" --progress "50%" --next "Add logout"  # Update state
   dory context --goal "Add auth" --next "Step 1" --next "Step 2"       # Multiple next steps`,
 	Run: func(cmd *cobra.Command, args []string) {
@@ -43,9 +50,17 @@ Examples:
 		tag, _ := cmd.Flags().GetString("tag")
 		recentDays, _ := cmd.Flags().GetInt("recent")
 		full, _ := cmd.Flags().GetBool("full")
-
-		s := store.New(doryRoot)
-		defer s.Close()
+		filter := resolveFilter(cmd)
+		budget, _ := cmd.Flags().GetInt("budget")
+		bodies, _ := cmd.Flags().GetBool("bodies")
+		if bodies && budget <= 0 {
+			CheckError(fmt.Errorf("--bodies requires --budget"))
+		}
+		forChanges, _ := cmd.Flags().GetBool("for-changes")
+		base, _ := cmd.Flags().GetString("base")
+		if base != "" && !forChanges {
+			CheckError(fmt.Errorf("--base requires --for-changes"))
+		}
 
 		// Check if any state flags provided (write mode)
 		hasStateFlags := goal != "" || progress != "" || blocker != "" ||
@@ -53,14 +68,43 @@ Examples:
 
 		if hasStateFlags {
 			// Write mode: update state first
+			s := store.New(doryRoot)
 			_, err := s.UpdateStatus(goal, progress, blocker, next, workingFiles, openQuestions)
 			CheckError(err)
+			CheckError(s.Close())
 		}
 
-		// Always return full context
-		result, err := s.Context(tag, recentDays, full)
+		stack := openStack()
+		defer stack.Close()
+
+		var result *store.ContextResult
+		var err error
+		if budget > 0 {
+			cfg, cfgErr := config.Load(doryRoot)
+			CheckError(cfgErr)
+			result, err = stack.BudgetedContext(store.ContextBudget{
+				Tokens:  budget,
+				Bodies:  bodies,
+				Topic:   tag,
+				Filter:  filter,
+				Ranking: cfg.Ranking(),
+			})
+		} else {
+			// Always return full context
+			result, err = stack.Context(tag, recentDays, full, filter)
+		}
 		CheckError(err)
 
+		if forChanges {
+			paths, err := gitChangedPaths(base)
+			CheckError(err)
+			if result.State != nil {
+				paths = append(paths, result.State.WorkingFiles...)
+			}
+			result.Changes, err = stack.ChangeContext(paths, filter)
+			CheckError(err)
+		}
+
 		OutputResult(cmd, result, func() {
 			printContext(result, hasStateFlags)
 		})
@@ -78,8 +122,18 @@ func printContext(ctx *store.ContextResult, updated bool) {
 		fmt.Println()
 	}
 
+	// Pinned items
+	if len(ctx.Pinned) > 0 {
+		fmt.Printf("PINNED (%d)\n", len(ctx.Pinned))
+		fmt.Println(strings.Repeat("─", 50))
+		for _, item := range ctx.Pinned {
+			fmt.Printf("  %s [%s]: %s%s\n", item.ID, item.Type, item.Oneliner, originLabel(item))
+		}
+		fmt.Println()
+	}
+
 	// Session State
-	if ctx.State != nil && (ctx.State.Goal != "" || ctx.State.Progress != "" || len(ctx.State.Next) > 0) {
+	if ctx.State != nil && (ctx.State.Goal != "" || ctx.State.Progress != "" || len(ctx.State.Next) > 0 || len(ctx.State.OpenQuestions) > 0 || len(ctx.State.WorkingFiles) > 0) {
 		fmt.Println("SESSION STATE")
 		fmt.Println(strings.Repeat("─", 50))
 
@@ -92,18 +146,36 @@ func printContext(ctx *store.ContextResult, updated bool) {
 		if ctx.State.Blocker != "" {
 			fmt.Printf("  Blocker: %s\n", ctx.State.Blocker)
 		}
-		if len(ctx.State.Next) > 0 {
+		if pending := pendingTasks(ctx.State.Next); len(pending) > 0 {
 			fmt.Println("  Next:")
-			for _, n := range ctx.State.Next {
-				fmt.Printf("    - %s\n", n)
+			for _, task := range pending {
+				fmt.Printf("    %s %s: %s\n", taskStatusMarker(task.Status), task.ID, task.Text)
 			}
 		}
+		if open := openQuestions(ctx.State.OpenQuestions); len(open) > 0 {
+			fmt.Println("  Open questions:")
+			for _, question := range open {
+				fmt.Printf("    ? %s: %s\n", question.ID, question.Text)
+			}
+		}
+		if len(ctx.State.WorkingFiles) > 0 {
+			fmt.Printf("  Working files: %s\n", strings.Join(ctx.State.WorkingFiles, ", "))
+		}
 		if ctx.State.LastUpdated != "" {
 			fmt.Printf("  (updated: %s)\n", ctx.State.LastUpdated)
 		}
 		fmt.Println()
 	}
 
+	if ctx.Changes != nil {
+		printChanges(ctx.Changes)
+	}
+
+	if ctx.Budget != nil {
+		printBudget(ctx.Budget)
+		return
+	}
+
 	// Critical lessons
 	if len(ctx.Critical) > 0 {
 		fmt.Printf("CRITICAL/HIGH LESSONS (%d)\n", len(ctx.Critical))
@@ -113,7 +185,7 @@ func printContext(ctx *store.ContextResult, updated bool) {
 			if item.Severity != "" {
 				sev = fmt.Sprintf("[%s] ", item.Severity)
 			}
-			fmt.Printf("  %s: %s%s\n", item.ID, sev, truncateOneliner(item.Oneliner, 40))
+			fmt.Printf("  %s: %s%s%s\n", item.ID, sev, truncateOneliner(item.Oneliner, 40), originLabel(item))
 		}
 		fmt.Println()
 	}
@@ -123,7 +195,7 @@ func printContext(ctx *store.ContextResult, updated bool) {
 		fmt.Printf("TAG ITEMS (%d)\n", len(ctx.Topic))
 		fmt.Println(strings.Repeat("─", 50))
 		for _, item := range ctx.Topic {
-			fmt.Printf("  %s [%s]: %s\n", item.ID, item.Type, truncateOneliner(item.Oneliner, 35))
+			fmt.Printf("  %s [%s]: %s%s\n", item.ID, item.Type, truncateOneliner(item.Oneliner, 35), originLabel(item))
 		}
 		fmt.Println()
 	}
@@ -133,13 +205,13 @@ func printContext(ctx *store.ContextResult, updated bool) {
 		fmt.Printf("RECENT ITEMS (%d)\n", len(ctx.Recent))
 		fmt.Println(strings.Repeat("─", 50))
 		for _, item := range ctx.Recent {
-			fmt.Printf("  %s [%s]: %s\n", item.ID, item.Type, truncateOneliner(item.Oneliner, 35))
+			fmt.Printf("  %s [%s]: %s%s\n", item.ID, item.Type, truncateOneliner(item.Oneliner, 35), originLabel(item))
 		}
 		fmt.Println()
 	}
 
 	// Summary
-	total := len(ctx.Critical) + len(ctx.Recent)
+	total := len(ctx.Pinned) + len(ctx.Critical) + len(ctx.Recent)
 	if len(ctx.Topic) > 0 {
 		total += len(ctx.Topic)
 	}
@@ -147,6 +219,91 @@ func printContext(ctx *store.ContextResult, updated bool) {
 	fmt.Println("Use 'dory show <id>' for full content, 'dory expand <id>' for related items")
 }
 
+func printChanges(changes *store.ChangesResult) {
+	fmt.Printf("FOR CHANGES (%d items, %d paths)\n", len(changes.Items), len(changes.Paths))
+	fmt.Println(strings.Repeat("─", 50))
+	if len(changes.Paths) == 0 {
+		fmt.Println("  No working files or git changes found")
+	}
+	for _, item := range changes.Items {
+		sev := ""
+		if item.Severity != "" {
+			sev = fmt.Sprintf("[%s] ", item.Severity)
+		}
+		fmt.Printf("  %s [%s]: %s%s%s\n", item.ID, item.Type, sev, truncateOneliner(item.Oneliner, 40), originLabel(item.ListItem))
+		fmt.Printf("      (%s)\n", strings.Join(item.Reasons, ", "))
+	}
+	fmt.Println()
+}
+
+func printBudget(budget *store.BudgetResult) {
+	if len(budget.Pinned) > 0 {
+		fmt.Printf("PINNED (%d)\n", len(budget.Pinned))
+		fmt.Println(strings.Repeat("─", 50))
+		printBudgetedItems(budget.Pinned)
+		fmt.Println()
+	}
+
+	fmt.Printf("RANKED ITEMS (%d)\n", len(budget.Included))
+	fmt.Println(strings.Repeat("─", 50))
+	printBudgetedItems(budget.Included)
+	fmt.Println()
+
+	if len(budget.Omitted) > 0 {
+		fmt.Printf("LEFT OUT (%d)\n", len(budget.Omitted))
+		fmt.Println(strings.Repeat("─", 50))
+		for _, item := range budget.Omitted {
+			fmt.Printf("  %s [%s]: %s\n", item.ID, item.Type, truncateOneliner(item.Oneliner, 35))
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("Budget: ~%d of %d tokens used\n", budget.Used, budget.Tokens)
+	fmt.Println("Use 'dory show <id>' for full content, 'dory expand <id>' for related items")
+}
+
+func printBudgetedItems(items []store.BudgetedItem) {
+	for _, item := range items {
+		sev := ""
+		if item.Severity != "" {
+			sev = fmt.Sprintf("[%s] ", item.Severity)
+		}
+		fmt.Printf("  %s [%s]: %s%s%s\n", item.ID, item.Type, sev, item.Oneliner, originLabel(item.ListItem))
+		if item.Body != "" {
+			for _, line := range strings.Split(item.Body, "\n") {
+				fmt.Printf("      %s\n", line)
+			}
+		}
+	}
+}
+
+func pendingTasks(tasks []store.Task) []store.Task {
+	pending := make([]store.Task, 0, len(tasks))
+	for _, task := range tasks {
+		if task.Status != "done" {
+			pending = append(pending, task)
+		}
+	}
+	return pending
+}
+
+func openQuestions(questions []store.Question) []store.Question {
+	open := make([]store.Question, 0, len(questions))
+	for _, question := range questions {
+		if question.Status != "answered" {
+			open = append(open, question)
+		}
+	}
+	return open
+}
+
+func originLabel(item store.ListItem) string {
+	if item.Origin == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (%s)", item.Origin)
+}
+
 func truncateOneliner(s string, max int) string {
 	if len(s) > max {
 		return s[:max-3] + "..."
@@ -159,14 +316,20 @@ func init() {
 	contextCmd.Flags().StringP("tag", "T", "", "Include all items for this tag")
 	contextCmd.Flags().Int("recent", 7, "Include items from last N days")
 	contextCmd.Flags().Bool("full", false, "Include all items")
+	contextCmd.Flags().String("filter", "", "Only include items matching a filter expression (see dory query)")
+	contextCmd.Flags().Int("budget", 0, "Rank items and include only what fits this many tokens")
+	contextCmd.Flags().Bool("bodies", false, "With --budget, include item bodies while they fit")
+	contextCmd.Flags().Bool("for-changes", false, "Include items relevant to working files and git changes")
+	contextCmd.Flags().String("base", "", "With --for-changes, also include files changed since this git ref")
+	contextCmd.RegisterFlagCompletionFunc("tag", completeTags)
 
 	// Write mode flags (state)
 	contextCmd.Flags().StringP("goal", "g", "", "Set current goal")
 	contextCmd.Flags().StringP("progress", "p", "", "Set current progress")
 	contextCmd.Flags().StringP("blocker", "b", "", "Set current blocker")
-	contextCmd.Flags().StringSliceP("next", "n", nil, "Set next steps (repeatable)")
+	contextCmd.Flags().StringSliceP("next", "n", nil, "Replace next steps with new todo tasks (repeatable; see 'dory task')")
 	contextCmd.Flags().StringSlice("working-file", nil, "Set working files (repeatable)")
-	contextCmd.Flags().StringSliceP("question", "q", nil, "Set open questions (repeatable)")
+	contextCmd.Flags().StringSliceP("question", "q", nil, "Replace open questions (repeatable; see 'dory question')")
 
 	RootCmd.AddCommand(contextCmd)
 }
@@ -27,6 +27,9 @@ Examples:
   dory create "Title" --tag api --body "# Details..."
   cat notes.md | dory create "Title" --tag api --body -
 
+A warning names any existing item that looks like a duplicate (see
+dory similar); --no-duplicates refuses to create the item instead.
+
 Kinds:
   lesson      Something learned (default) - supports --severity
   decision    Architectural/technical choice
@@ -62,6 +65,7 @@ Kinds:
 
 		s := store.New(doryRoot)
 		defer s.Close()
+		applyRefPolicy(cmd, s)
 
 		var oneliner, body string
 
@@ -88,6 +92,8 @@ Kinds:
 			oneliner, body = parseEditorContent(content)
 		}
 
+		duplicates := checkDuplicates(cmd, s, oneliner, body)
+
 		runPluginHooks(plugin.HookBeforeCreate, map[string]interface{}{
 			"type":     kind,
 			"oneliner": oneliner,
@@ -128,6 +134,9 @@ Kinds:
 		if kind == "lesson" {
 			result["severity"] = string(severity)
 		}
+		if len(duplicates) > 0 {
+			result["similar_to"] = duplicateIDs(duplicates)
+		}
 
 		OutputResult(cmd, result, func() {
 			fmt.Printf("Created %s\n", id)
@@ -196,10 +205,14 @@ func parseEditorContent(content string) (oneliner, body string) {
 }
 
 func init() {
+	addDuplicateFlags(createCmd)
 	createCmd.Flags().StringP("kind", "k", "lesson", "Kind: lesson, decision, convention")
 	createCmd.Flags().StringP("tag", "T", "", "Tag/category (required)")
 	createCmd.Flags().StringP("severity", "S", "normal", "Severity: critical, high, normal, low (lessons only)")
 	createCmd.Flags().StringP("body", "b", "", "Full markdown body (use - for stdin)")
 	createCmd.Flags().StringSliceP("refs", "R", []string{}, "References (comma-separated, e.g., L-abc123,D-def456)")
+	addRefPolicyFlag(createCmd)
+	createCmd.RegisterFlagCompletionFunc("tag", completeTags)
+	createCmd.RegisterFlagCompletionFunc("refs", completeRefs)
 	RootCmd.AddCommand(createCmd)
 }
@@ -0,0 +1,232 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sibellavia/dory/internal/doryfile"
+	"github.com/sibellavia/dory/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <from> [to]",
+	Short: "Show knowledge added, removed, and changed between two points",
+	Long: `Compare the store at two points and list items added, removed, and
+modified (with field and body changes), plus session state changes.
+
+Each side can be:
+  now                 the current store (the default for <to>)
+  a number            the current store after that event seq (0 is empty)
+  a date or time      .dory as last committed to git before then
+                      (2026-01-31 or 2026-01-31T12:00:00Z)
+  a directory         another store (.dory or a directory containing one)
+  a git revision      .dory as committed in that revision
+
+Examples:
+  dory diff main                 # What this branch added or changed
+  dory diff HEAD~3 HEAD
+  dory diff 40 now               # Changes since event 40
+  dory diff 2026-01-01
+  dory diff ../other-repo/.dory now`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		RequireStore()
+
+		to := "now"
+		if len(args) == 2 {
+			to = args[1]
+		}
+		fromSnap, err := loadDiffSide(args[0])
+		CheckError(err)
+		toSnap, err := loadDiffSide(to)
+		CheckError(err)
+
+		result := store.Diff(fromSnap, toSnap)
+		OutputResult(cmd, result, func() {
+			printDiff(result)
+		})
+	},
+}
+
+// loadDiffSide resolves one side of a diff to a snapshot of the store.
+func loadDiffSide(spec string) (*store.Snapshot, error) {
+	snap, err := resolveDiffSide(spec)
+	if err != nil {
+		return nil, err
+	}
+	snap.Label = spec
+	return snap, nil
+}
+
+func resolveDiffSide(spec string) (*store.Snapshot, error) {
+	if spec == "now" {
+		return store.LoadSnapshot(doryRoot, 0)
+	}
+	if isDigits(spec) {
+		seq, err := strconv.ParseUint(spec, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid seq %q", spec)
+		}
+		if seq == 0 {
+			return store.EmptySnapshot(), nil
+		}
+		return store.LoadSnapshot(doryRoot, seq)
+	}
+	if at, ok := parseDiffTime(spec); ok {
+		return gitSnapshotBefore(at)
+	}
+	if dir, ok := storeDir(spec); ok {
+		return store.LoadSnapshot(dir, 0)
+	}
+	if _, err := runGit("rev-parse", "--verify", "--quiet", spec+"^{commit}"); err != nil {
+		return nil, fmt.Errorf("unknown diff side %q (expected now, a seq, a date, a store path, or a git revision)", spec)
+	}
+	return gitSnapshot(spec)
+}
+
+func isDigits(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789") == ""
+}
+
+func parseDiffTime(spec string) (time.Time, bool) {
+	if t, err := time.Parse(time.RFC3339, spec); err == nil {
+		return t, true
+	}
+	if t, err := time.ParseInLocation("2006-01-02", spec, time.Local); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+// storeDir returns the store directory for a path naming a store or a
+// directory that contains one.
+func storeDir(p string) (string, bool) {
+	for _, dir := range []string{p, filepath.Join(p, store.DoryDir)} {
+		if info, err := os.Stat(filepath.Join(dir, doryfile.KnowledgeFile)); err == nil && !info.IsDir() {
+			return dir, true
+		}
+	}
+	return "", false
+}
+
+// gitSnapshotBefore reads the store as last committed before at. A store
+// with no commit before then is empty.
+func gitSnapshotBefore(at time.Time) (*store.Snapshot, error) {
+	rel, err := gitStorePath()
+	if err != nil {
+		return nil, err
+	}
+	out, err := runGit("rev-list", "-1", "--before="+at.Format(time.RFC3339), "HEAD", "--", ":(top)"+rel)
+	if err != nil {
+		return nil, err
+	}
+	rev := strings.TrimSpace(out)
+	if rev == "" {
+		return store.EmptySnapshot(), nil
+	}
+	return gitSnapshot(rev)
+}
+
+// gitSnapshot reads the store as committed in rev. A revision that predates
+// the store is empty.
+func gitSnapshot(rev string) (*store.Snapshot, error) {
+	rel, err := gitStorePath()
+	if err != nil {
+		return nil, err
+	}
+	object := rev + ":" + path.Join(rel, doryfile.KnowledgeFile)
+	if _, err := runGit("cat-file", "-e", object); err != nil {
+		return store.EmptySnapshot(), nil
+	}
+	content, err := runGit("show", object)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "dory-diff-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	if err := os.WriteFile(filepath.Join(dir, doryfile.KnowledgeFile), []byte(content), 0644); err != nil {
+		return nil, err
+	}
+	snap, err := store.LoadSnapshot(dir, 0)
+	if err != nil {
+		return nil, fmt.Errorf("read %s at %s: %w", doryfile.KnowledgeFile, rev, err)
+	}
+	return snap, nil
+}
+
+// gitStorePath returns the store directory relative to the git work tree root.
+func gitStorePath() (string, error) {
+	top, err := runGit("rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", fmt.Errorf("git revisions and dates need a git work tree: %w", err)
+	}
+	root, err := filepath.Abs(doryRoot)
+	if err != nil {
+		return "", err
+	}
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+	rel, err := filepath.Rel(strings.TrimSpace(top), root)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return "", fmt.Errorf("store %s is outside the git work tree", doryRoot)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+func printDiff(result *store.DiffResult) {
+	if result.Empty() {
+		fmt.Printf("No knowledge changes between %s and %s\n", result.From, result.To)
+		return
+	}
+	fmt.Printf("Knowledge diff %s..%s\n", result.From, result.To)
+
+	printDiffItems := func(title, mark string, items []store.ListItem) {
+		if len(items) == 0 {
+			return
+		}
+		fmt.Printf("\n%s (%d):\n", title, len(items))
+		for _, item := range items {
+			fmt.Printf("  %s %s  [%s] %s\n", mark, item.ID, item.Type, truncateOneliner(item.Oneliner, 60))
+		}
+	}
+	printDiffItems("ADDED", "+", result.Added)
+	printDiffItems("REMOVED", "-", result.Removed)
+
+	if len(result.Modified) > 0 {
+		fmt.Printf("\nMODIFIED (%d):\n", len(result.Modified))
+		for _, item := range result.Modified {
+			fmt.Printf("  ~ %s  [%s] %s\n", item.ID, item.Type, truncateOneliner(item.Oneliner, 60))
+			for _, change := range item.Fields {
+				fmt.Printf("      %s: %q -> %q\n", change.Field, change.From, change.To)
+			}
+			if len(item.Body) > 0 {
+				fmt.Println("      body:")
+				for _, line := range item.Body {
+					fmt.Printf("        %s\n", line)
+				}
+			}
+		}
+	}
+
+	if len(result.State) > 0 {
+		fmt.Println("\nSTATE:")
+		for _, change := range result.State {
+			fmt.Printf("  %s: %q -> %q\n", change.Field, change.From, change.To)
+		}
+	}
+}
+
+func init() {
+	RootCmd.AddCommand(diffCmd)
+}
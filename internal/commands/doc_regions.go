@@ -0,0 +1,287 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/sibellavia/dory/internal/config"
+	"github.com/sibellavia/dory/internal/query"
+	"github.com/sibellavia/dory/internal/store"
+)
+
+// Managed regions are blocks of a markdown file that dory owns:
+//
+//	<!-- dory:begin tag=auth -->
+//	...generated...
+//	<!-- dory:end -->
+//
+// The begin marker says what goes inside, and `dory sync-docs` regenerates
+// the block in place. Text outside the markers is never touched.
+
+var (
+	docBeginMarker = regexp.MustCompile(`<!--\s*dory:begin\b(.*?)-->`)
+	docEndMarker   = regexp.MustCompile(`<!--\s*dory:end\s*-->`)
+	docMarkerAttr  = regexp.MustCompile(`([A-Za-z_]+)(?:=("[^"]*"|'[^']*'|\S+))?`)
+)
+
+// docSpec selects what a region holds: the dory instructions, a named spec
+// from the project config, specific items, or items by tag, type, and filter.
+type docSpec struct {
+	Instructions bool
+	Region       string
+	Tag          string
+	Type         string
+	Filter       string
+	IDs          []string
+}
+
+func parseDocSpec(attrs string) (docSpec, error) {
+	var spec docSpec
+	for _, m := range docMarkerAttr.FindAllStringSubmatch(attrs, -1) {
+		key, value := m[1], strings.Trim(m[2], `"'`)
+		switch key {
+		case "instructions":
+			spec.Instructions = true
+		case "region":
+			spec.Region = value
+		case "tag":
+			spec.Tag = value
+		case "type":
+			spec.Type = value
+		case "filter":
+			spec.Filter = value
+		case "ids":
+			spec.IDs = strings.Split(value, ",")
+		default:
+			return spec, fmt.Errorf("unknown dory:begin attribute %q", key)
+		}
+	}
+	return spec, nil
+}
+
+// marker formats the begin marker for spec.
+func (spec docSpec) marker() string {
+	var attrs []string
+	if spec.Instructions {
+		attrs = append(attrs, "instructions")
+	}
+	add := func(key, value string) {
+		if value == "" {
+			return
+		}
+		if strings.Contains(value, `"`) {
+			value = "'" + value + "'"
+		} else if strings.ContainsAny(value, " \t'") {
+			value = `"` + value + `"`
+		}
+		attrs = append(attrs, key+"="+value)
+	}
+	add("region", spec.Region)
+	add("tag", spec.Tag)
+	add("type", spec.Type)
+	add("filter", spec.Filter)
+	add("ids", strings.Join(spec.IDs, ","))
+	return "<!-- dory:begin " + strings.Join(attrs, " ") + " -->"
+}
+
+// renderDocSpec generates the markdown for a region, using the same
+// output as `dory export`.
+func renderDocSpec(s *store.Store, cfg *config.ProjectConfig, spec docSpec) (string, error) {
+	if spec.Instructions {
+		return strings.TrimSpace(doryInstructions), nil
+	}
+	if spec.Region != "" {
+		var named config.DocsRegion
+		var ok bool
+		if cfg.Docs != nil {
+			named, ok = cfg.Docs.Regions[spec.Region]
+		}
+		if !ok {
+			return "", fmt.Errorf("region %q is not defined under docs.regions in %s", spec.Region, config.Path(doryRoot))
+		}
+		spec = docSpec{Tag: named.Tag, Type: named.Type, Filter: named.Filter}
+	}
+	if len(spec.IDs) > 0 {
+		out, err := exportItems(s, spec.IDs)
+		return strings.TrimSpace(out), err
+	}
+
+	raw := spec.Filter
+	if spec.Type != "" {
+		typeFilter := "type:" + spec.Type
+		if raw != "" {
+			raw = "(" + raw + ") AND " + typeFilter
+		} else {
+			raw = typeFilter
+		}
+	}
+	var filter query.Expr
+	if raw != "" {
+		expr, err := query.Parse(raw)
+		if err != nil {
+			return "", err
+		}
+		filter = expr
+	}
+	var out string
+	var err error
+	if spec.Tag != "" {
+		out, err = exportByTopic(s, spec.Tag, filter)
+	} else {
+		out, err = exportAll(s, filter)
+	}
+	return strings.TrimSpace(out), err
+}
+
+// docRegion is a managed region found in a file. Start and End bound the
+// generated text between the markers.
+type docRegion struct {
+	Marker string
+	Spec   docSpec
+	Start  int
+	End    int
+}
+
+func findDocRegions(content string) ([]docRegion, error) {
+	var regions []docRegion
+	pos := 0
+	for {
+		begin := docBeginMarker.FindStringSubmatchIndex(content[pos:])
+		if begin == nil {
+			break
+		}
+		start := pos + begin[1]
+		marker := content[pos+begin[0] : start]
+		line := strings.Count(content[:pos+begin[0]], "\n") + 1
+		end := docEndMarker.FindStringIndex(content[start:])
+		if end == nil {
+			return nil, fmt.Errorf("line %d: %s has no <!-- dory:end -->", line, marker)
+		}
+		if next := docBeginMarker.FindStringIndex(content[start:]); next != nil && next[0] < end[0] {
+			return nil, fmt.Errorf("line %d: %s is not closed before the next dory:begin", line, marker)
+		}
+		spec, err := parseDocSpec(content[pos+begin[2] : pos+begin[3]])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		regions = append(regions, docRegion{Marker: marker, Spec: spec, Start: start, End: start + end[0]})
+		pos = start + end[1]
+	}
+	return regions, nil
+}
+
+// DocRegionResult is the state of one managed region after a sync.
+type DocRegionResult struct {
+	File   string `json:"file" yaml:"file"`
+	Region string `json:"region" yaml:"region"`
+	Status string `json:"status" yaml:"status"`
+}
+
+// syncDocFile regenerates every region of path. With check it only reports
+// regions that are out of date ("stale") and writes nothing.
+func syncDocFile(s *store.Store, cfg *config.ProjectConfig, path string, check bool) ([]DocRegionResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	content := string(data)
+	regions, err := findDocRegions(content)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	var results []DocRegionResult
+	var out strings.Builder
+	last := 0
+	for _, region := range regions {
+		generated, err := renderDocSpec(s, cfg, region.Spec)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", path, region.Marker, err)
+		}
+		body := "\n" + generated + "\n"
+		status := "unchanged"
+		if content[region.Start:region.End] != body {
+			status = "updated"
+			if check {
+				status = "stale"
+			}
+		}
+		results = append(results, DocRegionResult{File: path, Region: region.Marker, Status: status})
+		out.WriteString(content[last:region.Start])
+		out.WriteString(body)
+		last = region.End
+	}
+	out.WriteString(content[last:])
+
+	if !check && out.String() != content {
+		if err := os.WriteFile(path, []byte(out.String()), 0644); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// upsertDocRegion writes generated into the region of path with the same
+// spec, or appends a new region (creating the file) when there is none.
+// It reports whether the region was new.
+func upsertDocRegion(path string, spec docSpec, generated string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	content := string(data)
+	regions, err := findDocRegions(content)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", path, err)
+	}
+	body := "\n" + strings.TrimSpace(generated) + "\n"
+	want := spec.marker()
+	for _, region := range regions {
+		if region.Spec.marker() == want {
+			content = content[:region.Start] + body + content[region.End:]
+			return false, os.WriteFile(path, []byte(content), 0644)
+		}
+	}
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	if content != "" {
+		content += "\n"
+	}
+	content += want + body + "<!-- dory:end -->\n"
+	return true, os.WriteFile(path, []byte(content), 0644)
+}
+
+// projectRoot is the directory holding the store, which docs paths in the
+// project config are relative to.
+func projectRoot() string {
+	abs, err := filepath.Abs(doryRoot)
+	if err != nil {
+		return "."
+	}
+	return filepath.Dir(abs)
+}
+
+// registerDocsFile records path in the project config so sync-docs keeps
+// its regions current.
+func registerDocsFile(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	rel, err := filepath.Rel(projectRoot(), abs)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		rel = abs
+	}
+	cfg, err := config.Load(doryRoot)
+	if err != nil {
+		return err
+	}
+	if !cfg.AddDocsFile(filepath.ToSlash(rel)) {
+		return nil
+	}
+	return config.Save(doryRoot, cfg)
+}
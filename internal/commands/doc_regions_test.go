@@ -0,0 +1,97 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sibellavia/dory/internal/config"
+	"github.com/sibellavia/dory/internal/models"
+	"github.com/sibellavia/dory/internal/store"
+)
+
+func TestParseDocSpec(t *testing.T) {
+	spec, err := parseDocSpec(` tag=auth type=lesson filter="severity>=high AND tag:auth" ids=L-1,D-2 `)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spec.Tag != "auth" || spec.Type != "lesson" || spec.Filter != "severity>=high AND tag:auth" || len(spec.IDs) != 2 {
+		t.Fatalf("unexpected spec %+v", spec)
+	}
+	if got := spec.marker(); got != `<!-- dory:begin tag=auth type=lesson filter="severity>=high AND tag:auth" ids=L-1,D-2 -->` {
+		t.Fatalf("unexpected marker %s", got)
+	}
+	if _, err := parseDocSpec("topic=auth"); err == nil {
+		t.Fatal("expected an unknown attribute to fail")
+	}
+	if _, err := findDocRegions("intro\n<!-- dory:begin tag=auth -->\nbody\n"); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Fatalf("expected a missing end marker to fail on line 2, got %v", err)
+	}
+}
+
+func TestSyncDocFile(t *testing.T) {
+	dir := t.TempDir()
+	s := store.New(filepath.Join(dir, ".dory"))
+	defer s.Close()
+	if err := s.Init("project", ""); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if _, err := s.Learn("Retry on 429", "api", models.SeverityHigh, "", nil); err != nil {
+		t.Fatalf("learn: %v", err)
+	}
+	cfg := &config.ProjectConfig{Docs: &config.DocsConfig{
+		Regions: map[string]config.DocsRegion{"hot": {Filter: "severity>=high"}},
+	}}
+
+	path := filepath.Join(dir, "AGENTS.md")
+	original := "# Agents\n\n<!-- dory:begin tag=api -->\nold\n<!-- dory:end -->\n\nHand-written.\n\n<!-- dory:begin region=hot -->\n<!-- dory:end -->\n"
+	if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sync := func(check bool) []DocRegionResult {
+		t.Helper()
+		results, err := syncDocFile(s, cfg, path, check)
+		if err != nil {
+			t.Fatalf("sync: %v", err)
+		}
+		return results
+	}
+
+	if results := sync(true); len(results) != 2 || results[0].Status != "stale" || results[1].Status != "stale" {
+		t.Fatalf("expected two stale regions, got %+v", results)
+	}
+	if data, _ := os.ReadFile(path); string(data) != original {
+		t.Fatal("expected --check to leave the file alone")
+	}
+	if results := sync(false); results[0].Status != "updated" {
+		t.Fatalf("expected the region to update, got %+v", results)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+	if strings.Contains(content, "old") || strings.Count(content, "Retry on 429") != 2 || !strings.Contains(content, "Hand-written.") {
+		t.Fatalf("unexpected synced file:\n%s", content)
+	}
+	if results := sync(true); results[0].Status != "unchanged" || results[1].Status != "unchanged" {
+		t.Fatalf("expected a synced file to be current, got %+v", results)
+	}
+
+	// Upserting the same spec twice replaces the region instead of duplicating it.
+	target := filepath.Join(dir, "CLAUDE.md")
+	spec := docSpec{Tag: "api"}
+	for i, want := range []bool{true, false} {
+		created, err := upsertDocRegion(target, spec, "generated "+string(rune('a'+i)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if created != want {
+			t.Fatalf("upsert %d: expected created=%v", i, want)
+		}
+	}
+	if data, _ := os.ReadFile(target); string(data) != "<!-- dory:begin tag=api -->\ngenerated b\n<!-- dory:end -->\n" {
+		t.Fatalf("unexpected upserted file:\n%s", data)
+	}
+}
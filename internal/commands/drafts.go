@@ -0,0 +1,24 @@
+package commands
+
+import "github.com/spf13/cobra"
+
+var draftsCmd = &cobra.Command{
+	Use:   "drafts",
+	Short: "Review draft lessons proposed by dory harvest",
+	Long: `Review the draft lessons 'dory harvest' found in agent transcripts.
+
+Drafts are kept in .dory/drafts.yaml, outside the log, until accepted:
+accepting a draft creates a lesson, rejecting it keeps it out of later
+harvests of the same transcript.
+
+Examples:
+  dory drafts list
+  dory drafts list DR-3                 # Show the full draft
+  dory drafts accept DR-3 --tag build
+  dory drafts accept DR-4 --edit        # Revise in $EDITOR first
+  dory drafts reject DR-5 DR-6`,
+}
+
+func init() {
+	RootCmd.AddCommand(draftsCmd)
+}
@@ -0,0 +1,115 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sibellavia/dory/internal/models"
+	"github.com/sibellavia/dory/internal/plugin"
+	"github.com/sibellavia/dory/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var draftsAcceptCmd = &cobra.Command{
+	Use:   "accept <id>...",
+	Short: "Create lessons from drafts",
+	Long: `Create a lesson from each draft and mark the draft accepted.
+
+The lesson takes the draft's oneliner, body, tag, and severity; --tag and
+--severity override them, and --edit opens each draft in $EDITOR first (the
+"# " heading becomes the oneliner).`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		RequireStore()
+
+		tagFlag, _ := cmd.Flags().GetString("tag")
+		severityFlag, _ := cmd.Flags().GetString("severity")
+		edit, _ := cmd.Flags().GetBool("edit")
+		CheckError(validateSeverityFlag(models.Severity(severityFlag)))
+
+		s := store.New(doryRoot)
+		defer s.Close()
+
+		accepted := make([]map[string]interface{}, 0, len(args))
+		for _, ref := range args {
+			draft, err := s.Draft(ref)
+			CheckError(err)
+			if draft.Status != store.DraftPending {
+				CheckError(fmt.Errorf("draft %s is already %s", draft.ID, draft.Status))
+			}
+
+			tag := draft.Topic
+			if tagFlag != "" {
+				tag = tagFlag
+			}
+			if tag == "" {
+				CheckError(fmt.Errorf("draft %s has no tag; pass --tag", draft.ID))
+			}
+			severity := models.Severity(draft.Severity)
+			if severityFlag != "" {
+				severity = models.Severity(severityFlag)
+			}
+			if severity == "" {
+				severity = models.SeverityNormal
+			}
+
+			oneliner, body := draft.Oneliner, draft.Body
+			if edit {
+				content, err := openEditor(body)
+				CheckError(err)
+				if strings.TrimSpace(content) == "" {
+					CheckError(fmt.Errorf("aborted: draft %s is empty", draft.ID))
+				}
+				oneliner, body = parseEditorContent(content)
+				if oneliner == "" {
+					CheckError(fmt.Errorf("aborted: draft %s has no '# ' heading", draft.ID))
+				}
+			}
+
+			duplicates := checkDuplicates(cmd, s, oneliner, body)
+
+			runPluginHooks(plugin.HookBeforeCreate, map[string]interface{}{
+				"type":     "lesson",
+				"oneliner": oneliner,
+				"topic":    tag,
+				"severity": string(severity),
+			})
+			id, err := s.AcceptDraft(draft.ID, oneliner, tag, severity, body)
+			CheckError(err)
+			runPluginHooks(plugin.HookAfterCreate, map[string]interface{}{
+				"id":       id,
+				"type":     "lesson",
+				"oneliner": oneliner,
+				"topic":    tag,
+				"severity": string(severity),
+			})
+
+			result := map[string]interface{}{
+				"draft":    draft.ID,
+				"id":       id,
+				"oneliner": oneliner,
+				"tag":      tag,
+				"severity": string(severity),
+			}
+			if len(duplicates) > 0 {
+				result["similar_to"] = duplicateIDs(duplicates)
+			}
+			accepted = append(accepted, result)
+		}
+
+		OutputResult(cmd, accepted, func() {
+			for _, result := range accepted {
+				fmt.Printf("Accepted %s as %s\n", result["draft"], result["id"])
+			}
+		})
+	},
+}
+
+func init() {
+	addDuplicateFlags(draftsAcceptCmd)
+	draftsAcceptCmd.Flags().StringP("tag", "T", "", "Tag for the lessons (default: the draft's tag)")
+	draftsAcceptCmd.Flags().StringP("severity", "S", "", "Severity: critical, high, normal, low (default: the draft's)")
+	draftsAcceptCmd.Flags().Bool("edit", false, "Revise each draft in $EDITOR before accepting it")
+	draftsAcceptCmd.RegisterFlagCompletionFunc("tag", completeTags)
+	draftsCmd.AddCommand(draftsAcceptCmd)
+}
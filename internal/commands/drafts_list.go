@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sibellavia/dory/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var draftsListCmd = &cobra.Command{
+	Use:   "list [ids...]",
+	Short: "List pending drafts, or show the given drafts in full",
+	Run: func(cmd *cobra.Command, args []string) {
+		RequireStore()
+
+		all, _ := cmd.Flags().GetBool("all")
+		showBody, _ := cmd.Flags().GetBool("body")
+
+		s := store.New(doryRoot)
+		defer s.Close()
+
+		filtered := make([]store.Draft, 0)
+		if len(args) > 0 {
+			for _, id := range args {
+				draft, err := s.Draft(id)
+				CheckError(err)
+				filtered = append(filtered, *draft)
+			}
+			showBody = true
+		} else {
+			drafts, err := s.Drafts()
+			CheckError(err)
+			for _, draft := range drafts {
+				if all || draft.Status == store.DraftPending {
+					filtered = append(filtered, draft)
+				}
+			}
+		}
+
+		OutputResult(cmd, filtered, func() {
+			if len(filtered) == 0 {
+				fmt.Println("No drafts found")
+				return
+			}
+			for i, draft := range filtered {
+				if showBody && i > 0 {
+					fmt.Println()
+				}
+				fmt.Printf("%-6s %-7s %s%s\n", draft.ID, draft.Signal, truncateOneliner(draft.Oneliner, 80), draftStatusNote(draft))
+				if showBody {
+					tag := draft.Topic
+					if tag == "" {
+						tag = "(none)"
+					}
+					fmt.Printf("       tag: %s  severity: %s  from: %s:%d\n\n", tag, draft.Severity, draft.Source, draft.Line)
+					fmt.Println(strings.TrimRight(draft.Body, "\n"))
+				}
+			}
+		})
+	},
+}
+
+func draftStatusNote(draft store.Draft) string {
+	switch draft.Status {
+	case store.DraftAccepted:
+		return " (accepted as " + draft.ItemID + ")"
+	case store.DraftRejected:
+		return " (rejected)"
+	default:
+		return ""
+	}
+}
+
+func init() {
+	draftsListCmd.Flags().Bool("all", false, "Include accepted and rejected drafts")
+	draftsListCmd.Flags().Bool("body", false, "Show each draft's body")
+	draftsCmd.AddCommand(draftsListCmd)
+}
@@ -0,0 +1,37 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/sibellavia/dory/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var draftsRejectCmd = &cobra.Command{
+	Use:   "reject <id>...",
+	Short: "Discard drafts",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		RequireStore()
+
+		s := store.New(doryRoot)
+		defer s.Close()
+
+		rejected := make([]store.Draft, 0, len(args))
+		for _, id := range args {
+			draft, err := s.ResolveDraft(id, store.DraftRejected, "")
+			CheckError(err)
+			rejected = append(rejected, *draft)
+		}
+
+		OutputResult(cmd, rejected, func() {
+			for _, draft := range rejected {
+				fmt.Printf("Rejected %s\n", draft.ID)
+			}
+		})
+	},
+}
+
+func init() {
+	draftsCmd.AddCommand(draftsRejectCmd)
+}
@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/sibellavia/dory/internal/store"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var dumpCmd = &cobra.Command{
+	Use:   "dump",
+	Short: "Write the whole store as a lossless JSON Lines or YAML stream",
+	Long: `Write the store as a self-describing stream that 'dory load' turns back
+into the same store: a header record (format, project, description, alias
+counters, config.yaml), then every live item with its full body, refs,
+alias, and pin, then the session state.
+
+With --history the header is followed by every event of the log instead, so
+deleted items, past versions, and event seqs survive the round trip.
+
+Records are JSON Lines by default, or YAML documents with --yaml.
+
+Examples:
+  dory dump -o backup.jsonl
+  dory dump --history --yaml > knowledge.yaml
+  dory dump | ssh host 'cd repo && dory load -'`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		RequireStore()
+		history, _ := cmd.Flags().GetBool("history")
+		out, _ := cmd.Flags().GetString("output")
+
+		s := store.New(doryRoot)
+		defer s.Close()
+
+		var buf bytes.Buffer
+		var records int
+		var emit func(*store.DumpRecord) error
+		finish := func() error { return nil }
+		if GetOutputFormat(cmd) == "yaml" {
+			enc := yaml.NewEncoder(&buf)
+			enc.SetIndent(2)
+			emit = func(record *store.DumpRecord) error { return enc.Encode(record) }
+			finish = enc.Close
+		} else {
+			enc := json.NewEncoder(&buf)
+			enc.SetEscapeHTML(false)
+			emit = func(record *store.DumpRecord) error { return enc.Encode(record) }
+		}
+		CheckError(s.Dump(history, func(record *store.DumpRecord) error {
+			records++
+			return emit(record)
+		}))
+		CheckError(finish())
+
+		if out == "" {
+			_, err := os.Stdout.Write(buf.Bytes())
+			CheckError(err)
+			return
+		}
+		CheckError(os.WriteFile(out, buf.Bytes(), 0644))
+		fmt.Fprintf(os.Stderr, "Wrote %d records to %s\n", records, out)
+	},
+}
+
+func init() {
+	dumpCmd.Flags().Bool("history", false, "Dump every event of the log instead of the live items")
+	dumpCmd.Flags().StringP("output", "o", "", "Write to a file instead of stdout")
+	RootCmd.AddCommand(dumpCmd)
+}
@@ -37,17 +37,41 @@ AGENT-FRIENDLY MODES:
   Inline flags:
       dory edit L-abc123 --tag networking --severity critical
 
+BULK MODE:
+
+  --where applies the same change to every item matching a filter, as one
+  atomic batch. --dry-run previews the affected IDs without writing:
+      dory edit --where 'tag:auth AND type:lesson' --set severity=high --dry-run
+      dory edit --where 'tag:auth' --set tag=authentication --add-ref D-abc123
+
+  --set accepts tag, severity (lessons only), and pinned.
+
 HUMAN MODE:
 
   No flags opens $EDITOR (not recommended for agents)
 
 APPLY/PATCH FIELDS:
-  tag, severity, oneliner, body, refs (array)`,
-	Args: cobra.ExactArgs(1),
+  tag, severity, oneliner, body, refs (array)
+
+The ID may be a full ID, an alias (L-42), or any unique prefix.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if where, _ := cmd.Flags().GetString("where"); where != "" {
+			if len(args) > 0 {
+				return fmt.Errorf("--where edits every matching item; do not also pass an ID")
+			}
+			return nil
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		RequireStore()
 
-		id := args[0]
+		if where, _ := cmd.Flags().GetString("where"); where != "" {
+			editWhere(cmd, where)
+			return
+		}
+
+		id := resolveItemID(args[0])
 
 		applyFlag, _ := cmd.Flags().GetString("apply")
 		patchFlag, _ := cmd.Flags().GetString("patch")
@@ -124,6 +148,7 @@ func editPatch(cmd *cobra.Command, id, patchJSON string) {
 func applyPatch(cmd *cobra.Command, id string, patch *editPatchData) {
 	s := store.New(doryRoot)
 	defer s.Close()
+	applyRefPolicy(cmd, s)
 
 	entry, err := s.GetEntry(id)
 	CheckError(err)
@@ -173,6 +198,7 @@ func applyPatch(cmd *cobra.Command, id string, patch *editPatchData) {
 func editInline(cmd *cobra.Command, id, severity, topic, domain, oneliner string, refs []string) {
 	s := store.New(doryRoot)
 	defer s.Close()
+	applyRefPolicy(cmd, s)
 
 	entry, err := s.GetEntry(id)
 	CheckError(err)
@@ -217,6 +243,7 @@ func editInline(cmd *cobra.Command, id, severity, topic, domain, oneliner string
 func editWithEditor(cmd *cobra.Command, id string) {
 	s := store.New(doryRoot)
 	defer s.Close()
+	applyRefPolicy(cmd, s)
 
 	// Get current content
 	content, err := s.Show(id)
@@ -328,6 +355,9 @@ func parseEditedContent(content string) (*doryfile.Entry, error) {
 	if v, ok := frontmatter["severity"].(string); ok {
 		entry.Severity = v
 	}
+	if v, ok := frontmatter["pinned"].(bool); ok {
+		entry.Pinned = v
+	}
 	if v, ok := frontmatter["refs"].([]interface{}); ok {
 		for _, r := range v {
 			if s, ok := r.(string); ok {
@@ -372,6 +402,19 @@ func init() {
 	editCmd.Flags().StringP("domain", "d", "", "Alias for --tag (deprecated)")
 	editCmd.Flags().StringP("oneliner", "o", "", "Update oneliner/title")
 	editCmd.Flags().StringSliceP("refs", "R", nil, "Update references (comma-separated, replaces existing)")
+	addRefPolicyFlag(editCmd)
+
+	// Bulk mode
+	editCmd.Flags().String("where", "", "Edit every item matching a query filter")
+	editCmd.Flags().StringArray("set", nil, "Set a field on matching items (tag=, severity=, pinned=)")
+	editCmd.Flags().StringSlice("add-ref", nil, "Add refs to matching items")
+	editCmd.Flags().StringSlice("remove-ref", nil, "Remove refs from matching items")
+	editCmd.Flags().Bool("dry-run", false, "Preview the items a --where edit would change")
+	editCmd.ValidArgsFunction = completeIDs(1)
+	editCmd.RegisterFlagCompletionFunc("tag", completeTags)
+	editCmd.RegisterFlagCompletionFunc("refs", completeRefs)
+	editCmd.RegisterFlagCompletionFunc("add-ref", completeRefs)
+	editCmd.RegisterFlagCompletionFunc("remove-ref", completeRefs)
 	editCmd.Flags().MarkHidden("topic")
 	editCmd.Flags().MarkHidden("domain")
 	RootCmd.AddCommand(editCmd)
@@ -0,0 +1,89 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/sibellavia/dory/internal/models"
+	"github.com/sibellavia/dory/internal/query"
+	"github.com/sibellavia/dory/internal/store"
+	"github.com/spf13/cobra"
+)
+
+// editWhere applies --set, --add-ref and --remove-ref to every item matching
+// the --where filter as a single batch.
+func editWhere(cmd *cobra.Command, where string) {
+	filter, err := query.Parse(where)
+	CheckError(err)
+
+	sets, _ := cmd.Flags().GetStringArray("set")
+	edit, err := parseBulkSets(sets)
+	CheckError(err)
+	edit.AddRefs, _ = cmd.Flags().GetStringSlice("add-ref")
+	edit.RemoveRefs, _ = cmd.Flags().GetStringSlice("remove-ref")
+	if edit.Tag == "" && edit.Severity == "" && edit.Pinned == nil && len(edit.AddRefs) == 0 && len(edit.RemoveRefs) == 0 {
+		CheckError(fmt.Errorf("nothing to change: use --set, --add-ref, or --remove-ref with --where"))
+	}
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	s := store.New(doryRoot)
+	defer s.Close()
+	applyRefPolicy(cmd, s)
+
+	result, err := s.BulkUpdate(filter, edit, dryRun)
+	CheckError(err)
+	printBulkResult(cmd, result)
+}
+
+// parseBulkSets turns repeated key=value --set flags into a bulk edit.
+func parseBulkSets(sets []string) (store.BulkEdit, error) {
+	var edit store.BulkEdit
+	for _, set := range sets {
+		key, value, ok := strings.Cut(set, "=")
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+		if !ok || value == "" {
+			return edit, fmt.Errorf("invalid --set %q (expected key=value)", set)
+		}
+		switch key {
+		case "tag", "topic", "domain":
+			edit.Tag = value
+		case "severity":
+			if err := validateSeverityFlag(models.Severity(value)); err != nil {
+				return edit, err
+			}
+			edit.Severity = value
+		case "pinned":
+			pinned, err := strconv.ParseBool(value)
+			if err != nil {
+				return edit, fmt.Errorf("invalid pinned value %q (expected true or false)", value)
+			}
+			edit.Pinned = &pinned
+		default:
+			return edit, fmt.Errorf("cannot --set %q (use tag, severity, or pinned)", key)
+		}
+	}
+	return edit, nil
+}
+
+// printBulkResult reports the items a bulk edit matched and changed.
+func printBulkResult(cmd *cobra.Command, result *store.BulkResult) {
+	OutputResult(cmd, result, func() {
+		changed := make(map[string]bool, len(result.Changed))
+		for _, id := range result.Changed {
+			changed[id] = true
+		}
+		verb := "Updated"
+		if result.DryRun {
+			verb = "Would update"
+		}
+		fmt.Printf("%s %d of %d matching items\n", verb, len(result.Changed), len(result.Matched))
+		for _, item := range result.Matched {
+			mark := " "
+			if changed[item.ID] {
+				mark = "*"
+			}
+			fmt.Printf("  %s %s  %s\n", mark, item.ID, truncateOneliner(item.Oneliner, 60))
+		}
+	})
+}
@@ -3,33 +3,76 @@ package commands
 import (
 	"bytes"
 	"fmt"
-	"os"
+	"path/filepath"
 	"sort"
-	"time"
 
+	"github.com/sibellavia/dory/internal/query"
 	"github.com/sibellavia/dory/internal/store"
 	"github.com/spf13/cobra"
 )
 
 var exportCmd = &cobra.Command{
 	Use:   "export [ids...]",
-	Short: "Export knowledge as markdown",
+	Short: "Export knowledge as markdown or a static HTML site",
 	Long: `Export knowledge items as markdown for inclusion in CLAUDE.md or AGENTS.md.
 
+With --append, the output goes in a managed region of the file
+(<!-- dory:begin ... --> ... <!-- dory:end -->) that later runs with the same
+selection replace in place, and 'dory sync-docs' keeps current.
+
+With --format html, write a self-contained static site to --out instead: an
+index by type and tag with client-side search, one page per item with its
+rendered body, refs, and back-refs, and a graph view. The site needs no
+server; open index.html directly or publish the directory as-is.
+
 Examples:
   dory export                      # Export all knowledge
   dory export --tag architecture   # Export by tag
   dory export D-01JX... D-01JY... L-01JX...  # Export specific items
-  dory export --append CLAUDE.md   # Append to file`,
+  dory export --append CLAUDE.md   # Managed region in file (see dory sync-docs)
+  dory export --filter 'type:lesson AND severity>=high'  # Filter expression (see dory query)
+  dory export --format html --out site/   # Static site for browsing or publishing
+  dory export --format html --out site/ --tag auth`,
 	Run: func(cmd *cobra.Command, args []string) {
 		RequireStore()
 
 		topic := resolveTag(cmd, "topic")
 		appendFile, _ := cmd.Flags().GetString("append")
+		filter := resolveFilter(cmd)
+		format, _ := cmd.Flags().GetString("format")
+		outDir, _ := cmd.Flags().GetString("out")
+
+		switch format {
+		case "", "markdown":
+		case "json", "yaml":
+			outputFormat = format
+		case "html":
+			if outDir == "" {
+				CheckError(fmt.Errorf("--format html requires --out <dir>"))
+			}
+			if appendFile != "" {
+				CheckError(fmt.Errorf("--append cannot be used with --format html"))
+			}
+		default:
+			CheckError(fmt.Errorf("invalid --format %q (expected: markdown, html, json, yaml)", format))
+		}
 
 		s := store.New(doryRoot)
 		defer s.Close()
 
+		if format == "html" {
+			items, err := exportSelection(s, args, topic, filter)
+			CheckError(err)
+			project, description, err := s.Project()
+			CheckError(err)
+			result, err := exportHTML(s, items, project, description, outDir)
+			CheckError(err)
+			OutputResult(cmd, result, func() {
+				fmt.Printf("Wrote site for %d items to %s (open %s)\n", result.Items, outDir, filepath.Join(outDir, "index.html"))
+			})
+			return
+		}
+
 		var output string
 		var err error
 
@@ -38,28 +81,38 @@ Examples:
 			output, err = exportItems(s, args)
 		} else if topic != "" {
 			// Export by topic
-			output, err = exportByTopic(s, topic)
+			output, err = exportByTopic(s, topic, filter)
 		} else {
 			// Export all
-			output, err = exportAll(s)
+			output, err = exportAll(s, filter)
 		}
 		CheckError(err)
 
 		if appendFile != "" {
-			// Append to file
-			f, err := os.OpenFile(appendFile, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0644)
-			CheckError(err)
-			defer f.Close()
-
-			_, err = f.WriteString("\n" + output)
+			// Write a managed region that sync-docs and later runs keep current
+			spec := docSpec{Tag: topic, IDs: args}
+			if filter != nil {
+				spec.Filter, _ = cmd.Flags().GetString("filter")
+			}
+			created, err := upsertDocRegion(appendFile, spec, output)
 			CheckError(err)
+			CheckError(registerDocsFile(appendFile))
 
+			status := "updated"
+			if created {
+				status = "appended"
+			}
 			result := map[string]interface{}{
-				"status": "appended",
+				"status": status,
 				"file":   appendFile,
+				"region": spec.marker(),
 			}
 			OutputResult(cmd, result, func() {
-				fmt.Printf("Appended to %s\n", appendFile)
+				if created {
+					fmt.Printf("Appended to %s\n", appendFile)
+				} else {
+					fmt.Printf("Updated %s\n", appendFile)
+				}
 			})
 			return
 		}
@@ -73,14 +126,15 @@ Examples:
 	},
 }
 
-func exportAll(s *store.Store) (string, error) {
-	items, err := s.List("", "", "", time.Time{}, time.Time{})
+func exportAll(s *store.Store, filter query.Expr) (string, error) {
+	items, err := s.List(store.ListFilter{Query: filter})
 	if err != nil {
 		return "", err
 	}
 
 	var buf bytes.Buffer
 	buf.WriteString("## Project Knowledge\n\n")
+	items = writePinned(&buf, items)
 
 	// Group by type
 	var lessons, decisions, conventions []store.ListItem
@@ -125,14 +179,15 @@ func exportAll(s *store.Store) (string, error) {
 	return buf.String(), nil
 }
 
-func exportByTopic(s *store.Store, topic string) (string, error) {
-	items, err := s.List(topic, "", "", time.Time{}, time.Time{})
+func exportByTopic(s *store.Store, topic string, filter query.Expr) (string, error) {
+	items, err := s.List(store.ListFilter{Topic: topic, Query: filter})
 	if err != nil {
 		return "", err
 	}
 
 	var buf bytes.Buffer
 	buf.WriteString(fmt.Sprintf("## Knowledge: %s\n\n", topic))
+	items = writePinned(&buf, items)
 
 	// Group by type
 	var lessons, decisions, conventions []store.ListItem
@@ -177,9 +232,36 @@ func exportByTopic(s *store.Store, topic string) (string, error) {
 	return buf.String(), nil
 }
 
+// writePinned writes a Pinned section for the pinned items and returns
+// the rest, so pinned items are not repeated under their type.
+func writePinned(buf *bytes.Buffer, items []store.ListItem) []store.ListItem {
+	var pinned, rest []store.ListItem
+	for _, item := range items {
+		if item.Pinned {
+			pinned = append(pinned, item)
+		} else {
+			rest = append(rest, item)
+		}
+	}
+	if len(pinned) == 0 {
+		return rest
+	}
+
+	buf.WriteString("### Pinned\n\n")
+	for _, item := range pinned {
+		if item.Severity != "" {
+			buf.WriteString(fmt.Sprintf("- **%s** [%s]: %s\n", item.ID, item.Severity, item.Oneliner))
+		} else {
+			buf.WriteString(fmt.Sprintf("- **%s**: %s\n", item.ID, item.Oneliner))
+		}
+	}
+	buf.WriteString("\n")
+	return rest
+}
+
 func exportItems(s *store.Store, ids []string) (string, error) {
 	// Get all items and filter
-	allItems, err := s.List("", "", "", time.Time{}, time.Time{})
+	allItems, err := s.List(store.ListFilter{})
 	if err != nil {
 		return "", err
 	}
@@ -193,7 +275,13 @@ func exportItems(s *store.Store, ids []string) (string, error) {
 	var buf bytes.Buffer
 	buf.WriteString("## Project Knowledge\n\n")
 
-	for _, id := range ids {
+	for _, ref := range ids {
+		id := ref
+		if resolved, err := s.ResolveID(ref); err == nil {
+			id = resolved
+		} else if _, ambiguous := err.(*store.AmbiguousIDError); ambiguous {
+			return "", err
+		}
 		if item, ok := itemMap[id]; ok {
 			switch item.Type {
 			case "lesson":
@@ -210,10 +298,45 @@ func exportItems(s *store.Store, ids []string) (string, error) {
 	return buf.String(), nil
 }
 
+// exportSelection returns the items an export covers: the given IDs, or
+// every item matching the tag and filter.
+func exportSelection(s *store.Store, ids []string, topic string, filter query.Expr) ([]store.ListItem, error) {
+	if len(ids) == 0 {
+		return s.List(store.ListFilter{Topic: topic, Query: filter})
+	}
+	all, err := s.List(store.ListFilter{})
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]store.ListItem, len(all))
+	for _, item := range all {
+		byID[item.ID] = item
+	}
+	var items []store.ListItem
+	seen := make(map[string]bool)
+	for _, ref := range ids {
+		id, err := s.ResolveID(ref)
+		if err != nil {
+			return nil, err
+		}
+		if item, ok := byID[id]; ok && !seen[id] {
+			seen[id] = true
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
 func init() {
 	exportCmd.Flags().StringP("tag", "T", "", "Export items for a specific tag/category")
 	exportCmd.Flags().StringP("topic", "t", "", "Alias for --tag (deprecated)")
-	exportCmd.Flags().StringP("append", "a", "", "Append output to file")
+	exportCmd.Flags().StringP("append", "a", "", "Write output to a managed region in file, updated in place on later runs")
+	// --format shadows the global output format flag on this command.
+	exportCmd.Flags().String("format", "", "Export format: markdown (default), html, json, yaml")
+	exportCmd.Flags().String("out", "", "Output directory for --format html")
+	exportCmd.Flags().String("filter", "", "Only export items matching a filter expression (see dory query)")
+	exportCmd.ValidArgsFunction = completeIDs(0)
+	exportCmd.RegisterFlagCompletionFunc("tag", completeTags)
 	exportCmd.Flags().MarkHidden("topic")
 	RootCmd.AddCommand(exportCmd)
 }
@@ -0,0 +1,141 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/sibellavia/dory/internal/doryfile"
+	"github.com/sibellavia/dory/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var exportADRCmd = &cobra.Command{
+	Use:   "adr <dir>",
+	Short: "Write decisions as numbered ADR files",
+	Long: `Write decisions to a directory as numbered ADR files (0001-title.md) in
+MADR or Nygard style, next to any ADRs already there.
+
+Each file records its decision's dory ID, so a decision always maps to the
+same file: later exports rewrite it in place (even after a title change) and
+only touch files whose content changed. New decisions get the next free
+number. Files are never deleted.
+
+Without --style, existing files keep their own style and new files follow
+the newest ADR in the directory (MADR for an empty directory).
+
+Examples:
+  dory export adr docs/adr
+  dory export adr docs/adr --style nygard --tag architecture`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		RequireStore()
+		tag, _ := cmd.Flags().GetString("tag")
+		filter := resolveFilter(cmd)
+		style, _ := cmd.Flags().GetString("style")
+		if style != "" && style != adrMADR && style != adrNygard {
+			CheckError(fmt.Errorf("invalid --style %q (expected: madr, nygard)", style))
+		}
+
+		s := store.New(doryRoot)
+		defer s.Close()
+		items, err := s.List(store.ListFilter{Type: "decision", Topic: tag, Query: filter})
+		CheckError(err)
+		result, err := exportADRs(s, items, args[0], style)
+		CheckError(err)
+
+		OutputResult(cmd, result, func() {
+			for _, file := range result.Files {
+				fmt.Printf("%-9s %s  %s\n", file.Status, file.ID, file.File)
+			}
+			fmt.Printf("\n%s (%s style) in %s\n", result.summary(), result.Style, result.Dir)
+		})
+	},
+}
+
+// exportADRs writes one ADR file per decision into dir. A decision reuses
+// the file carrying its dory ID, or an unclaimed file with the same title
+// (an ADR it was imported from); otherwise it gets the next number.
+func exportADRs(s *store.Store, items []store.ListItem, dir, style string) (*ADRResult, error) {
+	existing, err := readADRDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	keep := style == ""
+	if keep {
+		style = adrMADR
+		if len(existing) > 0 {
+			style = existing[len(existing)-1].Style
+		}
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]*adrRecord)
+	byTitle := make(map[string]*adrRecord)
+	next := 1
+	for _, record := range existing {
+		if record.DoryID != "" {
+			byID[record.DoryID] = record
+		} else if _, ok := byTitle[record.Title]; !ok {
+			byTitle[record.Title] = record
+		}
+		if record.Number >= next {
+			next = record.Number + 1
+		}
+	}
+
+	entries := make([]*doryfile.Entry, 0, len(items))
+	for _, item := range items {
+		entry, err := s.GetEntry(item.ID)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].Created.Equal(entries[j].Created) {
+			return entries[i].Created.Before(entries[j].Created)
+		}
+		return entries[i].ID < entries[j].ID
+	})
+
+	result := &ADRResult{Dir: dir, Style: style}
+	for _, entry := range entries {
+		record := byID[entry.ID]
+		if record == nil {
+			record = byTitle[entry.Oneliner]
+			delete(byTitle, entry.Oneliner)
+		}
+		if record == nil {
+			record = &adrRecord{File: adrFileFor(next, entry.Oneliner), Number: next}
+			next++
+		}
+
+		fileStyle := style
+		if keep && record.Style != "" {
+			fileStyle = record.Style
+		}
+		path := filepath.Join(dir, record.File)
+		content := renderADR(entry, record.Number, fileStyle)
+		status := "written"
+		if old, err := os.ReadFile(path); err == nil && string(old) == content {
+			status = "unchanged"
+		} else if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			return nil, err
+		}
+		result.Files = append(result.Files, ADRFileResult{File: record.File, ID: entry.ID, Title: entry.Oneliner, Status: status})
+	}
+	return result, nil
+}
+
+func init() {
+	exportADRCmd.Flags().StringP("tag", "T", "", "Only export decisions with this tag")
+	exportADRCmd.Flags().String("filter", "", "Only export decisions matching a filter expression (see dory query)")
+	exportADRCmd.Flags().String("style", "", "Rewrite every file in this style: madr, nygard (default: keep each file's style)")
+	exportADRCmd.RegisterFlagCompletionFunc("tag", completeTags)
+	exportADRCmd.RegisterFlagCompletionFunc("style", cobra.FixedCompletions([]string{adrMADR, adrNygard}, cobra.ShellCompDirectiveNoFileComp))
+	exportCmd.AddCommand(exportADRCmd)
+}
@@ -0,0 +1,565 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sibellavia/dory/internal/models"
+	"github.com/sibellavia/dory/internal/store"
+)
+
+// siteItem is one exported item as rendered on the site.
+type siteItem struct {
+	ID       string
+	Alias    string
+	Type     string
+	Tag      string
+	Severity string
+	Oneliner string
+	Created  string
+	Pinned   bool
+	Body     template.HTML
+	Refs     []siteLink
+	RefBy    []siteLink
+	Href     string
+	body     string
+}
+
+// siteLink is a ref on an item page. Href is empty for refs to items that
+// were not exported, such as tasks or filtered-out items.
+type siteLink struct {
+	ID       string
+	Oneliner string
+	Href     string
+}
+
+type siteGroup struct {
+	Name   string
+	Anchor string
+	Items  []*siteItem
+}
+
+// sitePage is the data every page template gets.
+type sitePage struct {
+	Project     string
+	Description string
+	Generated   string
+	Title       string
+	Root        string
+	Count       int
+	Pinned      []*siteItem
+	Types       []siteGroup
+	Tags        []siteGroup
+	Item        *siteItem
+	Graph       template.HTML
+}
+
+// HTMLExportResult summarizes a static site export.
+type HTMLExportResult struct {
+	Status string `json:"status" yaml:"status"`
+	Path   string `json:"path" yaml:"path"`
+	Items  int    `json:"items" yaml:"items"`
+	Pages  int    `json:"pages" yaml:"pages"`
+}
+
+// exportHTML writes a self-contained static site for items into dir: an
+// index by type and tag with client-side search, one page per item, and a
+// graph view. Stale item pages from earlier exports are removed.
+func exportHTML(s *store.Store, items []store.ListItem, project, description, dir string) (*HTMLExportResult, error) {
+	byID := make(map[string]*siteItem, len(items))
+	var site []*siteItem
+	for _, item := range items {
+		entry, err := s.GetEntry(item.ID)
+		if err != nil {
+			return nil, err
+		}
+		tag := entry.Topic
+		if tag == "" {
+			tag = entry.Domain
+		}
+		si := &siteItem{
+			ID:       entry.ID,
+			Alias:    entry.Alias,
+			Type:     entry.Type,
+			Tag:      tag,
+			Severity: entry.Severity,
+			Oneliner: entry.Oneliner,
+			Created:  entry.Created.Format("2006-01-02"),
+			Pinned:   entry.Pinned,
+			Href:     "items/" + entry.ID + ".html",
+			body:     entry.Body,
+		}
+		byID[si.ID] = si
+		site = append(site, si)
+	}
+	sort.Slice(site, func(i, j int) bool { return site[i].ID < site[j].ID })
+
+	link := func(id string) siteLink {
+		if target, ok := byID[id]; ok {
+			return siteLink{ID: id, Oneliner: target.Oneliner, Href: id + ".html"}
+		}
+		return siteLink{ID: id}
+	}
+	for _, si := range site {
+		entry, err := s.GetEntry(si.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, ref := range entry.Refs {
+			si.Refs = append(si.Refs, link(ref))
+			if target, ok := byID[ref]; ok && ref != si.ID {
+				target.RefBy = append(target.RefBy, link(si.ID))
+			}
+		}
+		si.Body = template.HTML(markdownHTML(dropTitle(si.body, si.Oneliner), func(id string) string {
+			if _, ok := byID[id]; ok {
+				return id + ".html"
+			}
+			return ""
+		}))
+	}
+
+	itemsDir := filepath.Join(dir, "items")
+	if err := os.MkdirAll(itemsDir, 0755); err != nil {
+		return nil, err
+	}
+	page := sitePage{
+		Project:     project,
+		Description: description,
+		Generated:   time.Now().UTC().Format("2006-01-02 15:04 UTC"),
+		Count:       len(site),
+	}
+	pages := 0
+	write := func(name, tmpl string, data sitePage) error {
+		var buf bytes.Buffer
+		if err := siteTemplates.ExecuteTemplate(&buf, tmpl, data); err != nil {
+			return err
+		}
+		pages++
+		return os.WriteFile(filepath.Join(dir, name), buf.Bytes(), 0644)
+	}
+
+	index := page
+	index.Title = "Index"
+	index.Pinned, index.Types, index.Tags = siteGroups(site)
+	if err := write("index.html", "index", index); err != nil {
+		return nil, err
+	}
+	graph := page
+	graph.Title = "Graph"
+	graph.Graph = template.HTML(graphSVG(site))
+	if err := write("graph.html", "graph", graph); err != nil {
+		return nil, err
+	}
+
+	written := make(map[string]bool, len(site))
+	for _, si := range site {
+		itemPage := page
+		itemPage.Title = si.Oneliner
+		itemPage.Root = "../"
+		itemPage.Item = si
+		name := si.ID + ".html"
+		if err := write(filepath.Join("items", name), "item", itemPage); err != nil {
+			return nil, err
+		}
+		written[name] = true
+	}
+	existing, err := os.ReadDir(itemsDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range existing {
+		if !f.IsDir() && strings.HasSuffix(f.Name(), ".html") && !written[f.Name()] {
+			if err := os.Remove(filepath.Join(itemsDir, f.Name())); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "style.css"), []byte(siteCSS), 0644); err != nil {
+		return nil, err
+	}
+	searchJS, err := siteSearchJS(site)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "search.js"), searchJS, 0644); err != nil {
+		return nil, err
+	}
+
+	return &HTMLExportResult{Status: "exported", Path: dir, Items: len(site), Pages: pages}, nil
+}
+
+// dropTitle removes a leading "# <oneliner>" heading, which the page
+// already shows as its title.
+func dropTitle(body, oneliner string) string {
+	trimmed := strings.TrimLeft(body, "\n")
+	first, rest, _ := strings.Cut(trimmed, "\n")
+	if strings.HasPrefix(first, "# ") && strings.TrimSpace(first[2:]) == strings.TrimSpace(oneliner) {
+		return rest
+	}
+	return body
+}
+
+// siteGroups splits items into pinned, per-type, and per-tag groups. Built-in
+// types come first, then custom types by name.
+func siteGroups(items []*siteItem) ([]*siteItem, []siteGroup, []siteGroup) {
+	var pinned []*siteItem
+	byType := make(map[string][]*siteItem)
+	byTag := make(map[string][]*siteItem)
+	for _, item := range items {
+		if item.Pinned {
+			pinned = append(pinned, item)
+		}
+		byType[item.Type] = append(byType[item.Type], item)
+		if item.Tag != "" {
+			byTag[item.Tag] = append(byTag[item.Tag], item)
+		}
+	}
+
+	order := map[string]int{"lesson": 0, "decision": 1, "convention": 2}
+	typeNames := make([]string, 0, len(byType))
+	for name := range byType {
+		typeNames = append(typeNames, name)
+	}
+	sort.Slice(typeNames, func(i, j int) bool {
+		oi, iBuiltin := order[typeNames[i]]
+		oj, jBuiltin := order[typeNames[j]]
+		if iBuiltin != jBuiltin {
+			return iBuiltin
+		}
+		if iBuiltin {
+			return oi < oj
+		}
+		return typeNames[i] < typeNames[j]
+	})
+	var types []siteGroup
+	for _, name := range typeNames {
+		types = append(types, siteGroup{Name: name, Anchor: "type-" + siteSlug(name), Items: byType[name]})
+	}
+
+	tagNames := make([]string, 0, len(byTag))
+	for name := range byTag {
+		tagNames = append(tagNames, name)
+	}
+	sort.Strings(tagNames)
+	var tags []siteGroup
+	for _, name := range tagNames {
+		tags = append(tags, siteGroup{Name: name, Anchor: "tag-" + siteSlug(name), Items: byTag[name]})
+	}
+	return pinned, types, tags
+}
+
+func siteSlug(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('-')
+		}
+	}
+	return b.String()
+}
+
+// siteSearchJS builds search.js: the search index as a script variable, so
+// the site works from file:// without fetching anything, plus the search box.
+func siteSearchJS(items []*siteItem) ([]byte, error) {
+	type entry struct {
+		ID       string `json:"id"`
+		URL      string `json:"url"`
+		Type     string `json:"type"`
+		Tag      string `json:"tag,omitempty"`
+		Oneliner string `json:"oneliner"`
+		Text     string `json:"text"`
+	}
+	index := make([]entry, 0, len(items))
+	for _, item := range items {
+		text := strings.Join([]string{item.ID, item.Alias, item.Type, item.Tag, item.Oneliner, item.body}, " ")
+		index = append(index, entry{
+			ID:       item.ID,
+			URL:      item.Href,
+			Type:     item.Type,
+			Tag:      item.Tag,
+			Oneliner: item.Oneliner,
+			Text:     strings.ToLower(strings.Join(strings.Fields(text), " ")),
+		})
+	}
+	data, err := json.Marshal(index)
+	if err != nil {
+		return nil, err
+	}
+	return []byte("var DORY_INDEX = " + string(data) + ";\n" + siteSearchScript), nil
+}
+
+// graphSVG lays out the ref graph of items and draws it as an SVG whose
+// nodes link to item pages.
+func graphSVG(items []*siteItem) string {
+	if len(items) == 0 {
+		return `<p class="empty">No items to graph.</p>`
+	}
+	index := make(map[string]int, len(items))
+	for i, item := range items {
+		index[item.ID] = i
+	}
+	var edges [][2]int
+	for i, item := range items {
+		for _, ref := range item.Refs {
+			if j, ok := index[ref.ID]; ok && j != i {
+				edges = append(edges, [2]int{i, j})
+			}
+		}
+	}
+	pos := layoutGraph(len(items), edges)
+
+	const margin, radius = 40.0, 7.0
+	minX, minY, maxX, maxY := math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	for _, p := range pos {
+		minX, minY = math.Min(minX, p[0]), math.Min(minY, p[1])
+		maxX, maxY = math.Max(maxX, p[0]), math.Max(maxY, p[1])
+	}
+	width, height := maxX-minX+2*margin+160, maxY-minY+2*margin
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg id="graph" xmlns="http://www.w3.org/2000/svg" viewBox="%.0f %.0f %.0f %.0f">`, minX-margin, minY-margin, width, height)
+	b.WriteString(`<defs><marker id="arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="6" markerHeight="6" orient="auto-start-reverse"><path d="M0,0 L10,5 L0,10 z" fill="#9ca3af"/></marker></defs>`)
+	for _, e := range edges {
+		from, to := pos[e[0]], pos[e[1]]
+		dx, dy := to[0]-from[0], to[1]-from[1]
+		dist := math.Max(math.Hypot(dx, dy), 1)
+		x2, y2 := to[0]-dx/dist*(radius+2), to[1]-dy/dist*(radius+2)
+		fmt.Fprintf(&b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" marker-end="url(#arrow)"/>`, from[0], from[1], x2, y2)
+	}
+	for i, item := range items {
+		style := styleFor(GraphNode{Type: item.Type, Severity: models.Severity(item.Severity)})
+		dash := ""
+		if style.Dashed {
+			dash = ` stroke-dasharray="3 2"`
+		}
+		label := item.Alias
+		if label == "" {
+			label = truncateOneliner(item.Oneliner, 32)
+		}
+		fmt.Fprintf(&b, `<a href="%s"><title>%s</title><circle cx="%.1f" cy="%.1f" r="%.0f" fill="%s" stroke="%s" stroke-width="%d"%s/><text x="%.1f" y="%.1f">%s</text></a>`,
+			template.HTMLEscapeString(item.Href),
+			template.HTMLEscapeString(item.ID+": "+item.Oneliner),
+			pos[i][0], pos[i][1], radius, style.Fill, style.Stroke, style.Width, dash,
+			pos[i][0]+radius+4, pos[i][1]+4, template.HTMLEscapeString(label))
+	}
+	b.WriteString(`</svg>`)
+	return b.String()
+}
+
+// layoutGraph places n nodes with a force-directed (Fruchterman-Reingold)
+// layout. Starting positions are fixed, so the same graph always gets the
+// same picture.
+func layoutGraph(n int, edges [][2]int) [][2]float64 {
+	pos := make([][2]float64, n)
+	if n == 1 {
+		return pos
+	}
+	side := 120 * math.Sqrt(float64(n))
+	k := side / math.Sqrt(float64(n))
+	for i := range pos {
+		angle := 2 * math.Pi * float64(i) / float64(n)
+		pos[i] = [2]float64{side/2 + side/3*math.Cos(angle), side/2 + side/3*math.Sin(angle)}
+	}
+
+	iterations := 300
+	if n > 300 {
+		iterations = 60
+	}
+	disp := make([][2]float64, n)
+	for iter := 0; iter < iterations; iter++ {
+		for i := range disp {
+			disp[i] = [2]float64{}
+		}
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				dx, dy := pos[i][0]-pos[j][0], pos[i][1]-pos[j][1]
+				dist := math.Max(math.Hypot(dx, dy), 0.01)
+				force := k * k / dist
+				disp[i][0] += dx / dist * force
+				disp[i][1] += dy / dist * force
+				disp[j][0] -= dx / dist * force
+				disp[j][1] -= dy / dist * force
+			}
+		}
+		for _, e := range edges {
+			u, v := e[0], e[1]
+			dx, dy := pos[u][0]-pos[v][0], pos[u][1]-pos[v][1]
+			dist := math.Max(math.Hypot(dx, dy), 0.01)
+			force := dist * dist / k
+			disp[u][0] -= dx / dist * force
+			disp[u][1] -= dy / dist * force
+			disp[v][0] += dx / dist * force
+			disp[v][1] += dy / dist * force
+		}
+		temp := side / 10 * (1 - float64(iter)/float64(iterations))
+		for i := range pos {
+			length := math.Max(math.Hypot(disp[i][0], disp[i][1]), 0.01)
+			step := math.Min(length, temp)
+			pos[i][0] = math.Min(side, math.Max(0, pos[i][0]+disp[i][0]/length*step))
+			pos[i][1] = math.Min(side, math.Max(0, pos[i][1]+disp[i][1]/length*step))
+		}
+	}
+	return pos
+}
+
+var siteTemplates = template.Must(template.New("site").Funcs(template.FuncMap{"slug": siteSlug}).Parse(`
+{{define "head"}}<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}} · {{.Project}}</title>
+<link rel="stylesheet" href="{{.Root}}style.css">
+</head>
+<body>
+<header>
+<a class="brand" href="{{.Root}}index.html">{{.Project}}</a>
+<nav><a href="{{.Root}}index.html">Index</a> <a href="{{.Root}}graph.html">Graph</a></nav>
+</header>
+<main>
+{{end}}
+
+{{define "foot"}}</main>
+<footer>{{.Count}} items · exported by dory on {{.Generated}}</footer>
+</body>
+</html>
+{{end}}
+
+{{define "row"}}<li><a href="{{.Href}}">{{.ID}}</a>{{if .Alias}} <span class="alias">{{.Alias}}</span>{{end}}{{if .Severity}} <span class="sev sev-{{.Severity}}">{{.Severity}}</span>{{end}} {{.Oneliner}}</li>
+{{end}}
+
+{{define "index"}}{{template "head" .}}
+<h1>{{.Project}}</h1>
+{{if .Description}}<p class="lead">{{.Description}}</p>{{end}}
+<input id="search" type="search" placeholder="Search {{.Count}} items…" autocomplete="off">
+<ul id="results" class="items" hidden></ul>
+<nav class="toc">
+{{range .Types}}<a href="#{{.Anchor}}">{{.Name}} ({{len .Items}})</a> {{end}}
+</nav>
+{{if .Pinned}}<section><h2>Pinned</h2><ul class="items">{{range .Pinned}}{{template "row" .}}{{end}}</ul></section>{{end}}
+<h2>By type</h2>
+{{range .Types}}<section id="{{.Anchor}}"><h3>{{.Name}}</h3><ul class="items">{{range .Items}}{{template "row" .}}{{end}}</ul></section>
+{{end}}
+<h2>By tag</h2>
+<p class="tags">{{range .Tags}}<a href="#{{.Anchor}}">{{.Name}}</a> {{end}}</p>
+{{range .Tags}}<section id="{{.Anchor}}"><h3>{{.Name}}</h3><ul class="items">{{range .Items}}{{template "row" .}}{{end}}</ul></section>
+{{end}}
+<script src="search.js"></script>
+{{template "foot" .}}{{end}}
+
+{{define "item"}}{{template "head" .}}{{with .Item}}
+<h1>{{.Oneliner}}</h1>
+<p class="meta"><code>{{.ID}}</code>{{if .Alias}} · {{.Alias}}{{end}} · <a href="../index.html#type-{{slug .Type}}">{{.Type}}</a>{{if .Tag}} · tag <a href="../index.html#tag-{{slug .Tag}}">{{.Tag}}</a>{{end}}{{if .Severity}} · <span class="sev sev-{{.Severity}}">{{.Severity}}</span>{{end}}{{if .Pinned}} · pinned{{end}} · {{.Created}}</p>
+<article>{{.Body}}</article>
+{{if .Refs}}<section><h2>Refs</h2><ul class="items">{{range .Refs}}<li>{{if .Href}}<a href="{{.Href}}">{{.ID}}</a> {{.Oneliner}}{{else}}<code>{{.ID}}</code>{{end}}</li>{{end}}</ul></section>{{end}}
+{{if .RefBy}}<section><h2>Referenced by</h2><ul class="items">{{range .RefBy}}<li><a href="{{.Href}}">{{.ID}}</a> {{.Oneliner}}</li>{{end}}</ul></section>{{end}}
+{{end}}{{template "foot" .}}{{end}}
+
+{{define "graph"}}{{template "head" .}}
+<h1>Graph</h1>
+<p class="lead">Arrows point from an item to the items it refs. Scroll to zoom, drag to pan, click a node to open it.</p>
+<div class="graph">{{.Graph}}</div>
+<script>
+(function () {
+  var svg = document.getElementById("graph");
+  if (!svg) return;
+  var box = svg.viewBox.baseVal, drag = null;
+  svg.addEventListener("wheel", function (e) {
+    e.preventDefault();
+    var scale = e.deltaY > 0 ? 1.1 : 1 / 1.1;
+    var r = svg.getBoundingClientRect();
+    var x = box.x + (e.clientX - r.left) / r.width * box.width;
+    var y = box.y + (e.clientY - r.top) / r.height * box.height;
+    box.x = x - (x - box.x) * scale; box.y = y - (y - box.y) * scale;
+    box.width *= scale; box.height *= scale;
+  });
+  svg.addEventListener("mousedown", function (e) { drag = {x: e.clientX, y: e.clientY}; });
+  window.addEventListener("mouseup", function () { drag = null; });
+  window.addEventListener("mousemove", function (e) {
+    if (!drag) return;
+    var r = svg.getBoundingClientRect();
+    box.x -= (e.clientX - drag.x) / r.width * box.width;
+    box.y -= (e.clientY - drag.y) / r.height * box.height;
+    drag = {x: e.clientX, y: e.clientY};
+  });
+})();
+</script>
+{{template "foot" .}}{{end}}
+`))
+
+const siteSearchScript = `(function () {
+  var input = document.getElementById("search");
+  var results = document.getElementById("results");
+  if (!input || !results) return;
+  input.addEventListener("input", function () {
+    var terms = input.value.toLowerCase().split(/\s+/).filter(Boolean);
+    results.innerHTML = "";
+    results.hidden = terms.length === 0;
+    if (!terms.length) return;
+    var hits = DORY_INDEX.filter(function (item) {
+      return terms.every(function (t) { return item.text.indexOf(t) >= 0; });
+    });
+    hits.sort(function (a, b) {
+      var ta = terms.every(function (t) { return a.oneliner.toLowerCase().indexOf(t) >= 0; });
+      var tb = terms.every(function (t) { return b.oneliner.toLowerCase().indexOf(t) >= 0; });
+      return ta === tb ? 0 : ta ? -1 : 1;
+    });
+    if (!hits.length) {
+      var none = document.createElement("li");
+      none.textContent = "No matches";
+      results.appendChild(none);
+      return;
+    }
+    hits.slice(0, 50).forEach(function (item) {
+      var li = document.createElement("li");
+      var a = document.createElement("a");
+      a.href = item.url;
+      a.textContent = item.id;
+      li.appendChild(a);
+      li.appendChild(document.createTextNode(" [" + item.type + "] " + item.oneliner));
+      results.appendChild(li);
+    });
+  });
+})();
+`
+
+const siteCSS = `body { margin: 0; font: 15px/1.55 -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; color: #1f2937; background: #fff; }
+header { display: flex; justify-content: space-between; align-items: center; padding: 0.6rem 1.5rem; border-bottom: 1px solid #e5e7eb; background: #f9fafb; }
+header a { color: #374151; text-decoration: none; margin-left: 1rem; }
+header .brand { font-weight: 600; margin-left: 0; }
+main { max-width: 860px; margin: 0 auto; padding: 1.5rem; }
+footer { max-width: 860px; margin: 2rem auto; padding: 0 1.5rem; color: #9ca3af; font-size: 0.85rem; }
+a { color: #2563eb; }
+h1 { font-size: 1.6rem; margin: 0.2rem 0 0.6rem; }
+h2 { font-size: 1.2rem; margin-top: 2rem; border-bottom: 1px solid #e5e7eb; padding-bottom: 0.2rem; }
+h3 { font-size: 1rem; text-transform: capitalize; }
+.lead, .meta { color: #6b7280; }
+.toc a, .tags a { margin-right: 0.8rem; text-transform: capitalize; }
+ul.items { padding-left: 1.2rem; }
+ul.items li { margin: 0.2rem 0; }
+ul.items a { font-family: ui-monospace, SFMono-Regular, Menlo, monospace; font-size: 0.85rem; }
+.alias { color: #6b7280; font-size: 0.85rem; }
+.sev { font-size: 0.75rem; padding: 0 0.35rem; border-radius: 3px; background: #f3f4f6; }
+.sev-critical { background: #fee2e2; color: #b91c1c; }
+.sev-high { background: #ffedd5; color: #c2410c; }
+#search { width: 100%; box-sizing: border-box; padding: 0.5rem 0.7rem; font-size: 1rem; border: 1px solid #d1d5db; border-radius: 6px; margin: 0.8rem 0; }
+article pre { background: #f3f4f6; padding: 0.8rem; overflow-x: auto; border-radius: 4px; }
+article code, .meta code { font-family: ui-monospace, SFMono-Regular, Menlo, monospace; font-size: 0.88em; }
+article blockquote { margin: 0; padding-left: 1rem; border-left: 3px solid #d1d5db; color: #4b5563; }
+.graph { border: 1px solid #e5e7eb; border-radius: 6px; }
+.graph svg { width: 100%; height: 75vh; cursor: grab; }
+.graph line { stroke: #9ca3af; stroke-width: 1; }
+.graph text { font-size: 11px; fill: #374151; }
+`
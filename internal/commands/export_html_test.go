@@ -0,0 +1,101 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sibellavia/dory/internal/models"
+	"github.com/sibellavia/dory/internal/store"
+)
+
+func TestMarkdownHTML(t *testing.T) {
+	body := "## Why\n\nWe **chose** `a<b>`, see L-01JX0000000000000000000000 and [docs](https://example.com) or [bad](javascript:alert(1)).\n\n- one\n- two\n\n```go\nx := \"<y>\"\n```\n"
+	got := markdownHTML(body, func(id string) string { return id + ".html" })
+	for _, want := range []string{
+		"<h2>Why</h2>",
+		"<strong>chose</strong>",
+		"<code>a&lt;b&gt;</code>",
+		`<a href="L-01JX0000000000000000000000.html">L-01JX0000000000000000000000</a>`,
+		`<a href="https://example.com">docs</a>`,
+		"[bad](javascript:alert(1))",
+		"<ul>\n<li>one</li>\n<li>two</li>\n</ul>",
+		`<pre><code class="language-go">x := &#34;&lt;y&gt;&#34;</code></pre>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %s:\n%s", want, got)
+		}
+	}
+}
+
+func TestExportHTML(t *testing.T) {
+	dir := t.TempDir()
+	s := store.New(filepath.Join(dir, ".dory"))
+	defer s.Close()
+	if err := s.Init("project", "Test project"); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	decision, err := s.Decide("Use <Postgres>", "db", "", "# Use <Postgres>\n\nIt is **boring**.", nil)
+	if err != nil {
+		t.Fatalf("decide: %v", err)
+	}
+	lesson, err := s.Learn("Retry on 429", "api", models.SeverityHigh, "See "+decision+".", []string{decision})
+	if err != nil {
+		t.Fatalf("learn: %v", err)
+	}
+	items, err := s.List(store.ListFilter{})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+
+	out := filepath.Join(dir, "site")
+	if err := os.MkdirAll(filepath.Join(out, "items"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	stale := filepath.Join(out, "items", "L-STALE.html")
+	if err := os.WriteFile(stale, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := exportHTML(s, items, "project", "Test project", out)
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if result.Items != 2 || result.Pages != 4 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Fatalf("expected stale page to be removed, got %v", err)
+	}
+
+	read := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(out, name))
+		if err != nil {
+			t.Fatalf("read %s: %v", name, err)
+		}
+		return string(data)
+	}
+	index := read("index.html")
+	for _, want := range []string{"Test project", `id="type-lesson"`, `id="tag-db"`, "Use &lt;Postgres&gt;", `href="items/` + lesson + `.html"`} {
+		if !strings.Contains(index, want) {
+			t.Errorf("index missing %s", want)
+		}
+	}
+	page := read(filepath.Join("items", decision+".html"))
+	if strings.Contains(page, "<article><h1>") {
+		t.Errorf("expected the title heading to be dropped from the body:\n%s", page)
+	}
+	if !strings.Contains(page, "Referenced by") || !strings.Contains(page, `href="`+lesson+`.html"`) {
+		t.Errorf("expected a back-ref to %s:\n%s", lesson, page)
+	}
+	if !strings.Contains(read(filepath.Join("items", lesson+".html")), `<a href="`+decision+`.html">`+decision+`</a>.`) {
+		t.Errorf("expected the body mention of %s to link to its page", decision)
+	}
+	if graph := read("graph.html"); strings.Count(graph, "<circle") != 2 || strings.Count(graph, "<line") != 1 {
+		t.Errorf("expected 2 nodes and 1 edge in the graph")
+	}
+	if !strings.HasPrefix(read("search.js"), "var DORY_INDEX = [") {
+		t.Errorf("expected search.js to start with the index")
+	}
+}
@@ -0,0 +1,121 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/sibellavia/dory/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var exportVaultCmd = &cobra.Command{
+	Use:   "vault <dir>",
+	Short: "Write items as notes in an Obsidian/markdown vault",
+	Long: `Write one markdown note per item into a vault directory (<type>/<id>.md),
+with YAML frontmatter (id, type, oneliner, tags, severity, refs as
+[[wikilinks]]) and the item body.
+
+Each note records a content hash, so notes are only rewritten when the item
+changed, and notes edited in the vault since the last sync are reported as
+conflicts instead of overwritten; run 'dory import vault' first or pass
+--force. Notes moved inside the vault are found by their id. Notes of items
+deleted from dory are removed unless they were edited.
+
+Examples:
+  dory export vault ~/notes/project
+  dory export vault vault/ --tag auth
+  dory export vault vault/ --force   # Overwrite notes edited in the vault`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		RequireStore()
+		tag, _ := cmd.Flags().GetString("tag")
+		filter := resolveFilter(cmd)
+		force, _ := cmd.Flags().GetBool("force")
+
+		s := store.New(doryRoot)
+		defer s.Close()
+		items, err := s.List(store.ListFilter{Topic: tag, Query: filter})
+		CheckError(err)
+		result, err := exportVault(s, items, args[0], force)
+		CheckError(err)
+		OutputResult(cmd, result, result.print)
+	},
+}
+
+// exportVault writes a note per item into dir, keeping notes where they are
+// and refusing to overwrite notes edited in the vault unless force is set.
+func exportVault(s *store.Store, items []store.ListItem, dir string, force bool) (*VaultResult, error) {
+	notes, err := readVault(dir)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*vaultNote, len(notes))
+	for _, note := range notes {
+		if note.Front.ID != "" && byID[note.Front.ID] == nil {
+			byID[note.Front.ID] = note
+		}
+	}
+	all, err := s.List(store.ListFilter{})
+	if err != nil {
+		return nil, err
+	}
+	live := make(map[string]bool, len(all))
+	for _, item := range all {
+		live[item.ID] = true
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+
+	result := &VaultResult{Dir: dir}
+	for _, item := range items {
+		entry, err := s.GetEntry(item.ID)
+		if err != nil {
+			return nil, err
+		}
+		content := renderVaultNote(entry, nil)
+		path := filepath.Join(entry.Type, entry.ID+".md")
+		if note := byID[entry.ID]; note != nil {
+			path = note.Path
+			content = renderVaultNote(entry, note.Raw)
+			if !force && note.Err == nil && note.hash() != note.Front.Hash && note.hash() != entryHash(entry) {
+				detail := "edited in the vault; run 'dory import vault' first"
+				if entryHash(entry) != note.Front.Hash {
+					detail = "changed in both dory and the vault; --force keeps dory's version"
+				}
+				result.add(path, entry.ID, "conflict", detail)
+				continue
+			}
+		}
+		if old, err := os.ReadFile(filepath.Join(dir, path)); err == nil && string(old) == content {
+			result.add(path, entry.ID, "unchanged", "")
+			continue
+		}
+		if err := writeVaultNote(dir, path, content); err != nil {
+			return nil, err
+		}
+		result.add(path, entry.ID, "written", "")
+	}
+
+	for _, note := range notes {
+		if note.Front.ID == "" || live[note.Front.ID] || byID[note.Front.ID] != note {
+			continue
+		}
+		if note.Err != nil || note.hash() != note.Front.Hash {
+			result.add(note.Path, note.Front.ID, "conflict", "deleted in dory but edited in the vault")
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, note.Path)); err != nil {
+			return nil, err
+		}
+		result.add(note.Path, note.Front.ID, "removed", "deleted in dory")
+	}
+	return result, nil
+}
+
+func init() {
+	exportVaultCmd.Flags().StringP("tag", "T", "", "Only export items with this tag")
+	exportVaultCmd.Flags().String("filter", "", "Only export items matching a filter expression (see dory query)")
+	exportVaultCmd.Flags().Bool("force", false, "Overwrite notes edited in the vault")
+	exportVaultCmd.RegisterFlagCompletionFunc("tag", completeTags)
+	exportCmd.AddCommand(exportVaultCmd)
+}
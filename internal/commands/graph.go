@@ -0,0 +1,19 @@
+package commands
+
+import "github.com/spf13/cobra"
+
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Export and analyze the knowledge graph",
+	Long: `Work with the graph formed by item references.
+
+Examples:
+  dory graph export --format mermaid
+  dory graph export --format dot --center D-01JX... --depth 2 | dot -Tsvg > graph.svg
+  dory graph analyze
+  dory graph path L-01JX... D-01JY...`,
+}
+
+func init() {
+	RootCmd.AddCommand(graphCmd)
+}
@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sibellavia/dory/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var graphAnalyzeCmd = &cobra.Command{
+	Use:   "analyze",
+	Short: "Report orphans, broken refs, cycles, and hub items",
+	Long: `Analyze the reference graph.
+
+Reports:
+  orphans      items that neither reference nor are referenced by anything
+  broken refs  refs to items that were deleted or never existed
+  cycles       chains of refs that lead back to where they started
+  hubs         the most connected items, by degree and betweenness centrality
+
+Refs to session tasks and questions (T-1, Q-1) are not reported as broken.
+
+Examples:
+  dory graph analyze
+  dory graph analyze --hubs 20 --json`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		RequireStore()
+
+		hubs, _ := cmd.Flags().GetInt("hubs")
+
+		s := store.New(doryRoot)
+		defer s.Close()
+
+		result, err := s.AnalyzeGraph(hubs)
+		CheckError(err)
+
+		OutputResult(cmd, result, func() {
+			printGraphAnalysis(result)
+		})
+	},
+}
+
+func printGraphAnalysis(result *store.GraphAnalysis) {
+	fmt.Printf("Graph: %d items, %d refs\n\n", result.Items, result.Edges)
+
+	fmt.Printf("ORPHANS (%d)\n", len(result.Orphans))
+	fmt.Println(strings.Repeat("─", 50))
+	for _, item := range result.Orphans {
+		fmt.Printf("  %s [%s]: %s\n", item.ID, item.Type, truncateOneliner(item.Oneliner, 40))
+	}
+	fmt.Println()
+
+	fmt.Printf("BROKEN REFS (%d)\n", len(result.BrokenRefs))
+	fmt.Println(strings.Repeat("─", 50))
+	for _, broken := range result.BrokenRefs {
+		reason := "never existed"
+		if broken.Deleted {
+			reason = "deleted"
+		}
+		fmt.Printf("  %s -> %s (%s)\n", broken.From, broken.Ref, reason)
+	}
+	fmt.Println()
+
+	fmt.Printf("CYCLES (%d)\n", len(result.Cycles))
+	fmt.Println(strings.Repeat("─", 50))
+	for _, cycle := range result.Cycles {
+		fmt.Printf("  %s\n", strings.Join(cycle, " -> "))
+	}
+	fmt.Println()
+
+	fmt.Printf("HUBS (%d)\n", len(result.Hubs))
+	fmt.Println(strings.Repeat("─", 50))
+	for _, hub := range result.Hubs {
+		fmt.Printf("  %s in:%d out:%d centrality:%.3f  %s\n",
+			hub.ID, hub.InDegree, hub.OutDegree, hub.Centrality, truncateOneliner(hub.Oneliner, 30))
+	}
+}
+
+func init() {
+	graphAnalyzeCmd.Flags().Int("hubs", 10, "How many hub items to list")
+	graphCmd.AddCommand(graphAnalyzeCmd)
+}
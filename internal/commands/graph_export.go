@@ -0,0 +1,328 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/sibellavia/dory/internal/models"
+	"github.com/sibellavia/dory/internal/store"
+	"github.com/spf13/cobra"
+)
+
+// graphFormats lists the supported `dory graph export` formats.
+var graphFormats = []string{"dot", "mermaid", "graphml", "cytoscape-json"}
+
+// graphLabelWidth caps oneliners in node labels.
+const graphLabelWidth = 60
+
+// nodeStyle is the rendering of one node, derived from its type and severity.
+type nodeStyle struct {
+	Fill   string
+	Stroke string
+	Width  int
+	Dashed bool
+}
+
+var graphExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the graph as DOT, Mermaid, GraphML, or Cytoscape JSON",
+	Long: `Export the reference graph of the whole store, or of the neighbourhood of
+one item with --center and --depth.
+
+Nodes are filled by type (lesson, decision, convention) and outlined by
+severity: critical is thick red, high is orange, low is dashed.
+
+Formats:
+  dot             Graphviz; render with: dot -Tsvg graph.dot > graph.svg
+  mermaid         Mermaid flowchart; paste into Markdown inside a mermaid block
+  graphml         GraphML XML for yEd, Gephi, or networkx
+  cytoscape-json  Cytoscape.js elements JSON
+
+Examples:
+  dory graph export --format mermaid
+  dory graph export --format dot --center D-01JX... --depth 2
+  dory graph export --format graphml --filter 'tag:auth' -o auth.graphml`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		RequireStore()
+
+		format, _ := cmd.Flags().GetString("format")
+		center, _ := cmd.Flags().GetString("center")
+		depth, _ := cmd.Flags().GetInt("depth")
+		out, _ := cmd.Flags().GetString("output")
+		filter := resolveFilter(cmd)
+
+		if center != "" {
+			center = resolveItemID(center)
+		}
+
+		s := store.New(doryRoot)
+		defer s.Close()
+
+		graph, err := buildGraphData(s, center, depth, filter)
+		CheckError(err)
+		if center == "" {
+			graph.Depth = 0
+		}
+
+		output, err := renderGraph(graph, format)
+		CheckError(err)
+
+		if out == "" {
+			fmt.Print(output)
+			return
+		}
+		CheckError(os.WriteFile(out, []byte(output), 0644))
+		fmt.Fprintf(os.Stderr, "Wrote %d nodes and %d edges to %s\n", len(graph.Nodes), len(graph.Edges), out)
+	},
+}
+
+// renderGraph serializes a graph in one of graphFormats.
+func renderGraph(graph *GraphResult, format string) (string, error) {
+	switch format {
+	case "dot":
+		return renderDOT(graph), nil
+	case "mermaid":
+		return renderMermaid(graph), nil
+	case "graphml":
+		return renderGraphML(graph)
+	case "cytoscape-json":
+		return renderCytoscape(graph)
+	default:
+		return "", fmt.Errorf("invalid --format %q (expected: %s)", format, strings.Join(graphFormats, ", "))
+	}
+}
+
+func styleFor(node GraphNode) nodeStyle {
+	style := nodeStyle{Fill: "#f3f4f6", Stroke: "#6b7280", Width: 1}
+	switch node.Type {
+	case "lesson":
+		style.Fill = "#fef3c7"
+	case "decision":
+		style.Fill = "#dbeafe"
+	case "convention":
+		style.Fill = "#dcfce7"
+	}
+	switch node.Severity {
+	case models.SeverityCritical:
+		style.Stroke, style.Width = "#dc2626", 3
+	case models.SeverityHigh:
+		style.Stroke, style.Width = "#ea580c", 2
+	case models.SeverityLow:
+		style.Dashed = true
+	}
+	return style
+}
+
+func renderDOT(graph *GraphResult) string {
+	quote := func(s string) string {
+		s = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+		return `"` + s + `"`
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("digraph dory {\n")
+	buf.WriteString("  rankdir=LR;\n")
+	buf.WriteString("  node [shape=box, style=\"rounded,filled\", fontname=\"Helvetica\", fontsize=10];\n")
+	for _, node := range graph.Nodes {
+		style := styleFor(node)
+		shape := "rounded,filled"
+		if style.Dashed {
+			shape += ",dashed"
+		}
+		label := node.ID + "\n" + truncateOneliner(node.Oneliner, graphLabelWidth)
+		fmt.Fprintf(&buf, "  %s [label=%s, fillcolor=%s, color=%s, penwidth=%d, style=%s",
+			quote(node.ID), quote(label), quote(style.Fill), quote(style.Stroke), style.Width, quote(shape))
+		if node.ID == graph.Center {
+			buf.WriteString(", peripheries=2")
+		}
+		buf.WriteString("];\n")
+	}
+	for _, edge := range graph.Edges {
+		fmt.Fprintf(&buf, "  %s -> %s;\n", quote(edge.From), quote(edge.To))
+	}
+	buf.WriteString("}\n")
+	return buf.String()
+}
+
+func renderMermaid(graph *GraphResult) string {
+	// Mermaid node IDs cannot hold every character of an item ID, so nodes
+	// get positional names and the item ID goes in the label.
+	names := make(map[string]string, len(graph.Nodes))
+	escape := func(s string) string {
+		return strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;").Replace(s)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("graph LR\n")
+	classes := make(map[string]nodeStyle)
+	for i, node := range graph.Nodes {
+		name := fmt.Sprintf("n%d", i)
+		names[node.ID] = name
+		label := escape(node.ID) + "<br/>" + escape(truncateOneliner(node.Oneliner, graphLabelWidth))
+		class := mermaidClass(node)
+		classes[class] = styleFor(node)
+		fmt.Fprintf(&buf, "  %s[\"%s\"]:::%s\n", name, label, class)
+	}
+	for _, edge := range graph.Edges {
+		fmt.Fprintf(&buf, "  %s --> %s\n", names[edge.From], names[edge.To])
+	}
+
+	classNames := make([]string, 0, len(classes))
+	for class := range classes {
+		classNames = append(classNames, class)
+	}
+	sort.Strings(classNames)
+	for _, class := range classNames {
+		style := classes[class]
+		fmt.Fprintf(&buf, "  classDef %s fill:%s,stroke:%s,stroke-width:%dpx", class, style.Fill, style.Stroke, style.Width)
+		if style.Dashed {
+			buf.WriteString(",stroke-dasharray:4 3")
+		}
+		buf.WriteString("\n")
+	}
+	return buf.String()
+}
+
+// mermaidClass names the class for a node's type and severity,
+// e.g. "lesson_critical" or "decision".
+func mermaidClass(node GraphNode) string {
+	class := node.Type
+	if class == "" {
+		class = "item"
+	}
+	class = strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, class)
+	if node.Severity != "" {
+		class += "_" + string(node.Severity)
+	}
+	return class
+}
+
+func renderGraphML(graph *GraphResult) (string, error) {
+	type data struct {
+		Key   string `xml:"key,attr"`
+		Value string `xml:",chardata"`
+	}
+	type node struct {
+		ID   string `xml:"id,attr"`
+		Data []data `xml:"data"`
+	}
+	type edge struct {
+		ID     string `xml:"id,attr"`
+		Source string `xml:"source,attr"`
+		Target string `xml:"target,attr"`
+	}
+	type key struct {
+		ID       string `xml:"id,attr"`
+		For      string `xml:"for,attr"`
+		Name     string `xml:"attr.name,attr"`
+		AttrType string `xml:"attr.type,attr"`
+	}
+	type graphElem struct {
+		ID          string `xml:"id,attr"`
+		EdgeDefault string `xml:"edgedefault,attr"`
+		Nodes       []node `xml:"node"`
+		Edges       []edge `xml:"edge"`
+	}
+	type graphML struct {
+		XMLName xml.Name  `xml:"graphml"`
+		Xmlns   string    `xml:"xmlns,attr"`
+		Keys    []key     `xml:"key"`
+		Graph   graphElem `xml:"graph"`
+	}
+
+	doc := graphML{
+		Xmlns: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []key{
+			{ID: "type", For: "node", Name: "type", AttrType: "string"},
+			{ID: "oneliner", For: "node", Name: "oneliner", AttrType: "string"},
+			{ID: "severity", For: "node", Name: "severity", AttrType: "string"},
+			{ID: "fill", For: "node", Name: "fill", AttrType: "string"},
+			{ID: "stroke", For: "node", Name: "stroke", AttrType: "string"},
+		},
+		Graph: graphElem{ID: "dory", EdgeDefault: "directed"},
+	}
+	for _, n := range graph.Nodes {
+		style := styleFor(n)
+		elem := node{ID: n.ID, Data: []data{
+			{Key: "type", Value: n.Type},
+			{Key: "oneliner", Value: n.Oneliner},
+		}}
+		if n.Severity != "" {
+			elem.Data = append(elem.Data, data{Key: "severity", Value: string(n.Severity)})
+		}
+		elem.Data = append(elem.Data, data{Key: "fill", Value: style.Fill}, data{Key: "stroke", Value: style.Stroke})
+		doc.Graph.Nodes = append(doc.Graph.Nodes, elem)
+	}
+	for _, e := range graph.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, edge{ID: e.From + "->" + e.To, Source: e.From, Target: e.To})
+	}
+
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return xml.Header + string(out) + "\n", nil
+}
+
+func renderCytoscape(graph *GraphResult) (string, error) {
+	type element struct {
+		Data    map[string]string `json:"data"`
+		Classes string            `json:"classes,omitempty"`
+	}
+	elements := struct {
+		Nodes []element `json:"nodes"`
+		Edges []element `json:"edges"`
+	}{Nodes: make([]element, 0, len(graph.Nodes)), Edges: make([]element, 0, len(graph.Edges))}
+
+	for _, node := range graph.Nodes {
+		style := styleFor(node)
+		data := map[string]string{
+			"id":       node.ID,
+			"label":    node.ID + "\n" + truncateOneliner(node.Oneliner, graphLabelWidth),
+			"type":     node.Type,
+			"oneliner": node.Oneliner,
+			"fill":     style.Fill,
+			"stroke":   style.Stroke,
+		}
+		classes := node.Type
+		if node.Severity != "" {
+			data["severity"] = string(node.Severity)
+			classes += " " + string(node.Severity)
+		}
+		elements.Nodes = append(elements.Nodes, element{Data: data, Classes: classes})
+	}
+	for _, edge := range graph.Edges {
+		elements.Edges = append(elements.Edges, element{Data: map[string]string{
+			"id":     edge.From + "->" + edge.To,
+			"source": edge.From,
+			"target": edge.To,
+		}})
+	}
+
+	out, err := json.MarshalIndent(map[string]interface{}{"elements": elements}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(out) + "\n", nil
+}
+
+func init() {
+	// --format shadows the global output format flag on this command.
+	graphExportCmd.Flags().String("format", "mermaid", "Output format: "+strings.Join(graphFormats, ", "))
+	graphExportCmd.Flags().String("center", "", "Only export the neighbourhood of this item")
+	graphExportCmd.Flags().Int("depth", 1, "With --center, how many hops to include")
+	graphExportCmd.Flags().String("filter", "", "Only include nodes matching a filter expression (see dory query)")
+	graphExportCmd.Flags().StringP("output", "o", "", "Write to a file instead of stdout")
+	graphCmd.AddCommand(graphExportCmd)
+}
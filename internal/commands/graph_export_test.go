@@ -0,0 +1,88 @@
+package commands
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/sibellavia/dory/internal/models"
+)
+
+func sampleGraph() *GraphResult {
+	return &GraphResult{
+		Nodes: []GraphNode{
+			{ID: "D-1", Type: "decision", Oneliner: `Use "OIDC" <now>`},
+			{ID: "L-1", Type: "lesson", Oneliner: "Tokens expire", Severity: models.SeverityCritical},
+		},
+		Edges: []GraphEdge{{From: "L-1", To: "D-1"}},
+	}
+}
+
+func TestRenderGraphFormats(t *testing.T) {
+	graph := sampleGraph()
+
+	dot, err := renderGraph(graph, "dot")
+	if err != nil {
+		t.Fatalf("dot: %v", err)
+	}
+	for _, want := range []string{`label="D-1\nUse \"OIDC\" <now>"`, `color="#dc2626", penwidth=3`, `"L-1" -> "D-1";`} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("dot output missing %s:\n%s", want, dot)
+		}
+	}
+
+	mermaid, err := renderGraph(graph, "mermaid")
+	if err != nil {
+		t.Fatalf("mermaid: %v", err)
+	}
+	for _, want := range []string{`n0["D-1<br/>Use #quot;OIDC#quot; #lt;now#gt;"]:::decision`, "n1 --> n0", "classDef lesson_critical"} {
+		if !strings.Contains(mermaid, want) {
+			t.Errorf("mermaid output missing %s:\n%s", want, mermaid)
+		}
+	}
+
+	graphml, err := renderGraph(graph, "graphml")
+	if err != nil {
+		t.Fatalf("graphml: %v", err)
+	}
+	var parsed struct {
+		Nodes []struct {
+			ID string `xml:"id,attr"`
+		} `xml:"graph>node"`
+		Edges []struct {
+			Source string `xml:"source,attr"`
+		} `xml:"graph>edge"`
+	}
+	if err := xml.Unmarshal([]byte(graphml), &parsed); err != nil {
+		t.Fatalf("graphml is not valid XML: %v", err)
+	}
+	if len(parsed.Nodes) != 2 || len(parsed.Edges) != 1 || parsed.Edges[0].Source != "L-1" {
+		t.Fatalf("unexpected graphml content: %+v", parsed)
+	}
+
+	cyto, err := renderGraph(graph, "cytoscape-json")
+	if err != nil {
+		t.Fatalf("cytoscape-json: %v", err)
+	}
+	var elements struct {
+		Elements struct {
+			Nodes []struct {
+				Classes string `json:"classes"`
+			} `json:"nodes"`
+			Edges []struct {
+				Data map[string]string `json:"data"`
+			} `json:"edges"`
+		} `json:"elements"`
+	}
+	if err := json.Unmarshal([]byte(cyto), &elements); err != nil {
+		t.Fatalf("cytoscape-json is not valid JSON: %v", err)
+	}
+	if elements.Elements.Nodes[1].Classes != "lesson critical" || elements.Elements.Edges[0].Data["target"] != "D-1" {
+		t.Fatalf("unexpected cytoscape content: %s", cyto)
+	}
+
+	if _, err := renderGraph(graph, "png"); err == nil {
+		t.Fatal("expected unknown format to fail")
+	}
+}
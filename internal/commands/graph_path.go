@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/sibellavia/dory/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var graphPathCmd = &cobra.Command{
+	Use:   "path <from> <to>",
+	Short: "Show the shortest ref chain between two items",
+	Long: `Print the shortest chain of refs connecting two items.
+
+Refs are followed in both directions; use --directed to follow them only
+from the referencing item to the referenced one.
+
+Examples:
+  dory graph path L-01JX... D-01JY...
+  dory graph path L-42 D-7 --directed`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		RequireStore()
+
+		directed, _ := cmd.Flags().GetBool("directed")
+
+		s := store.New(doryRoot)
+		defer s.Close()
+
+		steps, err := s.Path(args[0], args[1], directed)
+		CheckError(err)
+
+		result := map[string]interface{}{
+			"hops": len(steps) - 1,
+			"path": steps,
+		}
+		OutputResult(cmd, result, func() {
+			for i, step := range steps {
+				switch step.Via {
+				case "refs":
+					fmt.Println("   │ refs")
+				case "referenced_by":
+					fmt.Println("   │ referenced by")
+				}
+				fmt.Printf("%2d %s [%s]: %s\n", i, step.ID, step.Type, truncateOneliner(step.Oneliner, 40))
+			}
+		})
+	},
+}
+
+func init() {
+	graphPathCmd.Flags().Bool("directed", false, "Only follow refs from referencing to referenced item")
+	graphPathCmd.ValidArgsFunction = completeIDs(2)
+	graphCmd.AddCommand(graphPathCmd)
+}
@@ -0,0 +1,131 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/sibellavia/dory/internal/harvest"
+	"github.com/sibellavia/dory/internal/models"
+	"github.com/sibellavia/dory/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var harvestCmd = &cobra.Command{
+	Use:   "harvest <transcript.jsonl>...",
+	Short: "Propose lessons from agent session transcripts",
+	Long: `Read agent session transcripts (Claude Code or Codex JSONL) and propose
+draft lessons for review. Drafts are not written to the log; review them with
+'dory drafts list', then 'dory drafts accept' or 'dory drafts reject'.
+
+Signals:
+  retry   The same command failed at least twice, then passed
+  note    A message line starting with "Lesson:", "Note:", "TIL:", or "Gotcha:"
+  revert  An edit was undone, by a reverse edit or git checkout/restore
+
+Each draft's body follows the lesson template (Symptom, Root Cause, Fix),
+filled from the turns around the signal. Harvesting a transcript again only
+queues drafts that are new.
+
+Examples:
+  dory harvest ~/.claude/projects/<project>/<session>.jsonl
+  dory harvest ~/.codex/sessions/2026/10/18/*.jsonl --tag build
+  dory harvest session.jsonl --dry-run   # Show drafts without queueing them`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		RequireStore()
+		tag, _ := cmd.Flags().GetString("tag")
+		severity, _ := cmd.Flags().GetString("severity")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		CheckError(validateSeverityFlag(models.Severity(severity)))
+
+		var drafts []store.Draft
+		for _, path := range args {
+			found, err := harvestTranscript(path, tag, severity)
+			CheckError(err)
+			drafts = append(drafts, found...)
+		}
+
+		queued := drafts
+		if !dryRun {
+			s := store.New(doryRoot)
+			defer s.Close()
+			var err error
+			queued, err = s.AddDrafts(drafts)
+			CheckError(err)
+		}
+		if queued == nil {
+			queued = make([]store.Draft, 0)
+		}
+
+		result := map[string]interface{}{
+			"found":  len(drafts),
+			"drafts": queued,
+		}
+		if dryRun {
+			result["dry_run"] = true
+		}
+		OutputResult(cmd, result, func() {
+			if len(drafts) == 0 {
+				fmt.Println("No lessons found")
+				return
+			}
+			for _, d := range queued {
+				id := d.ID
+				if dryRun {
+					id = "-"
+				}
+				fmt.Printf("%-6s %-7s %s\n", id, d.Signal, truncateOneliner(d.Oneliner, 80))
+			}
+			switch {
+			case dryRun:
+				fmt.Printf("\n%d drafts found (dry run, nothing queued)\n", len(drafts))
+			case len(queued) == 0:
+				fmt.Printf("No new drafts (%d already queued)\n", len(drafts))
+			case len(queued) < len(drafts):
+				fmt.Printf("\nQueued %d drafts (%d already queued); review with 'dory drafts list'\n", len(queued), len(drafts)-len(queued))
+			default:
+				fmt.Printf("\nQueued %d drafts; review with 'dory drafts list'\n", len(queued))
+			}
+		})
+	},
+}
+
+// harvestTranscript parses a transcript and returns its drafts, with the
+// tag and severity they would be accepted with.
+func harvestTranscript(path, tag, severity string) ([]store.Draft, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	events, err := harvest.Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	source, err := filepath.Abs(path)
+	if err != nil {
+		source = path
+	}
+	var drafts []store.Draft
+	for _, d := range harvest.Find(events) {
+		drafts = append(drafts, store.Draft{
+			Signal:   d.Signal,
+			Oneliner: d.Oneliner,
+			Topic:    tag,
+			Severity: severity,
+			Source:   source,
+			Line:     d.Line,
+			Body:     d.Body,
+		})
+	}
+	return drafts, nil
+}
+
+func init() {
+	harvestCmd.Flags().StringP("tag", "T", "", "Tag for the drafts (can also be set on accept)")
+	harvestCmd.Flags().StringP("severity", "S", "normal", "Severity for the drafts: critical, high, normal, low")
+	harvestCmd.Flags().Bool("dry-run", false, "Show drafts without queueing them")
+	harvestCmd.RegisterFlagCompletionFunc("tag", completeTags)
+	RootCmd.AddCommand(harvestCmd)
+}
@@ -0,0 +1,26 @@
+package commands
+
+import (
+	"github.com/sibellavia/dory/internal/store"
+	"github.com/spf13/cobra"
+)
+
+// resolveItemID expands an alias (L-42) or unique ID prefix against the
+// store that receives writes, exiting with the candidates when ambiguous.
+func resolveItemID(ref string) string {
+	s := store.New(doryRoot)
+	defer s.Close()
+	id, err := s.ResolveID(ref)
+	CheckError(err)
+	return id
+}
+
+// addRefPolicyFlag registers --allow-missing-refs on a command that writes refs.
+func addRefPolicyFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("allow-missing-refs", false, "Keep refs that match no existing item, task, or question")
+}
+
+// applyRefPolicy applies --allow-missing-refs to the store receiving writes.
+func applyRefPolicy(cmd *cobra.Command, s *store.Store) {
+	s.AllowMissingRefs, _ = cmd.Flags().GetBool("allow-missing-refs")
+}
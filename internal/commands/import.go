@@ -28,7 +28,10 @@ Examples:
   dory import docs/api-details.md --type lesson --topic api
   dory import docs/why-redis.md --type decision --topic caching
   dory import notes.md  # uses frontmatter if present
-  dory import lessons.md --type lesson --topic infra --split`,
+  dory import lessons.md --type lesson --topic infra --split
+
+Items that look like duplicates of existing ones are reported with a warning;
+--no-duplicates refuses the whole import instead.`,
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		RequireStore()
@@ -87,32 +90,41 @@ Examples:
 
 		s := store.New(doryRoot)
 		defer s.Close()
+		applyRefPolicy(cmd, s)
 
-		imported := make([]map[string]string, 0)
+		var items []numberedItem
 		if split {
-			items, err := splitNumberedItems(body)
+			items, err = splitNumberedItems(body)
 			CheckError(err)
 			if len(items) == 0 {
 				CheckError(fmt.Errorf("no numbered items found (expected patterns like '1) Title' or '1. Title')"))
 			}
-			for _, item := range items {
-				id, err := importItem(s, itemType, item.title, item.body, topic, domain, severity, refs)
-				CheckError(err)
-				imported = append(imported, map[string]string{
-					"id":       id,
-					"type":     itemType,
-					"oneliner": item.title,
-				})
-			}
 		} else {
-			oneliner := extractOneliner(body, filePath)
-			id, err := importItem(s, itemType, oneliner, body, topic, domain, severity, refs)
+			items = []numberedItem{{title: extractOneliner(body, filePath), body: body}}
+		}
+
+		// Check every item first so --no-duplicates refuses before anything is
+		// written; each item is also compared with the batch items before it.
+		duplicates := make([][]store.SimilarItem, len(items))
+		batch := make([]store.PendingItem, 0, len(items))
+		for i, item := range items {
+			duplicates[i] = checkDuplicates(cmd, s, item.title, item.body, batch...)
+			batch = append(batch, pendingItem(i, itemType, item))
+		}
+
+		imported := make([]map[string]interface{}, 0)
+		for i, item := range items {
+			id, err := importItem(s, itemType, item.title, item.body, topic, domain, severity, refs)
 			CheckError(err)
-			imported = append(imported, map[string]string{
+			entry := map[string]interface{}{
 				"id":       id,
 				"type":     itemType,
-				"oneliner": oneliner,
-			})
+				"oneliner": item.title,
+			}
+			if len(duplicates[i]) > 0 {
+				entry["similar_to"] = duplicateIDs(duplicates[i])
+			}
+			imported = append(imported, entry)
 		}
 
 		result := map[string]interface{}{
@@ -140,7 +152,11 @@ func init() {
 	importCmd.Flags().StringP("domain", "d", "", "Alias for --tag (deprecated)")
 	importCmd.Flags().StringP("severity", "S", "", "Severity: critical, high, normal, low")
 	importCmd.Flags().StringSliceP("refs", "R", nil, "References to other items (comma-separated)")
+	addRefPolicyFlag(importCmd)
 	importCmd.Flags().Bool("split", false, "Split numbered items into separate entries")
+	addDuplicateFlags(importCmd)
+	importCmd.RegisterFlagCompletionFunc("tag", completeTags)
+	importCmd.RegisterFlagCompletionFunc("refs", completeRefs)
 	importCmd.Flags().MarkHidden("topic")
 	importCmd.Flags().MarkHidden("domain")
 	RootCmd.AddCommand(importCmd)
@@ -176,6 +192,17 @@ type numberedItem struct {
 	body  string
 }
 
+// pendingItem describes the i-th item of an import batch for duplicate checks
+// against the items that follow it.
+func pendingItem(i int, itemType string, item numberedItem) store.PendingItem {
+	return store.PendingItem{
+		Key:      fmt.Sprintf("item %d", i+1),
+		Type:     itemType,
+		Oneliner: item.title,
+		Body:     item.body,
+	}
+}
+
 var numberedItemPattern = regexp.MustCompile(`^(\d+)[)\.]\s+(.+)$`)
 
 func splitNumberedItems(content string) ([]numberedItem, error) {
@@ -217,25 +244,31 @@ func splitNumberedItems(content string) ([]numberedItem, error) {
 func parseFrontmatter(content string) (map[string]interface{}, string, error) {
 	frontmatter := make(map[string]interface{})
 
-	if !strings.HasPrefix(content, "---\n") {
+	yamlContent, body, ok := splitFrontmatter(content)
+	if !ok {
 		return frontmatter, content, nil
 	}
 
-	rest := content[4:]
-	endIdx := strings.Index(rest, "\n---")
-	if endIdx == -1 {
-		return frontmatter, content, nil
-	}
-
-	yamlContent := rest[:endIdx]
-	body := strings.TrimPrefix(rest[endIdx+4:], "\n")
-
 	if err := yaml.Unmarshal([]byte(yamlContent), &frontmatter); err != nil {
 		return nil, "", fmt.Errorf("invalid frontmatter: %w", err)
 	}
 	return frontmatter, body, nil
 }
 
+// splitFrontmatter splits a leading "---" YAML block from the rest of content.
+func splitFrontmatter(content string) (string, string, bool) {
+	if !strings.HasPrefix(content, "---\n") {
+		return "", content, false
+	}
+
+	rest := content[4:]
+	endIdx := strings.Index(rest, "\n---")
+	if endIdx == -1 {
+		return "", content, false
+	}
+	return rest[:endIdx], strings.TrimPrefix(rest[endIdx+4:], "\n"), true
+}
+
 func extractOneliner(body, filePath string) string {
 	scanner := bufio.NewScanner(strings.NewReader(body))
 	for scanner.Scan() {
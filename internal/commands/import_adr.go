@@ -0,0 +1,191 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sibellavia/dory/internal/doryfile"
+	"github.com/sibellavia/dory/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var importADRCmd = &cobra.Command{
+	Use:   "adr <dir>",
+	Short: "Import an ADR directory (MADR or Nygard) as decisions",
+	Long: `Import Architecture Decision Records from a directory of numbered files
+(docs/adr/0001-title.md) as decisions. MADR (YAML frontmatter or "* Status:"
+bullets) and Nygard/adr-tools ("Date:" line and "## Status") files are both
+understood.
+
+Each ADR becomes a decision with the ADR title as its oneliner, its date as
+the creation date, and its sections (including Status) as the body. ADRs it
+supersedes, amends, or is superseded by become refs.
+
+Importing again is safe: an ADR updates the decision it was exported from
+(its dory-id) or the decision with the same title instead of adding another.
+
+Examples:
+  dory import adr docs/adr
+  dory import adr docs/decisions --tag architecture`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		RequireStore()
+		tag, _ := cmd.Flags().GetString("tag")
+
+		records, err := readADRDir(args[0])
+		CheckError(err)
+		if len(records) == 0 {
+			CheckError(fmt.Errorf("no ADR files (NNNN-title.md) found in %s", args[0]))
+		}
+
+		s := store.New(doryRoot)
+		defer s.Close()
+		result, err := importADRs(s, records, tag)
+		CheckError(err)
+		result.Dir = args[0]
+
+		OutputResult(cmd, result, func() {
+			for _, file := range result.Files {
+				fmt.Printf("%-9s %s  %s\n", file.Status, file.ID, file.File)
+			}
+			fmt.Printf("\n%s\n", result.summary())
+		})
+	},
+}
+
+// ADRFileResult is what happened to one ADR file on import or export.
+type ADRFileResult struct {
+	File   string `json:"file" yaml:"file"`
+	ID     string `json:"id" yaml:"id"`
+	Title  string `json:"title" yaml:"title"`
+	Status string `json:"status" yaml:"status"`
+}
+
+// ADRResult summarizes an ADR import or export.
+type ADRResult struct {
+	Dir   string          `json:"dir" yaml:"dir"`
+	Style string          `json:"style,omitempty" yaml:"style,omitempty"`
+	Files []ADRFileResult `json:"files" yaml:"files"`
+}
+
+func (r *ADRResult) summary() string {
+	counts := make(map[string]int)
+	var order []string
+	for _, file := range r.Files {
+		if counts[file.Status] == 0 {
+			order = append(order, file.Status)
+		}
+		counts[file.Status]++
+	}
+	parts := make([]string, 0, len(order))
+	for _, status := range order {
+		parts = append(parts, fmt.Sprintf("%d %s", counts[status], status))
+	}
+	return fmt.Sprintf("%d ADRs: %s", len(r.Files), strings.Join(parts, ", "))
+}
+
+// importADRs creates or updates one decision per ADR, then adds refs for
+// supersede links once every ADR has a decision to point at.
+func importADRs(s *store.Store, records []*adrRecord, tag string) (*ADRResult, error) {
+	decisions, err := s.List(store.ListFilter{Type: "decision"})
+	if err != nil {
+		return nil, err
+	}
+	live := make(map[string]bool, len(decisions))
+	byTitle := make(map[string]string, len(decisions))
+	for _, item := range decisions {
+		live[item.ID] = true
+		byTitle[item.Oneliner] = item.ID
+	}
+
+	result := &ADRResult{}
+	byNumber := make(map[int]string, len(records))
+	for _, record := range records {
+		id := record.DoryID
+		if !live[id] {
+			id = byTitle[record.Title]
+		}
+		status := "unchanged"
+		if id == "" {
+			id, err = s.CreateEntry(&doryfile.Entry{
+				Type:     "decision",
+				Topic:    tag,
+				Oneliner: record.Title,
+				Created:  record.Date,
+				Body:     record.Body,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", record.File, err)
+			}
+			live[id] = true
+			byTitle[record.Title] = id
+			status = "created"
+		} else {
+			entry, err := s.GetEntry(id)
+			if err != nil {
+				return nil, err
+			}
+			changed := false
+			if entry.Oneliner != record.Title {
+				entry.Oneliner, changed = record.Title, true
+			}
+			if strings.TrimRight(entry.Body, " \t\n") != strings.TrimRight(record.Body, " \t\n") {
+				entry.Body, changed = record.Body, true
+			}
+			if !record.Date.IsZero() && !sameDay(entry.Created, record.Date) {
+				entry.Created, changed = record.Date, true
+			}
+			if changed {
+				if err := s.UpdateEntry(entry); err != nil {
+					return nil, fmt.Errorf("%s: %w", record.File, err)
+				}
+				status = "updated"
+			}
+		}
+		if record.Number > 0 {
+			byNumber[record.Number] = id
+		}
+		result.Files = append(result.Files, ADRFileResult{File: record.File, ID: id, Title: record.Title, Status: status})
+	}
+
+	for i, record := range records {
+		id := result.Files[i].ID
+		entry, err := s.GetEntry(id)
+		if err != nil {
+			return nil, err
+		}
+		has := make(map[string]bool, len(entry.Refs))
+		for _, ref := range entry.Refs {
+			has[ref] = true
+		}
+		added := false
+		for _, n := range record.Links {
+			if ref, ok := byNumber[n]; ok && ref != id && !has[ref] {
+				entry.Refs = append(entry.Refs, ref)
+				has[ref] = true
+				added = true
+			}
+		}
+		if !added {
+			continue
+		}
+		if err := s.UpdateEntry(entry); err != nil {
+			return nil, fmt.Errorf("%s: %w", record.File, err)
+		}
+		if result.Files[i].Status == "unchanged" {
+			result.Files[i].Status = "updated"
+		}
+	}
+	return result, nil
+}
+
+func sameDay(a, b time.Time) bool {
+	return a.UTC().Format("2006-01-02") == b.UTC().Format("2006-01-02")
+}
+
+func init() {
+	importADRCmd.Flags().StringP("tag", "T", "adr", "Tag for decisions created from ADRs")
+	importADRCmd.RegisterFlagCompletionFunc("tag", completeTags)
+	importCmd.AddCommand(importADRCmd)
+}
@@ -1,8 +1,11 @@
 package commands
 
 import (
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/sibellavia/dory/internal/store"
 )
 
 func TestParseFrontmatterInvalidYAML(t *testing.T) {
@@ -86,3 +89,33 @@ func TestImportItemValidationErrors(t *testing.T) {
 		t.Fatal("expected unknown type to fail")
 	}
 }
+
+func TestSplitItemsCheckedAgainstBatch(t *testing.T) {
+	s := store.New(filepath.Join(t.TempDir(), ".dory"))
+	defer s.Close()
+	if err := s.Init("project", ""); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	items, err := splitNumberedItems("1) Connection pool exhausts under load\n2) Rotate API keys monthly\n3) DB connection pool gets exhausted under heavy load\n")
+	if err != nil {
+		t.Fatalf("split: %v", err)
+	}
+
+	var batch []store.PendingItem
+	var found [][]store.SimilarItem
+	for i, item := range items {
+		duplicates, err := s.FindDuplicates(item.title, item.body, store.DefaultDuplicateThreshold, batch...)
+		if err != nil {
+			t.Fatalf("find duplicates: %v", err)
+		}
+		found = append(found, duplicates)
+		batch = append(batch, pendingItem(i, "lesson", item))
+	}
+
+	if len(found[0]) != 0 || len(found[1]) != 0 {
+		t.Fatalf("expected no duplicates for the first two items, got %+v", found[:2])
+	}
+	if len(found[2]) != 1 || found[2][0].ID != "item 1" || found[2][0].Oneliner != items[0].title {
+		t.Fatalf("expected item 3 to duplicate item 1, got %+v", found[2])
+	}
+}
@@ -0,0 +1,183 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sibellavia/dory/internal/doryfile"
+	"github.com/sibellavia/dory/internal/models"
+	"github.com/sibellavia/dory/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var importVaultCmd = &cobra.Command{
+	Use:   "vault <dir>",
+	Short: "Apply edits made in a vault written by 'dory export vault'",
+	Long: `Read the notes of a vault written by 'dory export vault' and append update
+events for the notes edited since the last sync. Edits are detected with the
+content hash in each note's frontmatter, so untouched notes write nothing.
+
+A note is reported, not applied, when:
+  conflict  the item also changed in dory since the last sync
+  stale     only dory changed (run 'dory export vault' to refresh the note)
+  missing   its id is not in dory (deleted, or from another store)
+  invalid   its frontmatter cannot be applied (e.g. a changed type)
+
+--force applies conflicting notes anyway, keeping the vault's version;
+'dory export vault --force' keeps dory's version instead.
+
+New notes with a type, a tag, and no id are added as items, and their id is
+written back into the note. Deleting a note does not delete the item.
+
+Examples:
+  dory import vault ~/notes/project
+  dory import vault vault/ --force   # Vault wins on conflicts`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		RequireStore()
+		force, _ := cmd.Flags().GetBool("force")
+
+		s := store.New(doryRoot)
+		defer s.Close()
+		result, err := importVault(s, args[0], force)
+		CheckError(err)
+		OutputResult(cmd, result, result.print)
+	},
+}
+
+// importVault applies vault edits: notes whose content no longer matches
+// their recorded hash become update events, and new notes with a type but
+// no id become items. A note is a conflict when dory changed the item too,
+// unless force is set.
+func importVault(s *store.Store, dir string, force bool) (*VaultResult, error) {
+	notes, err := readVault(dir)
+	if err != nil {
+		return nil, err
+	}
+	result := &VaultResult{Dir: dir}
+	seen := make(map[string]string)
+	for _, note := range notes {
+		id := note.Front.ID
+		if note.Err != nil {
+			result.add(note.Path, id, "invalid", note.Err.Error())
+			continue
+		}
+		if id == "" {
+			created, err := createFromVault(s, dir, note)
+			if err != nil {
+				result.add(note.Path, "", "invalid", err.Error())
+				continue
+			}
+			result.add(note.Path, created, "created", "")
+			continue
+		}
+		if first, dup := seen[id]; dup {
+			result.add(note.Path, id, "conflict", "same id as "+first)
+			continue
+		}
+		seen[id] = note.Path
+
+		entry, err := s.GetEntry(id)
+		if err != nil {
+			result.add(note.Path, id, "missing", "not in dory; deleted or from another store")
+			continue
+		}
+		noteHash, storeHash := note.hash(), entryHash(entry)
+		switch {
+		case noteHash == storeHash:
+			if note.Front.Hash != storeHash {
+				// Both sides made the same edit; record the new hash.
+				if err := writeVaultNote(dir, note.Path, renderVaultNote(entry, note.Raw)); err != nil {
+					return nil, err
+				}
+			}
+			result.add(note.Path, id, "unchanged", "")
+		case noteHash == note.Front.Hash:
+			result.add(note.Path, id, "stale", "changed in dory; run 'dory export vault'")
+		case storeHash != note.Front.Hash && !force:
+			result.add(note.Path, id, "conflict", "changed in both dory and the vault")
+		default:
+			if err := updateFromVault(s, entry, note); err != nil {
+				result.add(note.Path, id, "invalid", err.Error())
+				continue
+			}
+			entry, err = s.GetEntry(id)
+			if err != nil {
+				return nil, err
+			}
+			if err := writeVaultNote(dir, note.Path, renderVaultNote(entry, note.Raw)); err != nil {
+				return nil, err
+			}
+			result.add(note.Path, id, "updated", "")
+		}
+	}
+	return result, nil
+}
+
+// updateFromVault appends an update event with the note's content.
+func updateFromVault(s *store.Store, entry *doryfile.Entry, note *vaultNote) error {
+	if note.Front.Type != entry.Type {
+		return fmt.Errorf("type cannot change from %s to %s", entry.Type, note.Front.Type)
+	}
+	if err := checkVaultNote(note); err != nil {
+		return err
+	}
+	if tag := note.tag(); tag != entryTag(entry) {
+		setEntryTag(entry, tag)
+	}
+	entry.Oneliner = strings.TrimSpace(note.Front.Oneliner)
+	entry.Severity = note.Front.Severity
+	entry.Pinned = note.Front.Pinned
+	entry.Refs = note.refs()
+	entry.Body = note.Body
+	return s.UpdateEntry(entry)
+}
+
+// createFromVault adds a new note as an item and rewrites it with its id.
+func createFromVault(s *store.Store, dir string, note *vaultNote) (string, error) {
+	if note.Front.Oneliner == "" {
+		note.Front.Oneliner = extractOneliner(note.Body, note.Path)
+	}
+	if note.Front.Type == "lesson" && note.Front.Severity == "" {
+		note.Front.Severity = string(models.SeverityNormal)
+	}
+	if err := validateItemType(note.Front.Type); err != nil {
+		return "", err
+	}
+	if err := checkVaultNote(note); err != nil {
+		return "", err
+	}
+	entry := &doryfile.Entry{
+		Type:     note.Front.Type,
+		Oneliner: strings.TrimSpace(note.Front.Oneliner),
+		Severity: note.Front.Severity,
+		Pinned:   note.Front.Pinned,
+		Refs:     note.refs(),
+		Body:     note.Body,
+	}
+	setEntryTag(entry, note.tag())
+	id, err := s.CreateEntry(entry)
+	if err != nil {
+		return "", err
+	}
+	created, err := s.GetEntry(id)
+	if err != nil {
+		return "", err
+	}
+	return id, writeVaultNote(dir, note.Path, renderVaultNote(created, note.Raw))
+}
+
+func checkVaultNote(note *vaultNote) error {
+	if strings.TrimSpace(note.Front.Oneliner) == "" {
+		return fmt.Errorf("oneliner is empty")
+	}
+	if note.tag() == "" {
+		return fmt.Errorf("tags must name the item's tag")
+	}
+	return validateSeverityFlag(models.Severity(note.Front.Severity))
+}
+
+func init() {
+	importVaultCmd.Flags().Bool("force", false, "Apply notes that conflict with changes made in dory")
+	importCmd.AddCommand(importVaultCmd)
+}
@@ -117,7 +117,8 @@ func createDoryMd() (bool, error) {
 }
 
 // appendDoryInstructions appends dory instructions to a file if it exists
-// and doesn't already contain dory instructions.
+// and doesn't already contain dory instructions. The instructions go in a
+// managed region, so 'dory sync-docs' keeps them current.
 // Returns whether content was appended.
 func appendDoryInstructions(filename string) (bool, error) {
 	// Check if file exists
@@ -134,18 +135,10 @@ func appendDoryInstructions(filename string) (bool, error) {
 		return false, nil // Already has instructions
 	}
 
-	// Append instructions
-	f, err := os.OpenFile(filename, os.O_APPEND|os.O_WRONLY, 0644)
-	if err != nil {
-		return false, err
-	}
-	defer f.Close()
-
-	_, err = f.WriteString(doryInstructions)
-	if err != nil {
+	if _, err := upsertDocRegion(filename, docSpec{Instructions: true}, doryInstructions); err != nil {
 		return false, err
 	}
-	return true, nil
+	return true, registerDocsFile(filename)
 }
 
 func init() {
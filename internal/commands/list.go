@@ -21,13 +21,17 @@ Examples:
   dory list                      # List all items
   dory list --tag database       # Filter by tag
   dory list --type lesson        # Filter by type
-  dory list --tags               # List all tags with counts`,
+  dory list --tags               # List all tags with counts
+
+In a monorepo with nested stores (e.g. services/api/.dory), items from the
+nearest store and every ancestor store are merged and marked with their origin.
+Use --scope nearest or --scope root to read a single store.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		RequireStore()
 
 		showTags, _ := cmd.Flags().GetBool("tags")
 
-		s := store.New(doryRoot)
+		s := openStack()
 		defer s.Close()
 
 		// --tags mode: show tags with counts
@@ -65,7 +69,13 @@ Examples:
 			CheckError(fmt.Errorf("--since must be earlier than or equal to --until"))
 		}
 
-		items, err := s.List(topic, itemType, severity, since, until)
+		items, err := s.List(store.ListFilter{
+			Topic:    topic,
+			Type:     itemType,
+			Severity: severity,
+			Since:    since,
+			Until:    until,
+		})
 		CheckError(err)
 		sortListItems(items, sortKey, desc)
 
@@ -83,6 +93,7 @@ func init() {
 	listCmd.Flags().String("until", "", "Show items created on or before date (YYYY-MM-DD)")
 	listCmd.Flags().StringP("sort", "s", "id", "Sort by: id, created")
 	listCmd.Flags().Bool("desc", false, "Sort in descending order")
+	listCmd.RegisterFlagCompletionFunc("tag", completeTags)
 	listCmd.Flags().MarkHidden("topic")
 	RootCmd.AddCommand(listCmd)
 }
@@ -113,7 +124,19 @@ func renderListHuman(items []store.ListItem) {
 		return
 	}
 
+	// Show an alias column only when the project uses aliases.
+	aliasWidth := 0
+	for _, item := range items {
+		if len(item.Alias) > aliasWidth {
+			aliasWidth = len(item.Alias)
+		}
+	}
+
 	for _, item := range items {
+		if aliasWidth > 0 {
+			fmt.Printf("%-*s  ", aliasWidth, item.Alias)
+		}
+
 		topicStr := item.Topic
 		if topicStr == "" {
 			topicStr = item.Domain
@@ -129,12 +152,18 @@ func renderListHuman(items []store.ListItem) {
 			}
 		}
 
-		fmt.Printf("%s  %-8s  %-15s  %s%s\n",
+		originSuffix := ""
+		if item.Origin != "" {
+			originSuffix = fmt.Sprintf("  (%s)", item.Origin)
+		}
+
+		fmt.Printf("%s  %-8s  %-15s  %s%s%s\n",
 			item.ID,
 			item.Type,
 			topicStr,
 			item.Oneliner,
-			severityIndicator)
+			severityIndicator,
+			originSuffix)
 	}
 }
 
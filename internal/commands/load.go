@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sibellavia/dory/internal/store"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var loadCmd = &cobra.Command{
+	Use:   "load <file|->",
+	Short: "Recreate a store from a 'dory dump' stream",
+	Long: `Recreate a store from a 'dory dump' stream (JSON Lines or YAML, detected
+automatically), keeping every ID, alias, body, ref, and the session state.
+A --history dump also restores deleted items, past versions, and event seqs.
+
+The target store must not exist yet or must be empty, as left by 'dory init'.
+
+Examples:
+  dory load backup.jsonl
+  dory load knowledge.yaml --into ../new-repo/.dory
+  dory dump | ssh host 'cd repo && dory load -'`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		into, _ := cmd.Flags().GetString("into")
+
+		var data []byte
+		var err error
+		if args[0] == "-" {
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			data, err = os.ReadFile(args[0])
+		}
+		CheckError(err)
+		records, err := decodeDump(data)
+		CheckError(err)
+
+		result, err := store.Load(into, records)
+		CheckError(err)
+		OutputResult(cmd, result, func() {
+			fmt.Printf("Loaded %d items (%d events) for '%s' into %s\n", result.Items, result.Events, result.Project, result.Path)
+		})
+	},
+}
+
+// decodeDump reads dump records as JSON Lines, or as YAML documents when
+// the stream does not start with a JSON object.
+func decodeDump(data []byte) ([]store.DumpRecord, error) {
+	var records []store.DumpRecord
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		dec := json.NewDecoder(bytes.NewReader(trimmed))
+		for {
+			var record store.DumpRecord
+			if err := dec.Decode(&record); err == io.EOF {
+				break
+			} else if err != nil {
+				return nil, fmt.Errorf("invalid dump record %d: %w", len(records)+1, err)
+			}
+			records = append(records, record)
+		}
+		return records, nil
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var record store.DumpRecord
+		if err := dec.Decode(&record); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("invalid dump record %d: %w", len(records)+1, err)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func init() {
+	loadCmd.Flags().String("into", ".dory", "Store directory to create")
+	RootCmd.AddCommand(loadCmd)
+}
@@ -0,0 +1,237 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/sibellavia/dory/internal/doryfile"
+	"github.com/sibellavia/dory/internal/store"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// logPollInterval is how often --follow checks the log for new events.
+const logPollInterval = time.Second
+
+var logCmd = &cobra.Command{
+	Use:   "log",
+	Short: "Show the event log, newest first",
+	Long: `Show the knowledge log event by event: every item created, updated,
+and deleted, session state and task/question changes, and compactions.
+Useful for auditing what an agent did during a session.
+
+Each event records when it was written. Logs from older versions of dory
+do not; their events show the latest time known before them, marked with ~
+(time_inferred in JSON and YAML). Compaction rewrites the log, so events
+kept by 'dory compact' show the compaction time, and seqs restart.
+
+Ops: create, update, delete, state, task, question, compact.
+
+Examples:
+  dory log -n 20
+  dory log --id L-01JX --reverse     # Full history of one item
+  dory log --op delete --type lesson
+  dory log --since 2026-01-31T09:00:00Z
+  dory log --follow --json           # Stream new events as JSON lines`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		RequireStore()
+		s := store.New(doryRoot)
+		defer s.Close()
+
+		filter := logFilterFromFlags(cmd)
+		limit, _ := cmd.Flags().GetInt("limit")
+		reverse, _ := cmd.Flags().GetBool("reverse")
+		follow, _ := cmd.Flags().GetBool("follow")
+		if limit < 0 {
+			CheckError(fmt.Errorf("--limit must be 0 or greater"))
+		}
+
+		entries, head, err := s.Log(filter)
+		CheckError(err)
+		if limit > 0 && len(entries) > limit {
+			entries = entries[len(entries)-limit:]
+		}
+
+		if follow {
+			CheckError(followLog(cmd, s, filter, entries, head))
+			return
+		}
+		if !reverse {
+			for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+				entries[i], entries[j] = entries[j], entries[i]
+			}
+		}
+		OutputResult(cmd, entries, func() {
+			if len(entries) == 0 {
+				fmt.Println("No matching events")
+				return
+			}
+			for _, entry := range entries {
+				printLogEntry(entry)
+			}
+		})
+	},
+}
+
+func logFilterFromFlags(cmd *cobra.Command) store.LogFilter {
+	opNames, _ := cmd.Flags().GetStringSlice("op")
+	id, _ := cmd.Flags().GetString("id")
+	itemType, _ := cmd.Flags().GetString("type")
+	sinceStr, _ := cmd.Flags().GetString("since")
+	untilStr, _ := cmd.Flags().GetString("until")
+
+	filter := store.LogFilter{ID: id, Type: itemType}
+	for _, name := range opNames {
+		op, err := store.ParseLogOp(name)
+		CheckError(err)
+		filter.Ops = append(filter.Ops, op)
+	}
+
+	var err error
+	if sinceStr != "" {
+		filter.Since, _, err = parseLogTime(sinceStr, "--since")
+		CheckError(err)
+	}
+	if untilStr != "" {
+		var dateOnly bool
+		filter.Until, dateOnly, err = parseLogTime(untilStr, "--until")
+		CheckError(err)
+		if dateOnly {
+			// Include the full day for date-only filters.
+			filter.Until = filter.Until.Add(24*time.Hour - time.Nanosecond)
+		}
+	}
+	if !filter.Since.IsZero() && !filter.Until.IsZero() && filter.Since.After(filter.Until) {
+		CheckError(fmt.Errorf("--since must be earlier than or equal to --until"))
+	}
+	return filter
+}
+
+// parseLogTime accepts a date (YYYY-MM-DD) or an RFC3339 time, reporting
+// whether only a date was given.
+func parseLogTime(value, flagName string) (time.Time, bool, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, false, nil
+	}
+	t, err := parseDateFlag(value, flagName)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid %s value %q (expected YYYY-MM-DD or RFC3339)", flagName, value)
+	}
+	return t, true, nil
+}
+
+// followLog prints entries oldest first, then polls for new events until
+// interrupted. JSON output is one object per line; YAML is one document
+// per event.
+func followLog(cmd *cobra.Command, s *store.Store, filter store.LogFilter, entries []store.LogEntry, head uint64) error {
+	var emit func(store.LogEntry) error
+	switch GetOutputFormat(cmd) {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		emit = func(entry store.LogEntry) error { return enc.Encode(entry) }
+	case "yaml":
+		enc := yaml.NewEncoder(os.Stdout)
+		enc.SetIndent(2)
+		defer enc.Close()
+		emit = func(entry store.LogEntry) error { return enc.Encode(entry) }
+	default:
+		emit = func(entry store.LogEntry) error {
+			printLogEntry(entry)
+			return nil
+		}
+	}
+
+	info, err := s.LogFile()
+	if err != nil {
+		return err
+	}
+	for {
+		for _, entry := range entries {
+			if err := emit(entry); err != nil {
+				return err
+			}
+		}
+		time.Sleep(logPollInterval)
+
+		current, err := s.LogFile()
+		if err != nil {
+			return err
+		}
+		filter.AfterSeq = head
+		if !os.SameFile(info, current) || current.Size() < info.Size() {
+			// Compaction rewrote the log and restarted its seqs: resume
+			// after its compact marker, or from the end if it has none.
+			filter.AfterSeq, err = lastCompaction(s)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(os.Stderr, "Log compacted; following the new log")
+		}
+		var latest uint64
+		entries, latest, err = s.Log(filter)
+		if err != nil {
+			return err
+		}
+		after, err := s.LogFile()
+		if err != nil {
+			return err
+		}
+		if !os.SameFile(current, after) {
+			// Rewritten while reading; pick it up on the next poll.
+			entries = nil
+			info = current
+			continue
+		}
+		info, head = current, latest
+	}
+}
+
+// lastCompaction returns the seq of the last compact marker in the log, or
+// the last seq when there is none (logs compacted by older versions).
+func lastCompaction(s *store.Store) (uint64, error) {
+	markers, head, err := s.Log(store.LogFilter{Ops: []string{doryfile.OpCompact}})
+	if err != nil || len(markers) == 0 {
+		return head, err
+	}
+	return markers[len(markers)-1].Seq, nil
+}
+
+func printLogEntry(entry store.LogEntry) {
+	line := fmt.Sprintf("%6d  %-14s", entry.Seq, entry.Op)
+	if entry.ID != "" {
+		line += "  " + entry.ID
+	}
+	if entry.Type != "" && entry.Type != "task" && entry.Type != "question" {
+		line += fmt.Sprintf("  [%s]", entry.Type)
+	}
+	if entry.Status != "" {
+		line += fmt.Sprintf("  [%s]", entry.Status)
+	}
+	if entry.Oneliner != "" {
+		line += "  " + truncateOneliner(entry.Oneliner, 60)
+	}
+	if entry.Time != "" {
+		approx := ""
+		if entry.Inferred {
+			approx = "~"
+		}
+		line += fmt.Sprintf("  (%s%s)", approx, entry.Time)
+	}
+	fmt.Println(line)
+}
+
+func init() {
+	logCmd.Flags().StringSlice("op", nil, "Only show these ops (repeatable or comma-separated)")
+	logCmd.Flags().String("id", "", "Only show events for this item, task, or question")
+	logCmd.Flags().String("type", "", "Only show events for items of this type")
+	logCmd.Flags().String("since", "", "Only show events on or after this date or time")
+	logCmd.Flags().String("until", "", "Only show events on or before this date or time")
+	logCmd.Flags().IntP("limit", "n", 0, "Show only the N most recent matching events (0 for all)")
+	logCmd.Flags().Bool("reverse", false, "Show oldest first")
+	logCmd.Flags().BoolP("follow", "f", false, "Keep running and print new events as they are appended")
+	logCmd.RegisterFlagCompletionFunc("id", completeIDs(0))
+	RootCmd.AddCommand(logCmd)
+}
@@ -0,0 +1,188 @@
+package commands
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// A small markdown-to-HTML renderer for item bodies: headings, paragraphs,
+// lists, block quotes, fenced code, rules, and inline code, emphasis, and
+// links. Everything else is kept as escaped text.
+
+var (
+	mdOrderedItem = regexp.MustCompile(`^\d+[.)]\s+`)
+	mdLink        = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	mdStrong      = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	mdEm          = regexp.MustCompile(`\*([^*\s][^*]*)\*|\b_([^_\s][^_]*)_\b`)
+	mdItemID      = regexp.MustCompile(`\b[A-Z]+-[0-9A-Z]{26}\b`)
+)
+
+// markdownHTML renders body as HTML. linkID returns the URL for an item ID
+// mentioned in the text, or "" to leave it unlinked.
+func markdownHTML(body string, linkID func(id string) string) string {
+	var out strings.Builder
+	var para []string
+	var list string
+	var quote []string
+	var fence []string
+	inFence, fenceLang := false, ""
+
+	flushPara := func() {
+		if len(para) > 0 {
+			fmt.Fprintf(&out, "<p>%s</p>\n", inlineHTML(strings.Join(para, " "), linkID))
+			para = nil
+		}
+	}
+	closeList := func() {
+		if list != "" {
+			fmt.Fprintf(&out, "</%s>\n", list)
+			list = ""
+		}
+	}
+	flushQuote := func() {
+		if len(quote) > 0 {
+			fmt.Fprintf(&out, "<blockquote><p>%s</p></blockquote>\n", inlineHTML(strings.Join(quote, " "), linkID))
+			quote = nil
+		}
+	}
+	flushAll := func() {
+		flushPara()
+		closeList()
+		flushQuote()
+	}
+
+	for _, raw := range strings.Split(strings.TrimRight(body, "\n"), "\n") {
+		trimmed := strings.TrimSpace(raw)
+		if strings.HasPrefix(trimmed, "```") {
+			if inFence {
+				class := ""
+				if fenceLang != "" {
+					class = fmt.Sprintf(` class="language-%s"`, html.EscapeString(fenceLang))
+				}
+				fmt.Fprintf(&out, "<pre><code%s>%s</code></pre>\n", class, html.EscapeString(strings.Join(fence, "\n")))
+				fence, inFence = nil, false
+			} else {
+				flushAll()
+				inFence, fenceLang = true, strings.TrimSpace(strings.TrimPrefix(trimmed, "```"))
+			}
+			continue
+		}
+		if inFence {
+			fence = append(fence, raw)
+			continue
+		}
+
+		switch {
+		case trimmed == "":
+			flushAll()
+		case strings.HasPrefix(trimmed, "#"):
+			flushAll()
+			level := len(trimmed) - len(strings.TrimLeft(trimmed, "#"))
+			if level > 6 {
+				level = 6
+			}
+			text := strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+			fmt.Fprintf(&out, "<h%d>%s</h%d>\n", level, inlineHTML(text, linkID), level)
+		case trimmed == "---" || trimmed == "***" || trimmed == "___":
+			flushAll()
+			out.WriteString("<hr>\n")
+		case strings.HasPrefix(trimmed, ">"):
+			flushPara()
+			closeList()
+			quote = append(quote, strings.TrimSpace(strings.TrimPrefix(trimmed, ">")))
+		case strings.HasPrefix(trimmed, "- ") || strings.HasPrefix(trimmed, "* ") || strings.HasPrefix(trimmed, "+ "):
+			flushPara()
+			flushQuote()
+			if list != "ul" {
+				closeList()
+				out.WriteString("<ul>\n")
+				list = "ul"
+			}
+			fmt.Fprintf(&out, "<li>%s</li>\n", inlineHTML(trimmed[2:], linkID))
+		case mdOrderedItem.MatchString(trimmed):
+			flushPara()
+			flushQuote()
+			if list != "ol" {
+				closeList()
+				out.WriteString("<ol>\n")
+				list = "ol"
+			}
+			fmt.Fprintf(&out, "<li>%s</li>\n", inlineHTML(mdOrderedItem.ReplaceAllString(trimmed, ""), linkID))
+		default:
+			if list != "" && raw != trimmed {
+				// An indented line continues the last list item.
+				para = append(para, trimmed)
+				continue
+			}
+			closeList()
+			flushQuote()
+			para = append(para, trimmed)
+		}
+	}
+	if inFence {
+		fmt.Fprintf(&out, "<pre><code>%s</code></pre>\n", html.EscapeString(strings.Join(fence, "\n")))
+	}
+	flushAll()
+	return out.String()
+}
+
+// inlineHTML escapes text and renders code spans, links, emphasis, and
+// item IDs. Code spans and links are swapped for placeholders first so the
+// later passes cannot rewrite their contents.
+func inlineHTML(text string, linkID func(id string) string) string {
+	var saved []string
+	hold := func(fragment string) string {
+		saved = append(saved, fragment)
+		return fmt.Sprintf("\x00%d\x00", len(saved)-1)
+	}
+
+	var b strings.Builder
+	parts := strings.Split(text, "`")
+	for i, part := range parts {
+		if i%2 == 1 && i < len(parts)-1 {
+			b.WriteString(hold("<code>" + html.EscapeString(part) + "</code>"))
+			continue
+		}
+		if i%2 == 1 {
+			b.WriteString("`")
+		}
+		b.WriteString(part)
+	}
+	text = html.EscapeString(b.String())
+
+	text = mdLink.ReplaceAllStringFunc(text, func(match string) string {
+		m := mdLink.FindStringSubmatch(match)
+		href := html.UnescapeString(m[2])
+		if !safeHref(href) {
+			return match
+		}
+		return hold(fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(href), m[1]))
+	})
+	if linkID != nil {
+		text = mdItemID.ReplaceAllStringFunc(text, func(id string) string {
+			if href := linkID(id); href != "" {
+				return hold(fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(href), id))
+			}
+			return id
+		})
+	}
+	text = mdStrong.ReplaceAllString(text, "<strong>$1$2</strong>")
+	text = mdEm.ReplaceAllString(text, "<em>$1$2</em>")
+
+	for i := len(saved) - 1; i >= 0; i-- {
+		text = strings.ReplaceAll(text, fmt.Sprintf("\x00%d\x00", i), saved[i])
+	}
+	return text
+}
+
+// safeHref allows web, mail, relative, and fragment links, but no scripts.
+func safeHref(href string) bool {
+	lower := strings.ToLower(href)
+	if i := strings.Index(lower, ":"); i >= 0 && !strings.ContainsAny(lower[:i], "/?#") {
+		scheme := lower[:i]
+		return scheme == "http" || scheme == "https" || scheme == "mailto"
+	}
+	return true
+}
@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/sibellavia/dory/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var pinCmd = &cobra.Command{
+	Use:   "pin <id>",
+	Short: "Pin an item so it always appears in context",
+	Long: `Pin an item. Pinned items are listed first in 'dory context' and
+'dory export', regardless of age or severity.
+
+The ID may be a full ID, an alias (L-42), or any unique prefix.
+
+Examples:
+  dory pin C-01JX...
+  dory unpin C-01JX...`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		RequireStore()
+		setPinned(cmd, args[0], true)
+	},
+}
+
+// setPinned pins or unpins an item and reports the result.
+func setPinned(cmd *cobra.Command, ref string, pinned bool) {
+	s := store.New(doryRoot)
+	defer s.Close()
+
+	id, changed, err := s.SetPinned(ref, pinned)
+	CheckError(err)
+
+	status, verb := "pinned", "Pinned"
+	if !pinned {
+		status, verb = "unpinned", "Unpinned"
+	}
+	result := map[string]interface{}{
+		"id":      id,
+		"status":  status,
+		"changed": changed,
+	}
+
+	OutputResult(cmd, result, func() {
+		if changed {
+			fmt.Printf("%s %s\n", verb, id)
+		} else {
+			fmt.Printf("%s is already %s\n", id, status)
+		}
+	})
+}
+
+func init() {
+	pinCmd.ValidArgsFunction = completeIDs(1)
+	RootCmd.AddCommand(pinCmd)
+}
@@ -26,5 +26,6 @@ var pluginDisableCmd = &cobra.Command{
 }
 
 func init() {
+	pluginDisableCmd.ValidArgsFunction = completePlugins
 	pluginCmd.AddCommand(pluginDisableCmd)
 }
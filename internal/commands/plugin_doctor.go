@@ -68,5 +68,6 @@ var pluginDoctorCmd = &cobra.Command{
 
 func init() {
 	pluginDoctorCmd.Flags().Duration("timeout", 3*time.Second, "Health-check timeout per plugin")
+	pluginDoctorCmd.ValidArgsFunction = completePlugins
 	pluginCmd.AddCommand(pluginDoctorCmd)
 }
@@ -33,5 +33,6 @@ var pluginEnableCmd = &cobra.Command{
 }
 
 func init() {
+	pluginEnableCmd.ValidArgsFunction = completePlugins
 	pluginCmd.AddCommand(pluginEnableCmd)
 }
@@ -55,6 +55,9 @@ var pluginInspectCmd = &cobra.Command{
 			if len(selected.Capabilities.Types) > 0 {
 				fmt.Printf("  Types: %s\n", strings.Join(selected.Capabilities.Types, ", "))
 			}
+			if selected.Capabilities.Embeddings {
+				fmt.Println("  Embeddings: yes")
+			}
 			for _, issue := range issues {
 				fmt.Printf("Warning: %s (%s)\n", issue.Error, pluginPathDisplay(issue.Path))
 			}
@@ -63,5 +66,6 @@ var pluginInspectCmd = &cobra.Command{
 }
 
 func init() {
+	pluginInspectCmd.ValidArgsFunction = completePlugins
 	pluginCmd.AddCommand(pluginInspectCmd)
 }
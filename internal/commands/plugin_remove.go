@@ -48,5 +48,6 @@ var pluginRemoveCmd = &cobra.Command{
 
 func init() {
 	pluginRemoveCmd.Flags().BoolP("force", "f", false, "Remove without confirmation")
+	pluginRemoveCmd.ValidArgsFunction = completePlugins
 	pluginCmd.AddCommand(pluginRemoveCmd)
 }
@@ -111,5 +111,6 @@ func resolvePluginCommand(requested []string, available []string) (string, []str
 
 func init() {
 	pluginRunCmd.Flags().Duration("timeout", 5*time.Second, "Plugin command timeout")
+	pluginRunCmd.ValidArgsFunction = completePluginRun
 	pluginCmd.AddCommand(pluginRunCmd)
 }
@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"strings"
+
+	"github.com/sibellavia/dory/internal/query"
+	"github.com/sibellavia/dory/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var queryCmd = &cobra.Command{
+	Use:   "query <expression>",
+	Short: "List items matching a filter expression",
+	Long: `List items matching a filter expression.
+
+Terms have the form field:value and are combined with AND, OR, NOT, and
+parentheses. Adjacent terms without an operator are joined with AND.
+
+Fields:
+  id, type, tag, topic, domain, ref, status   match a value (value* for a prefix)
+  severity                                    supports <, <=, >, >= (low < normal < high < critical)
+  created                                     supports <, <=, >, >= with YYYY-MM-DD or RFC3339
+  text                                        substring of the oneliner (a bare word does the same)
+  pinned                                      true or false (see dory pin)
+
+Operators: field:value, field=value, field!=value, field>value, field>=value,
+field<value, field<=value. Quote values with spaces: text:"connection pool".
+
+Every item currently has status active.
+
+Examples:
+  dory query 'type:lesson AND severity>=high'
+  dory query 'type:lesson AND (tag:auth OR tag:api) AND created>2026-01-01'
+  dory query 'NOT type:decision tag:db*'
+
+The same expression syntax is accepted by --filter on export, context, and
+show --graph.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		RequireStore()
+
+		expr, err := query.Parse(strings.Join(args, " "))
+		CheckError(err)
+
+		sortKey, _ := cmd.Flags().GetString("sort")
+		sortKey = resolveListSort(cmd, sortKey)
+		desc, _ := cmd.Flags().GetBool("desc")
+		CheckError(validateListSort(sortKey))
+
+		s := openStack()
+		defer s.Close()
+
+		items, err := s.List(store.ListFilter{Query: expr})
+		CheckError(err)
+		sortListItems(items, sortKey, desc)
+
+		OutputResult(cmd, items, func() { renderListHuman(items) })
+	},
+}
+
+func init() {
+	queryCmd.Flags().StringP("sort", "s", "id", "Sort by: id, created")
+	queryCmd.Flags().Bool("desc", false, "Sort in descending order")
+	RootCmd.AddCommand(queryCmd)
+}
+
+// resolveFilter parses the --filter flag; an empty flag yields a nil filter.
+func resolveFilter(cmd *cobra.Command) query.Expr {
+	raw, _ := cmd.Flags().GetString("filter")
+	if strings.TrimSpace(raw) == "" {
+		return nil
+	}
+	expr, err := query.Parse(raw)
+	CheckError(err)
+	return expr
+}
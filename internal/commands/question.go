@@ -0,0 +1,23 @@
+package commands
+
+import "github.com/spf13/cobra"
+
+var questionCmd = &cobra.Command{
+	Use:   "question",
+	Short: "Track open questions in session state",
+	Long: `Manage the session's open questions individually.
+
+Each question has an ID (Q-1, Q-2, ...) and a status: open or answered.
+An answer can link an existing item, or create a lesson or decision
+that refs the question.
+
+Examples:
+  dory question add "Which queue backs the mailer?"
+  dory question answer Q-2 --with D-01JX...
+  dory question answer Q-2 --kind decision --tag mail "Use SQS for the mailer"
+  dory question list`,
+}
+
+func init() {
+	RootCmd.AddCommand(questionCmd)
+}
@@ -0,0 +1,32 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sibellavia/dory/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var questionAddCmd = &cobra.Command{
+	Use:   "add <text>",
+	Short: "Add an open question",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		RequireStore()
+
+		s := store.New(doryRoot)
+		defer s.Close()
+
+		question, err := s.AddQuestion(strings.Join(args, " "))
+		CheckError(err)
+
+		OutputResult(cmd, question, func() {
+			fmt.Printf("Added %s: %s\n", question.ID, question.Text)
+		})
+	},
+}
+
+func init() {
+	questionCmd.AddCommand(questionAddCmd)
+}
@@ -0,0 +1,156 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sibellavia/dory/internal/models"
+	"github.com/sibellavia/dory/internal/plugin"
+	"github.com/sibellavia/dory/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var questionAnswerCmd = &cobra.Command{
+	Use:   "answer <id> [oneliner]",
+	Short: "Answer an open question",
+	Long: `Mark a question as answered.
+
+Use --with to link an existing item as the answer, --answer to record a short
+free-text answer, or --kind to create a new lesson or decision that refs the
+question and becomes its answer. An answered question keeps its answer
+unless --reanswer is given.
+
+Examples:
+  dory question answer Q-1 --answer "Postgres, see infra repo"
+  dory question answer Q-1 --with L-01JX...
+  dory question answer Q-1 --kind lesson --tag mail "SendGrid needs domain verification"`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		RequireStore()
+
+		id := args[0]
+		with, _ := cmd.Flags().GetString("with")
+		answer, _ := cmd.Flags().GetString("answer")
+		kind, _ := cmd.Flags().GetString("kind")
+		tag, _ := cmd.Flags().GetString("tag")
+		severityStr, _ := cmd.Flags().GetString("severity")
+		severity := models.Severity(severityStr)
+		reanswer, _ := cmd.Flags().GetBool("reanswer")
+
+		if with != "" && kind != "" {
+			CheckError(fmt.Errorf("--with and --kind cannot be used together"))
+		}
+
+		s := store.New(doryRoot)
+		defer s.Close()
+
+		// Check before --kind creates an item, so a refused answer leaves nothing behind.
+		question := findQuestion(s, id)
+		if question.Status == "answered" && !reanswer {
+			CheckError(fmt.Errorf("question %s is already answered (use --reanswer to replace the answer)", question.ID))
+		}
+
+		if kind != "" {
+			oneliner := strings.Join(args[1:], " ")
+			if oneliner == "" {
+				oneliner = answer
+			}
+			if oneliner == "" {
+				CheckError(fmt.Errorf("a oneliner is required with --kind"))
+			}
+			if tag == "" {
+				CheckError(fmt.Errorf("--tag is required with --kind"))
+			}
+			with = createAnswerItem(kind, question, oneliner, answer, tag, severity, s)
+		} else if len(args) > 1 {
+			CheckError(fmt.Errorf("unexpected arguments; use --answer for free-text answers"))
+		}
+
+		answered, err := s.AnswerQuestion(id, answer, with, reanswer)
+		CheckError(err)
+
+		OutputResult(cmd, answered, func() {
+			if answered.AnsweredBy != "" {
+				fmt.Printf("Answered %s with %s\n", answered.ID, answered.AnsweredBy)
+				return
+			}
+			fmt.Printf("Answered %s\n", answered.ID)
+		})
+	},
+}
+
+func findQuestion(s *store.Store, id string) store.Question {
+	questions, err := s.Questions()
+	CheckError(err)
+	for _, question := range questions {
+		if strings.EqualFold(question.ID, id) {
+			return question
+		}
+	}
+	CheckError(fmt.Errorf("question %s not found", id))
+	return store.Question{}
+}
+
+// createAnswerItem records a lesson or decision that refs the answered question.
+// The body's Answer section holds the free-text answer, or the oneliner when
+// there is none.
+func createAnswerItem(kind string, question store.Question, oneliner, answer, tag string, severity models.Severity, s *store.Store) string {
+	refs := []string{question.ID}
+	if answer == "" {
+		answer = oneliner
+	}
+	body := fmt.Sprintf("# %s\n\n## Question\n\n%s (%s)\n\n## Answer\n\n%s\n", oneliner, question.Text, question.ID, answer)
+
+	switch kind {
+	case "lesson":
+		if severity == "" {
+			severity = models.SeverityNormal
+		}
+		CheckError(validateSeverityFlag(severity))
+	case "decision":
+		if severity != "" {
+			CheckError(fmt.Errorf("--severity only applies to lessons"))
+		}
+	default:
+		CheckError(fmt.Errorf("invalid --kind %q (use: lesson, decision)", kind))
+	}
+
+	runPluginHooks(plugin.HookBeforeCreate, map[string]interface{}{
+		"type":     kind,
+		"oneliner": oneliner,
+		"topic":    tag,
+		"severity": string(severity),
+		"refs":     refs,
+	})
+
+	var id string
+	var err error
+	if kind == "lesson" {
+		id, err = s.Learn(oneliner, tag, severity, body, refs)
+	} else {
+		id, err = s.Decide(oneliner, tag, "", body, refs)
+	}
+	CheckError(err)
+
+	runPluginHooks(plugin.HookAfterCreate, map[string]interface{}{
+		"id":       id,
+		"type":     kind,
+		"oneliner": oneliner,
+		"topic":    tag,
+		"severity": string(severity),
+		"refs":     refs,
+	})
+	return id
+}
+
+func init() {
+	questionAnswerCmd.Flags().String("with", "", "Existing item ID that answers the question")
+	questionAnswerCmd.Flags().StringP("answer", "a", "", "Free-text answer")
+	questionAnswerCmd.Flags().StringP("kind", "k", "", "Create a new item as the answer: lesson, decision")
+	questionAnswerCmd.Flags().StringP("tag", "T", "", "Tag for the created item (with --kind)")
+	questionAnswerCmd.Flags().StringP("severity", "S", "", "Severity for a created lesson: critical, high, normal, low")
+	questionAnswerCmd.Flags().Bool("reanswer", false, "Replace the answer of an already answered question")
+	questionAnswerCmd.RegisterFlagCompletionFunc("with", completeIDs(0))
+	questionAnswerCmd.RegisterFlagCompletionFunc("tag", completeTags)
+	questionCmd.AddCommand(questionAnswerCmd)
+}
@@ -0,0 +1,52 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/sibellavia/dory/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var questionListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List session questions",
+	Run: func(cmd *cobra.Command, args []string) {
+		RequireStore()
+
+		all, _ := cmd.Flags().GetBool("all")
+
+		s := store.New(doryRoot)
+		defer s.Close()
+
+		questions, err := s.Questions()
+		CheckError(err)
+
+		filtered := make([]store.Question, 0, len(questions))
+		for _, question := range questions {
+			if all || question.Status != "answered" {
+				filtered = append(filtered, question)
+			}
+		}
+
+		OutputResult(cmd, filtered, func() {
+			if len(filtered) == 0 {
+				fmt.Println("No questions found")
+				return
+			}
+			for _, question := range filtered {
+				fmt.Printf("%-5s  %-8s  %s\n", question.ID, question.Status, question.Text)
+				if question.Answer != "" {
+					fmt.Printf("       answer: %s\n", question.Answer)
+				}
+				if question.AnsweredBy != "" {
+					fmt.Printf("       answered by: %s\n", question.AnsweredBy)
+				}
+			}
+		})
+	},
+}
+
+func init() {
+	questionListCmd.Flags().Bool("all", false, "Include answered questions")
+	questionCmd.AddCommand(questionListCmd)
+}
@@ -14,13 +14,26 @@ import (
 var removeCmd = &cobra.Command{
 	Use:   "remove <id>",
 	Short: "Remove an item",
-	Long:  `Delete an item from the knowledge store. Requires confirmation unless --force is used.`,
-	Args:  cobra.ExactArgs(1),
+	Long: `Delete an item from the knowledge store. Requires confirmation unless --force is used.
+
+The ID may be a full ID, an alias (L-42), or any unique prefix.
+
+When other items still reference the item, remove lists them and refuses,
+with or without --force. Use --cascade-unlink to strip the ref from those
+items in the same write, or --allow-dangling to remove it anyway and leave
+their refs dangling.
+
+Examples:
+  dory remove L-01JX...
+  dory remove L-01JX... --cascade-unlink --force`,
+	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		RequireStore()
 
-		id := args[0]
+		id := resolveItemID(args[0])
 		force, _ := cmd.Flags().GetBool("force")
+		cascade, _ := cmd.Flags().GetBool("cascade-unlink")
+		allowDangling, _ := cmd.Flags().GetBool("allow-dangling")
 		requireInteractive(force, "--force")
 
 		s := store.New(doryRoot)
@@ -30,6 +43,16 @@ var removeCmd = &cobra.Command{
 		content, err := s.Show(id)
 		CheckError(err)
 
+		referencing, err := checkReferences(s, id, cascade, allowDangling)
+		CheckError(err)
+		referencingIDs := make([]string, 0, len(referencing))
+		for _, item := range referencing {
+			referencingIDs = append(referencingIDs, item.ID)
+		}
+		if len(referencing) > 0 && !cascade {
+			fmt.Fprintf(os.Stderr, "Warning: leaving dangling refs to %s in %s\n", id, strings.Join(referencingIDs, ", "))
+		}
+
 		if !force {
 			fmt.Printf("About to remove %s:\n\n", id)
 			// Show first few lines
@@ -44,6 +67,9 @@ var removeCmd = &cobra.Command{
 			if len(lines) > 10 {
 				fmt.Println("...")
 			}
+			if len(referencing) > 0 {
+				fmt.Printf("\nThe ref will be removed from:\n%s\n", formatReferencing(referencing))
+			}
 
 			fmt.Print("\nConfirm removal? [y/N] ")
 			reader := bufio.NewReader(os.Stdin)
@@ -57,28 +83,67 @@ var removeCmd = &cobra.Command{
 		}
 
 		runPluginHooks(plugin.HookBeforeRemove, map[string]interface{}{
-			"id": id,
+			"id":             id,
+			"referenced_by":  referencingIDs,
+			"cascade_unlink": cascade,
 		})
 
-		err = s.Remove(id)
+		var unlinked []string
+		if cascade {
+			unlinked, err = s.RemoveUnlinking(id)
+		} else {
+			err = s.Remove(id)
+		}
 		CheckError(err)
 
 		runPluginHooks(plugin.HookAfterRemove, map[string]interface{}{
-			"id": id,
+			"id":       id,
+			"unlinked": unlinked,
 		})
 
-		result := map[string]string{
+		result := map[string]interface{}{
 			"id":     id,
 			"status": "removed",
 		}
+		if len(unlinked) > 0 {
+			result["unlinked"] = unlinked
+		}
 
 		OutputResult(cmd, result, func() {
 			fmt.Printf("Removed %s\n", id)
+			if len(unlinked) > 0 {
+				fmt.Printf("Unlinked from %s\n", strings.Join(unlinked, ", "))
+			}
 		})
 	},
 }
 
+// checkReferences returns the items that reference id, or an error listing
+// them when there are any and neither cascade nor allowDangling is set.
+func checkReferences(s *store.Store, id string, cascade, allowDangling bool) ([]store.ListItem, error) {
+	referencing, err := s.ReferencedBy(id)
+	if err != nil {
+		return nil, err
+	}
+	if len(referencing) > 0 && !cascade && !allowDangling {
+		return nil, fmt.Errorf("%s is referenced by %d item(s):\n%s\nUse --cascade-unlink to remove those refs, or --allow-dangling to leave them dangling",
+			id, len(referencing), formatReferencing(referencing))
+	}
+	return referencing, nil
+}
+
+func formatReferencing(items []store.ListItem) string {
+	lines := make([]string, 0, len(items))
+	for _, item := range items {
+		lines = append(lines, fmt.Sprintf("  %s  %s", item.ID, item.Oneliner))
+	}
+	return strings.Join(lines, "\n")
+}
+
 func init() {
 	removeCmd.Flags().BoolP("force", "f", false, "Remove without confirmation")
+	removeCmd.Flags().Bool("cascade-unlink", false, "Remove the ref from items that reference this one")
+	removeCmd.Flags().Bool("allow-dangling", false, "Remove even if other items reference it, leaving their refs dangling")
+	removeCmd.ValidArgsFunction = completeIDs(1)
 	RootCmd.AddCommand(removeCmd)
 }
@@ -0,0 +1,40 @@
+package commands
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sibellavia/dory/internal/models"
+	"github.com/sibellavia/dory/internal/store"
+)
+
+func TestCheckReferences(t *testing.T) {
+	s := store.New(filepath.Join(t.TempDir(), ".dory"))
+	defer s.Close()
+	if err := s.Init("project", ""); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	decision, err := s.Decide("Use Postgres", "db", "", "", nil)
+	if err != nil {
+		t.Fatalf("decide: %v", err)
+	}
+	lesson, err := s.Learn("Vacuum nightly", "db", models.SeverityNormal, "", []string{decision})
+	if err != nil {
+		t.Fatalf("learn: %v", err)
+	}
+
+	// --force only skips the prompt; it does not allow dangling refs.
+	if _, err := checkReferences(s, decision, false, false); err == nil || !strings.Contains(err.Error(), lesson) {
+		t.Fatalf("expected a referenced item to be refused, got %v", err)
+	}
+	for _, allow := range [][2]bool{{true, false}, {false, true}} {
+		referencing, err := checkReferences(s, decision, allow[0], allow[1])
+		if err != nil || len(referencing) != 1 || referencing[0].ID != lesson {
+			t.Fatalf("cascade=%v allow-dangling=%v: got %v, %v", allow[0], allow[1], referencing, err)
+		}
+	}
+	if referencing, err := checkReferences(s, lesson, false, false); err != nil || len(referencing) != 0 {
+		t.Fatalf("expected an unreferenced item to pass, got %v, %v", referencing, err)
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"os"
 	"strings"
-	"time"
 
 	"github.com/sibellavia/dory/internal/doryfile"
 	"github.com/sibellavia/dory/internal/store"
@@ -31,7 +30,7 @@ Use --full to completely reinitialize (like rm -rf .dory && dory init).`,
 		defer s.Close()
 
 		// Count items to show user
-		items, err := s.List("", "", "", time.Time{}, time.Time{})
+		items, err := s.List(store.ListFilter{})
 		CheckError(err)
 		itemCount := len(items)
 
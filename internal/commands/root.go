@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"path/filepath"
 
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
@@ -13,6 +12,8 @@ import (
 var (
 	outputFormat string
 	agentMode    bool
+	scopeFlag    string
+	globalFlag   bool
 )
 
 // RootCmd is the root command for dory
@@ -31,9 +32,8 @@ func init() {
 	RootCmd.PersistentFlags().Bool("json", false, "Output in JSON format (shorthand for --format=json)")
 	RootCmd.PersistentFlags().Bool("yaml", false, "Output in YAML format (shorthand for --format=yaml)")
 	RootCmd.PersistentFlags().BoolVar(&agentMode, "agent", false, "Agent mode: machine-oriented defaults (YAML output, no interactive prompts)")
-
-	// Hide the auto-generated completion command
-	RootCmd.CompletionOptions.HiddenDefaultCmd = true
+	RootCmd.PersistentFlags().StringVar(&scopeFlag, "scope", "", "Store scope: nearest, root (default: reads merge all stores up the tree, writes go to the nearest)")
+	RootCmd.PersistentFlags().BoolVar(&globalFlag, "global", false, "Use the user-global store: include it in reads and send writes to it")
 }
 
 // GetOutputFormat returns the output format from flags
@@ -67,26 +67,11 @@ func GetOutputFormat(cmd *cobra.Command) string {
 }
 
 func resolveDoryRoot(start string) (string, error) {
-	dir, err := filepath.Abs(start)
+	roots, err := resolveDoryRoots(start)
 	if err != nil {
 		return "", err
 	}
-
-	for {
-		root := filepath.Join(dir, ".dory")
-		indexPath := filepath.Join(root, "index.yaml")
-		if info, err := os.Stat(indexPath); err == nil && !info.IsDir() {
-			return root, nil
-		}
-
-		parent := filepath.Dir(dir)
-		if parent == dir {
-			break
-		}
-		dir = parent
-	}
-
-	return "", os.ErrNotExist
+	return roots[0], nil
 }
 
 func requireInteractive(force bool, flagName string) {
@@ -134,12 +119,33 @@ func CheckError(err error) {
 
 // RequireStore ensures the dory store exists
 func RequireStore() {
-	root, err := resolveDoryRoot(".")
-	if err != nil {
+	CheckError(validateScope(scopeFlag))
+	if !loadStores() {
 		fmt.Fprintln(os.Stderr, "Error: Dory not initialized. Run 'dory init' first.")
 		os.Exit(1)
 	}
-	doryRoot = root
+}
+
+// loadStores sets doryRoot and doryRoots from the working directory and the
+// scope flags, reporting whether a store was found.
+func loadStores() bool {
+	roots, err := resolveDoryRoots(".")
+	if err != nil && !globalFlag {
+		return false
+	}
+	doryRoots = nil
+	if len(roots) > 0 {
+		doryRoot, doryRoots = applyScope(roots, scopeFlag)
+	}
+
+	globalRoot := resolveGlobalRoot(roots)
+	if globalRoot != "" {
+		doryRoots = append(doryRoots, globalRoot)
+		if globalFlag {
+			doryRoot = globalRoot
+		}
+	}
+	return true
 }
 
 // resolveTag returns the tag value, checking --tag first then falling back to
@@ -0,0 +1,136 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/sibellavia/dory/internal/config"
+	"github.com/sibellavia/dory/internal/store"
+)
+
+const (
+	scopeNearest = "nearest"
+	scopeRoot    = "root"
+)
+
+// doryRoots lists the stores that participate in reads, nearest first.
+var doryRoots []string
+
+// resolveDoryRoots returns every store from start up to the filesystem root,
+// nearest first.
+func resolveDoryRoots(start string) ([]string, error) {
+	dir, err := filepath.Abs(start)
+	if err != nil {
+		return nil, err
+	}
+
+	var roots []string
+	for {
+		root := filepath.Join(dir, store.DoryDir)
+		indexPath := filepath.Join(root, "index.yaml")
+		if info, err := os.Stat(indexPath); err == nil && !info.IsDir() {
+			roots = append(roots, root)
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	if len(roots) == 0 {
+		return nil, os.ErrNotExist
+	}
+	return roots, nil
+}
+
+func validateScope(scope string) error {
+	switch scope {
+	case "", scopeNearest, scopeRoot:
+		return nil
+	default:
+		return fmt.Errorf("invalid --scope value %q (expected: %s, %s)", scope, scopeNearest, scopeRoot)
+	}
+}
+
+// applyScope picks the write store and the read stores for a scope.
+// Without a scope, reads merge every store and writes go to the nearest one.
+func applyScope(roots []string, scope string) (string, []string) {
+	switch scope {
+	case scopeNearest:
+		return roots[0], roots[:1]
+	case scopeRoot:
+		last := roots[len(roots)-1]
+		return last, []string{last}
+	default:
+		return roots[0], roots
+	}
+}
+
+// scopeOrigin labels a store by its project directory relative to the
+// outermost store, or "root" for the outermost store itself.
+func scopeOrigin(root, outermost string) string {
+	base := filepath.Dir(outermost)
+	dir := filepath.Dir(root)
+	if dir == base {
+		return scopeRoot
+	}
+	if rel, err := filepath.Rel(base, dir); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return dir
+}
+
+// resolveGlobalRoot returns the user-global store when it should join reads:
+// always with --global (creating it on first use), otherwise when the nearest
+// project config sets include_global and the store already exists.
+func resolveGlobalRoot(projectRoots []string) string {
+	if globalFlag {
+		root, err := store.EnsureGlobal()
+		CheckError(err)
+		return root
+	}
+	if len(projectRoots) == 0 {
+		return ""
+	}
+	cfg, err := config.Load(projectRoots[0])
+	CheckError(err)
+	if !cfg.IncludeGlobal {
+		return ""
+	}
+	root, err := store.GlobalRoot()
+	CheckError(err)
+	if !store.New(root).Exists() {
+		return ""
+	}
+	return root
+}
+
+// openStack opens the read stores for the current scope.
+func openStack() *store.Stack {
+	roots := doryRoots
+	if len(roots) == 0 {
+		roots = []string{doryRoot}
+	}
+
+	outermost := roots[len(roots)-1]
+	if all, err := resolveDoryRoots("."); err == nil {
+		outermost = all[len(all)-1]
+	}
+	globalRoot, _ := store.GlobalRoot()
+
+	layers := make([]store.Layer, 0, len(roots))
+	for _, root := range roots {
+		origin := scopeOrigin(root, outermost)
+		if root == globalRoot {
+			origin = store.GlobalOrigin
+		}
+		layers = append(layers, store.Layer{
+			Origin: origin,
+			Store:  store.New(root),
+		})
+	}
+	return store.NewStack(layers...)
+}
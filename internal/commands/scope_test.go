@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveDoryRootsNearestFirst(t *testing.T) {
+	base := t.TempDir()
+	pkg := filepath.Join(base, "services", "api")
+	for _, dir := range []string{base, pkg} {
+		root := filepath.Join(dir, ".dory")
+		if err := os.MkdirAll(root, 0755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(root, "index.yaml"), []byte("format: doryfile-v1\n"), 0644); err != nil {
+			t.Fatalf("write index: %v", err)
+		}
+	}
+
+	roots, err := resolveDoryRoots(filepath.Join(pkg, "handlers"))
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if len(roots) < 2 || roots[0] != filepath.Join(pkg, ".dory") || roots[1] != filepath.Join(base, ".dory") {
+		t.Fatalf("unexpected roots: %v", roots)
+	}
+
+	if got := scopeOrigin(roots[0], roots[1]); got != "services/api" {
+		t.Fatalf("unexpected package origin %q", got)
+	}
+	if got := scopeOrigin(roots[1], roots[1]); got != "root" {
+		t.Fatalf("unexpected root origin %q", got)
+	}
+}
+
+func TestApplyScope(t *testing.T) {
+	roots := []string{"/repo/pkg/.dory", "/repo/.dory"}
+
+	write, reads := applyScope(roots, "")
+	if write != roots[0] || len(reads) != 2 {
+		t.Fatalf("default scope: write=%s reads=%v", write, reads)
+	}
+	write, reads = applyScope(roots, scopeNearest)
+	if write != roots[0] || len(reads) != 1 || reads[0] != roots[0] {
+		t.Fatalf("nearest scope: write=%s reads=%v", write, reads)
+	}
+	write, reads = applyScope(roots, scopeRoot)
+	if write != roots[1] || len(reads) != 1 || reads[0] != roots[1] {
+		t.Fatalf("root scope: write=%s reads=%v", write, reads)
+	}
+
+	if err := validateScope("global-ish"); err == nil {
+		t.Fatal("expected invalid scope to fail")
+	}
+}
@@ -0,0 +1,157 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sibellavia/dory/internal/models"
+	"github.com/sibellavia/dory/internal/plugin"
+	"github.com/sibellavia/dory/internal/search"
+	"github.com/sibellavia/dory/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search <query...>",
+	Short: "Full-text search over items",
+	Long: `Search oneliners, tags, and bodies of all knowledge items.
+
+Results are ranked by relevance (BM25) and show a snippet with the matched
+words highlighted; JSON and YAML output list them as byte ranges of the
+snippet under highlights. Every word must match.
+
+Query syntax:
+  word          match the word
+  "two words"   match the exact phrase
+  conn*         match words starting with the prefix
+
+Examples:
+  dory search timeout
+  dory search "connection pool" --type lesson
+  dory search migrat* --tag database --limit 5
+
+The search index is kept in .dory/search.idx and updated incrementally.
+
+With --semantic, items are ranked by meaning instead of keywords using the
+enabled plugin that provides embeddings (capabilities.embeddings). Vectors
+are cached in .dory/vectors.json; only new or edited items are re-embedded.
+The query syntax above does not apply in semantic mode.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		RequireStore()
+
+		itemType, _ := cmd.Flags().GetString("type")
+		tag, _ := cmd.Flags().GetString("tag")
+		severityStr, _ := cmd.Flags().GetString("severity")
+		severity := models.Severity(severityStr)
+		limit, _ := cmd.Flags().GetInt("limit")
+		semantic, _ := cmd.Flags().GetBool("semantic")
+		embedTimeout, _ := cmd.Flags().GetDuration("embed-timeout")
+
+		CheckError(validateItemType(itemType))
+		CheckError(validateSeverityFlag(severity))
+		if limit < 0 {
+			CheckError(fmt.Errorf("--limit must be zero or positive"))
+		}
+
+		opts := store.SearchOptions{
+			Query:    strings.Join(args, " "),
+			Type:     itemType,
+			Tag:      tag,
+			Severity: severity,
+			Limit:    limit,
+		}
+
+		s := openStack()
+		defer s.Close()
+
+		var hits []store.SearchHit
+		var err error
+		if semantic {
+			hits, err = s.SemanticSearch(opts, pluginEmbedder(embedTimeout))
+		} else {
+			hits, err = s.Search(opts)
+		}
+		CheckError(err)
+
+		OutputResult(cmd, hits, func() { renderSearchHuman(hits) })
+	},
+}
+
+func init() {
+	searchCmd.Flags().String("type", "", "Filter by type (e.g. lesson, decision, pattern, or plugin custom type)")
+	searchCmd.Flags().StringP("tag", "T", "", "Filter by tag/category")
+	searchCmd.Flags().StringP("severity", "S", "", "Filter by severity: critical, high, normal, low")
+	searchCmd.Flags().Int("limit", 20, "Maximum number of results (0 for all)")
+	searchCmd.Flags().Bool("semantic", false, "Rank by embedding similarity using an embeddings plugin")
+	searchCmd.Flags().Duration("embed-timeout", 60*time.Second, "Timeout for each embedding request")
+	searchCmd.RegisterFlagCompletionFunc("tag", completeTags)
+	RootCmd.AddCommand(searchCmd)
+}
+
+// pluginEmbedder wraps the enabled embeddings plugin as a store embedder.
+func pluginEmbedder(timeout time.Duration) store.Embedder {
+	plugins, _, err := plugin.Discover(doryRoot)
+	CheckError(err)
+	provider, err := plugin.EmbeddingProvider(plugins)
+	CheckError(err)
+
+	name := provider.Name
+	if provider.Version != "" {
+		name += "@" + provider.Version
+	}
+	return store.Embedder{
+		Name: name,
+		Embed: func(texts []string) ([][]float64, error) {
+			return plugin.Embed(provider, texts, timeout)
+		},
+	}
+}
+
+func renderSearchHuman(hits []store.SearchHit) {
+	if len(hits) == 0 {
+		fmt.Println("No matches found")
+		return
+	}
+
+	color := stdoutIsTTY()
+	for _, hit := range hits {
+		originSuffix := ""
+		if hit.Origin != "" {
+			originSuffix = fmt.Sprintf("  (%s)", hit.Origin)
+		}
+		fmt.Printf("%s  %-8s  %s%s\n", hit.ID, hit.Type, hit.Oneliner, originSuffix)
+		if hit.Snippet != "" {
+			fmt.Printf("    %s\n", highlightSnippet(hit.Snippet, hit.Highlights, color))
+		}
+	}
+}
+
+// highlightSnippet bolds the snippet's highlighted spans on a terminal.
+func highlightSnippet(snippet string, spans []search.Span, color bool) string {
+	if !color {
+		return snippet
+	}
+	var b strings.Builder
+	pos := 0
+	for _, span := range spans {
+		if span.Start < pos || span.End > len(snippet) {
+			continue
+		}
+		b.WriteString(snippet[pos:span.Start])
+		b.WriteString("\x1b[1m" + snippet[span.Start:span.End] + "\x1b[0m")
+		pos = span.End
+	}
+	b.WriteString(snippet[pos:])
+	return b.String()
+}
+
+func stdoutIsTTY() bool {
+	info, err := os.Stdout.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -4,17 +4,19 @@ import (
 	"fmt"
 	"sort"
 	"strings"
-	"time"
 
+	"github.com/sibellavia/dory/internal/models"
+	"github.com/sibellavia/dory/internal/query"
 	"github.com/sibellavia/dory/internal/store"
 	"github.com/spf13/cobra"
 )
 
 // Graph types for JSON/YAML output
 type GraphNode struct {
-	ID       string `json:"id" yaml:"id"`
-	Type     string `json:"type" yaml:"type"`
-	Oneliner string `json:"oneliner" yaml:"oneliner"`
+	ID       string          `json:"id" yaml:"id"`
+	Type     string          `json:"type" yaml:"type"`
+	Oneliner string          `json:"oneliner" yaml:"oneliner"`
+	Severity models.Severity `json:"severity,omitempty" yaml:"severity,omitempty"`
 }
 
 type GraphEdge struct {
@@ -43,30 +45,47 @@ Examples:
   dory show D-01JX... --refs              # Content + relationships
   dory show D-01JX... --expand            # Content + connected items
   dory show D-01JX... --expand --depth 2  # Include items 2 hops away
-  dory show D-01JX... --graph             # Visual graph centered on item`,
+  dory show D-01JX... --graph             # Visual graph centered on item
+  dory show D-01JX... --graph --filter 'type:lesson'  # Only lesson neighbors
+
+The ID may be a full ID, an alias (L-42), or any unique prefix; an ambiguous
+prefix lists the matching items.
+
+With nested stores, the item is read from the nearest store that holds it.
+Prefix the ID with an origin to pick a store explicitly (e.g. global:L-01JX...).`,
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		RequireStore()
 
-		id := args[0]
+		ref := args[0]
 		showRefs, _ := cmd.Flags().GetBool("refs")
 		showExpand, _ := cmd.Flags().GetBool("expand")
 		showGraph, _ := cmd.Flags().GetBool("graph")
 		depth, _ := cmd.Flags().GetInt("depth")
+		filter := resolveFilter(cmd)
 
-		s := store.New(doryRoot)
-		defer s.Close()
+		stack := openStack()
+		defer stack.Close()
+
+		// Read from the nearest store that holds the item.
+		layer, id, err := stack.Locate(ref)
+		CheckError(err)
+		s := layer.Store
+		origin := ""
+		if stack.Merged() {
+			origin = layer.Origin
+		}
 
 		// --graph mode: visual graph centered on item
 		if showGraph {
 			format := GetOutputFormat(cmd)
 			if format == "json" || format == "yaml" {
-				result, err := buildGraphData(s, id, depth)
+				result, err := buildGraphData(s, id, depth, filter)
 				CheckError(err)
 				OutputResult(cmd, result, func() {})
 				return
 			}
-			output, err := generateTerminalGraph(s, id, depth)
+			output, err := generateTerminalGraph(s, id, depth, filter)
 			CheckError(err)
 			fmt.Print(output)
 			return
@@ -96,6 +115,9 @@ Examples:
 				"refs_to":       refInfo.RefsTo,
 				"referenced_by": refInfo.ReferencedBy,
 			}
+			if origin != "" {
+				result["origin"] = origin
+			}
 
 			OutputResult(cmd, result, func() {
 				fmt.Print(content)
@@ -119,6 +141,9 @@ Examples:
 			"id":      id,
 			"content": content,
 		}
+		if origin != "" {
+			result["origin"] = origin
+		}
 		if format == "json" || format == "yaml" {
 			OutputResult(cmd, result, func() {})
 			return
@@ -183,12 +208,12 @@ func printExpandedItem(item *store.ExpandedItem, isRoot bool) {
 
 // Graph rendering functions
 
-func generateTerminalGraph(s *store.Store, id string, depth int) (string, error) {
+func generateTerminalGraph(s *store.Store, id string, depth int, filter query.Expr) (string, error) {
 	if depth < 1 {
 		depth = 1
 	}
-	if depth > 1 {
-		result, err := buildGraphData(s, id, depth)
+	if depth > 1 || filter != nil {
+		result, err := buildGraphData(s, id, depth, filter)
 		if err != nil {
 			return "", err
 		}
@@ -442,7 +467,7 @@ func centerText(s string, width int) string {
 }
 
 func generateFullTerminalGraph(s *store.Store) (string, error) {
-	items, err := s.List("", "", "", time.Time{}, time.Time{})
+	items, err := s.List(store.ListFilter{})
 	if err != nil {
 		return "", err
 	}
@@ -548,7 +573,9 @@ func generateFullTerminalGraph(s *store.Store) (string, error) {
 	return sb.String(), nil
 }
 
-func buildGraphData(s *store.Store, center string, depth int) (*GraphResult, error) {
+// buildGraphData collects nodes and edges around center (or the whole store).
+// A non-nil filter keeps only matching nodes; the center is always kept.
+func buildGraphData(s *store.Store, center string, depth int, filter query.Expr) (*GraphResult, error) {
 	if depth < 1 {
 		depth = 1
 	}
@@ -562,7 +589,7 @@ func buildGraphData(s *store.Store, center string, depth int) (*GraphResult, err
 
 	nodeSet := make(map[string]GraphNode)
 	if center == "" {
-		items, err := s.List("", "", "", time.Time{}, time.Time{})
+		items, err := s.List(store.ListFilter{})
 		if err != nil {
 			return nil, err
 		}
@@ -571,6 +598,7 @@ func buildGraphData(s *store.Store, center string, depth int) (*GraphResult, err
 				ID:       item.ID,
 				Type:     item.Type,
 				Oneliner: item.Oneliner,
+				Severity: item.Severity,
 			}
 		}
 	} else {
@@ -592,6 +620,28 @@ func buildGraphData(s *store.Store, center string, depth int) (*GraphResult, err
 		}
 	}
 
+	if center != "" || filter != nil {
+		matching, err := s.List(store.ListFilter{Query: filter})
+		if err != nil {
+			return nil, err
+		}
+		keep := make(map[string]store.ListItem, len(matching))
+		for _, item := range matching {
+			keep[item.ID] = item
+		}
+		for id, node := range nodeSet {
+			item, ok := keep[id]
+			if !ok {
+				if id != center {
+					delete(nodeSet, id)
+				}
+				continue
+			}
+			node.Severity = item.Severity
+			nodeSet[id] = node
+		}
+	}
+
 	nodeIDs := make([]string, 0, len(nodeSet))
 	for id := range nodeSet {
 		nodeIDs = append(nodeIDs, id)
@@ -633,5 +683,7 @@ func init() {
 	showCmd.Flags().Bool("expand", false, "Include full content of connected items")
 	showCmd.Flags().Bool("graph", false, "Visualize connections as a graph")
 	showCmd.Flags().Int("depth", 1, "Depth for --expand/--graph traversal (default: 1)")
+	showCmd.Flags().String("filter", "", "Only include --graph nodes matching a filter expression (see dory query)")
+	showCmd.ValidArgsFunction = completeIDs(1)
 	RootCmd.AddCommand(showCmd)
 }
@@ -0,0 +1,110 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sibellavia/dory/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var similarCmd = &cobra.Command{
+	Use:   "similar <id|text>",
+	Short: "Show items similar to an item or to free text",
+	Long: `Rank existing items by similarity (TF-IDF cosine over oneliners and bodies).
+
+If the argument resolves to an item ID, items similar to that item are shown;
+otherwise the arguments are treated as free text.
+
+Examples:
+  dory similar L-01JX...
+  dory similar "connection pool exhausted under load"
+  dory similar L-42 --limit 3`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		RequireStore()
+
+		limit, _ := cmd.Flags().GetInt("limit")
+		if limit < 0 {
+			CheckError(fmt.Errorf("--limit must be zero or positive"))
+		}
+
+		s := store.New(doryRoot)
+		defer s.Close()
+
+		var items []store.SimilarItem
+		var err error
+		if len(args) == 1 {
+			if id, resolveErr := s.ResolveID(args[0]); resolveErr == nil {
+				items, err = s.SimilarTo(id, limit)
+			} else if _, ambiguous := resolveErr.(*store.AmbiguousIDError); ambiguous {
+				CheckError(resolveErr)
+			} else {
+				items, err = s.Similar(args[0], limit)
+			}
+		} else {
+			items, err = s.Similar(strings.Join(args, " "), limit)
+		}
+		CheckError(err)
+
+		OutputResult(cmd, items, func() { renderSimilarHuman(items) })
+	},
+}
+
+func init() {
+	similarCmd.Flags().Int("limit", 5, "Maximum number of results (0 for all)")
+	similarCmd.ValidArgsFunction = completeIDs(1)
+	RootCmd.AddCommand(similarCmd)
+}
+
+func renderSimilarHuman(items []store.SimilarItem) {
+	if len(items) == 0 {
+		fmt.Println("No similar items found")
+		return
+	}
+	for _, item := range items {
+		fmt.Printf("%.2f  %s  %-8s  %s\n", item.Score, item.ID, item.Type, item.Oneliner)
+	}
+}
+
+// addDuplicateFlags registers the duplicate-detection flags used by create and import.
+func addDuplicateFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("no-duplicates", false, "Refuse to create items similar to an existing item")
+	cmd.Flags().Float64("duplicate-threshold", store.DefaultDuplicateThreshold, "Similarity (0-1) at which an item counts as a duplicate")
+}
+
+// checkDuplicates looks for existing items, and any pending items of the same
+// batch, similar to a new one. With
+// --no-duplicates it exits with an error naming them; otherwise it warns on
+// stderr and returns them so results can report them too.
+func checkDuplicates(cmd *cobra.Command, s *store.Store, oneliner, body string, pending ...store.PendingItem) []store.SimilarItem {
+	threshold, _ := cmd.Flags().GetFloat64("duplicate-threshold")
+	refuse, _ := cmd.Flags().GetBool("no-duplicates")
+	if threshold <= 0 || threshold > 1 {
+		CheckError(fmt.Errorf("--duplicate-threshold must be between 0 and 1"))
+	}
+
+	duplicates, err := s.FindDuplicates(oneliner, body, threshold, pending...)
+	CheckError(err)
+	if len(duplicates) == 0 {
+		return nil
+	}
+
+	best := duplicates[0]
+	if refuse {
+		CheckError(fmt.Errorf("%q duplicates %s (%q, similarity %.2f); edit that item instead or drop --no-duplicates",
+			oneliner, best.ID, best.Oneliner, best.Score))
+	}
+	fmt.Fprintf(os.Stderr, "Warning: %q looks like %s (%q, similarity %.2f); consider editing that item instead\n",
+		oneliner, best.ID, best.Oneliner, best.Score)
+	return duplicates
+}
+
+func duplicateIDs(duplicates []store.SimilarItem) []string {
+	ids := make([]string, 0, len(duplicates))
+	for _, item := range duplicates {
+		ids = append(ids, item.ID)
+	}
+	return ids
+}
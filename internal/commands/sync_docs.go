@@ -0,0 +1,113 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/sibellavia/dory/internal/config"
+	"github.com/sibellavia/dory/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var syncDocsCmd = &cobra.Command{
+	Use:   "sync-docs [files...]",
+	Short: "Regenerate dory-managed regions in CLAUDE.md, AGENTS.md, and other docs",
+	Long: `Regenerate the managed regions of markdown files in place:
+
+  <!-- dory:begin tag=auth -->
+  ...kept current by dory...
+  <!-- dory:end -->
+
+The begin marker selects the content, like the flags of 'dory export':
+  tag=auth                   Items with a tag
+  type=lesson                Items of a type
+  filter="severity>=high"    Items matching a filter expression (see dory query)
+  ids=D-01JX...,L-3          Specific items
+  region=name                A spec under docs.regions in .dory/config.yaml
+  instructions               The dory instructions 'dory init' adds
+
+Without arguments, the files under docs.files in .dory/config.yaml are
+synced ('dory export --append' and 'dory init' register the files they
+write), or CLAUDE.md and AGENTS.md when none are listed. Text outside the
+markers is never changed.
+
+With --check nothing is written, and the command exits 1 when a region is
+out of date, e.g. in CI or a pre-commit hook.
+
+Examples:
+  dory sync-docs
+  dory sync-docs docs/auth.md
+  dory sync-docs --check`,
+	Run: func(cmd *cobra.Command, args []string) {
+		RequireStore()
+		check, _ := cmd.Flags().GetBool("check")
+
+		cfg, err := config.Load(doryRoot)
+		CheckError(err)
+		files := args
+		if len(files) == 0 {
+			files = docsFiles(cfg)
+		}
+
+		s := store.New(doryRoot)
+		defer s.Close()
+
+		results := make([]DocRegionResult, 0)
+		stale := 0
+		for _, file := range files {
+			fileResults, err := syncDocFile(s, cfg, file, check)
+			CheckError(err)
+			for _, r := range fileResults {
+				if r.Status == "stale" {
+					stale++
+				}
+			}
+			results = append(results, fileResults...)
+		}
+
+		OutputResult(cmd, map[string]interface{}{"regions": results, "stale": stale}, func() {
+			if len(results) == 0 {
+				fmt.Println("No dory regions found (add '<!-- dory:begin tag=... -->' ... '<!-- dory:end -->' to a file, or use 'dory export --append')")
+				return
+			}
+			for _, r := range results {
+				fmt.Printf("%-9s %s  %s\n", r.Status, r.File, r.Region)
+			}
+			if stale > 0 {
+				fmt.Printf("\n%d regions out of date; run 'dory sync-docs'\n", stale)
+			}
+		})
+		if stale > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+// docsFiles returns the files sync-docs maintains by default: docs.files
+// from the project config, or whichever of CLAUDE.md and AGENTS.md exist.
+func docsFiles(cfg *config.ProjectConfig) []string {
+	root := projectRoot()
+	var files []string
+	if cfg.Docs != nil && len(cfg.Docs.Files) > 0 {
+		for _, file := range cfg.Docs.Files {
+			if !filepath.IsAbs(file) {
+				file = filepath.Join(root, filepath.FromSlash(file))
+			}
+			files = append(files, file)
+		}
+		return files
+	}
+	for _, name := range []string{"CLAUDE.md", "AGENTS.md"} {
+		path := filepath.Join(root, name)
+		if _, err := os.Stat(path); err == nil {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+func init() {
+	syncDocsCmd.Flags().Bool("check", false, "Report out-of-date regions and exit 1 instead of writing")
+	RootCmd.AddCommand(syncDocsCmd)
+}
@@ -0,0 +1,17 @@
+package commands
+
+import "github.com/spf13/cobra"
+
+var tagCmd = &cobra.Command{
+	Use:   "tag",
+	Short: "Manage tags across the store",
+	Long: `Manage tags across all items.
+
+Examples:
+  dory tag rename auth authentication
+  dory tag rename auth authentication --dry-run`,
+}
+
+func init() {
+	RootCmd.AddCommand(tagCmd)
+}
@@ -0,0 +1,36 @@
+package commands
+
+import (
+	"github.com/sibellavia/dory/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var tagRenameCmd = &cobra.Command{
+	Use:   "rename <old> <new>",
+	Short: "Rename a tag on every item that has it",
+	Long: `Rename a tag across the store in a single atomic batch.
+Tags match case-insensitively. This is a shortcut for:
+
+  dory edit --where 'tag=<old>' --set tag=<new>
+
+Examples:
+  dory tag rename auth authentication
+  dory tag rename auth authentication --dry-run`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		RequireStore()
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		s := store.New(doryRoot)
+		defer s.Close()
+
+		result, err := s.RenameTag(args[0], args[1], dryRun)
+		CheckError(err)
+		printBulkResult(cmd, result)
+	},
+}
+
+func init() {
+	tagRenameCmd.Flags().Bool("dry-run", false, "Preview the items that would be renamed")
+	tagCmd.AddCommand(tagRenameCmd)
+}
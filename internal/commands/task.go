@@ -0,0 +1,22 @@
+package commands
+
+import "github.com/spf13/cobra"
+
+var taskCmd = &cobra.Command{
+	Use:   "task",
+	Short: "Track next-step tasks in session state",
+	Long: `Manage the session's next steps as individual tasks.
+
+Each task has an ID (T-1, T-2, ...) and a status: todo, doing, or done.
+Task commands update a single entry without rewriting the whole list.
+
+Examples:
+  dory task add "Implement logout"
+  dory task start T-3
+  dory task done T-3
+  dory task list`,
+}
+
+func init() {
+	RootCmd.AddCommand(taskCmd)
+}
@@ -0,0 +1,32 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sibellavia/dory/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var taskAddCmd = &cobra.Command{
+	Use:   "add <text>",
+	Short: "Add a todo task",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		RequireStore()
+
+		s := store.New(doryRoot)
+		defer s.Close()
+
+		task, err := s.AddTask(strings.Join(args, " "))
+		CheckError(err)
+
+		OutputResult(cmd, task, func() {
+			fmt.Printf("Added %s: %s\n", task.ID, task.Text)
+		})
+	},
+}
+
+func init() {
+	taskCmd.AddCommand(taskAddCmd)
+}
@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/sibellavia/dory/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var taskListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List session tasks",
+	Run: func(cmd *cobra.Command, args []string) {
+		RequireStore()
+
+		all, _ := cmd.Flags().GetBool("all")
+
+		s := store.New(doryRoot)
+		defer s.Close()
+
+		tasks, err := s.Tasks()
+		CheckError(err)
+
+		filtered := make([]store.Task, 0, len(tasks))
+		for _, task := range tasks {
+			if all || task.Status != "done" {
+				filtered = append(filtered, task)
+			}
+		}
+
+		OutputResult(cmd, filtered, func() {
+			if len(filtered) == 0 {
+				fmt.Println("No tasks found")
+				return
+			}
+			for _, task := range filtered {
+				fmt.Printf("%s %-5s  %s\n", taskStatusMarker(task.Status), task.ID, task.Text)
+			}
+		})
+	},
+}
+
+func taskStatusMarker(status string) string {
+	switch status {
+	case "doing":
+		return "[~]"
+	case "done":
+		return "[x]"
+	default:
+		return "[ ]"
+	}
+}
+
+func init() {
+	taskListCmd.Flags().Bool("all", false, "Include done tasks")
+	taskCmd.AddCommand(taskListCmd)
+}
@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/sibellavia/dory/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var taskStartCmd = &cobra.Command{
+	Use:   "start <id>",
+	Short: "Mark a task as in progress",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		setTaskStatus(cmd, args[0], "doing")
+	},
+}
+
+var taskDoneCmd = &cobra.Command{
+	Use:   "done <id>",
+	Short: "Mark a task as done",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		setTaskStatus(cmd, args[0], "done")
+	},
+}
+
+var taskReopenCmd = &cobra.Command{
+	Use:   "reopen <id>",
+	Short: "Mark a task as todo again",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		setTaskStatus(cmd, args[0], "todo")
+	},
+}
+
+func setTaskStatus(cmd *cobra.Command, id, status string) {
+	RequireStore()
+
+	s := store.New(doryRoot)
+	defer s.Close()
+
+	task, err := s.SetTaskStatus(id, status)
+	CheckError(err)
+
+	OutputResult(cmd, task, func() {
+		fmt.Printf("%s %s: %s\n", taskStatusMarker(task.Status), task.ID, task.Text)
+	})
+}
+
+func init() {
+	taskCmd.AddCommand(taskStartCmd)
+	taskCmd.AddCommand(taskDoneCmd)
+	taskCmd.AddCommand(taskReopenCmd)
+}
@@ -0,0 +1,135 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/sibellavia/dory/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var tuiCmd = &cobra.Command{
+	Use:   "tui",
+	Short: "Browse and curate items in a full-screen terminal UI",
+	Long: `Open a full-screen browser over the nearest store: a filterable item
+list, a markdown preview of the selected item, numbered ref and
+referenced-by links to follow, and inline edits.
+
+Edits, deletes, and restores go through the same store writes and plugin
+hooks as the CLI commands. The UI uses plain ANSI escapes and stty, so it
+works in any Unix terminal, including over SSH.
+
+Keys:
+  j/k, arrows   move              /      filter (query syntax)
+  1-9           follow a ref      b      back
+  s             set severity      t      set tag
+  p             pin/unpin         d      delete (u undoes)
+  D             deleted items     r      restore (in deleted view)
+  ?             help              q      quit`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		RequireStore()
+		if !stdoutIsTTY() || !stdinIsTTY() {
+			CheckError(fmt.Errorf("dory tui needs an interactive terminal"))
+		}
+
+		s := store.New(doryRoot)
+		defer s.Close()
+		CheckError(runTUI(newTUI(s)))
+	},
+}
+
+// runTUI takes over the terminal until the user quits, restoring it on exit.
+func runTUI(t *tui) error {
+	saved, err := stty("-g")
+	if err != nil {
+		return fmt.Errorf("dory tui needs a Unix terminal with stty: %w", err)
+	}
+	// Reads time out every half second so resizes are picked up.
+	if _, err := stty("raw", "-echo", "min", "0", "time", "5"); err != nil {
+		return err
+	}
+	restore := func() {
+		fmt.Print("\x1b[?25h\x1b[?1049l")
+		stty(strings.TrimSpace(saved))
+	}
+	defer restore()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(signals)
+	go func() {
+		if _, ok := <-signals; ok {
+			restore()
+			os.Exit(1)
+		}
+	}()
+
+	fmt.Print("\x1b[?1049h\x1b[?25l")
+	buf := make([]byte, 256)
+	lastWidth, lastHeight := 0, 0
+	dirty := true
+	for !t.quit {
+		width, height := terminalSize()
+		resized := width != lastWidth || height != lastHeight
+		if dirty || resized {
+			frame := "\x1b[H" + strings.Join(t.render(width, height), "\r\n")
+			if resized {
+				frame = "\x1b[2J" + frame
+			}
+			fmt.Print(frame)
+			lastWidth, lastHeight, dirty = width, height, false
+		}
+
+		n, err := os.Stdin.Read(buf)
+		if err != nil && err != io.EOF {
+			return err
+		}
+		for _, key := range parseKeys(buf[:n]) {
+			t.handleKey(key)
+			dirty = true
+		}
+	}
+	return nil
+}
+
+// stty runs stty against the controlling terminal.
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	return string(out), err
+}
+
+// terminalSize returns the terminal width and height, defaulting to 80x24.
+func terminalSize() (int, int) {
+	out, err := stty("size")
+	if err == nil {
+		if fields := strings.Fields(out); len(fields) == 2 {
+			rows, rowErr := strconv.Atoi(fields[0])
+			cols, colErr := strconv.Atoi(fields[1])
+			if rowErr == nil && colErr == nil && rows > 0 && cols > 0 {
+				return cols, rows
+			}
+		}
+	}
+	return 80, 24
+}
+
+func stdinIsTTY() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+func init() {
+	RootCmd.AddCommand(tuiCmd)
+}
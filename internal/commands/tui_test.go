@@ -0,0 +1,106 @@
+package commands
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sibellavia/dory/internal/models"
+	"github.com/sibellavia/dory/internal/store"
+)
+
+func TestParseKeys(t *testing.T) {
+	keys := parseKeys([]byte("j\x1b[A\x1b[6~\x1b\r\x7fé\x03"))
+	var got []string
+	for _, key := range keys {
+		if key.name != "" {
+			got = append(got, key.name)
+		} else {
+			got = append(got, string(key.r))
+		}
+	}
+	want := "j up pgdn esc enter backspace é ctrl-c"
+	if strings.Join(got, " ") != want {
+		t.Fatalf("expected %q, got %q", want, strings.Join(got, " "))
+	}
+}
+
+func TestWrapTextAndFit(t *testing.T) {
+	lines := wrapText("the quick brown fox jumps over", 10)
+	if strings.Join(lines, "|") != "the quick|brown fox|jumps over" {
+		t.Fatalf("unexpected wrap: %q", lines)
+	}
+	if lines := wrapText("abcdefghijkl", 5); strings.Join(lines, "|") != "abcde|fghij|kl" {
+		t.Fatalf("expected long words to split, got %q", lines)
+	}
+	if got := fit("abc", 5); got != "abc  " {
+		t.Fatalf("expected padding, got %q", got)
+	}
+	if got := fit("abcdef", 4); got != "abc…" {
+		t.Fatalf("expected truncation, got %q", got)
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	lines := renderMarkdown("# Title\n\n- **bold** item\n```\ncode here\n```\n> quoted", 40)
+	var texts []string
+	for _, line := range lines {
+		texts = append(texts, line.text)
+	}
+	want := "Title||• bold item|  code here|│ quoted"
+	if strings.Join(texts, "|") != want {
+		t.Fatalf("expected %q, got %q", want, strings.Join(texts, "|"))
+	}
+	if lines[0].style != styleBold || lines[3].style != styleDim {
+		t.Fatalf("unexpected styles: %+v", lines)
+	}
+}
+
+func TestTUIFollowsRefsAndEdits(t *testing.T) {
+	root := filepath.Join(t.TempDir(), ".dory")
+	s := store.New(root)
+	if err := s.Init("project", ""); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	defer s.Close()
+	target, err := s.Learn("Target", "api", models.SeverityNormal, "", nil)
+	if err != nil {
+		t.Fatalf("learn: %v", err)
+	}
+	source, err := s.Learn("Source", "api", models.SeverityNormal, "", []string{target})
+	if err != nil {
+		t.Fatalf("learn: %v", err)
+	}
+
+	view := newTUI(s)
+	if view.selectedID() != target {
+		t.Fatalf("expected %s selected first, got %s", target, view.selectedID())
+	}
+	for _, key := range parseKeys([]byte("/Source\r")) {
+		view.handleKey(key)
+	}
+	if len(view.items) != 1 || view.selectedID() != source {
+		t.Fatalf("expected filter to select %s, got %+v", source, view.items)
+	}
+
+	view.render(100, 20)
+	view.handleKey(tuiKey{r: '1'})
+	if view.selectedID() != target || view.filter != "" {
+		t.Fatalf("expected ref jump to %s with filter cleared, got %s %q", target, view.selectedID(), view.filter)
+	}
+	view.handleKey(tuiKey{r: 'b'})
+	if view.selectedID() != source {
+		t.Fatalf("expected back to return to %s, got %s", source, view.selectedID())
+	}
+
+	for _, key := range parseKeys([]byte("t\x7f\x7f\x7fgateway\r")) {
+		view.handleKey(key)
+	}
+	entry, err := s.GetEntry(source)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if entry.Topic != "gateway" || len(entry.Refs) != 1 {
+		t.Fatalf("expected tag edit to keep refs, got %+v", entry)
+	}
+}
@@ -0,0 +1,825 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sibellavia/dory/internal/doryfile"
+	"github.com/sibellavia/dory/internal/models"
+	"github.com/sibellavia/dory/internal/plugin"
+	"github.com/sibellavia/dory/internal/query"
+	"github.com/sibellavia/dory/internal/store"
+)
+
+// ANSI styles used by the terminal UI.
+const (
+	styleReset   = "\x1b[0m"
+	styleBold    = "\x1b[1m"
+	styleDim     = "\x1b[2m"
+	styleReverse = "\x1b[7m"
+)
+
+type tuiMode int
+
+const (
+	tuiBrowse tuiMode = iota
+	tuiInput
+	tuiConfirm
+)
+
+// tuiKey is a decoded keypress: a printable rune or a named key.
+type tuiKey struct {
+	r    rune
+	name string
+}
+
+// styledLine is one screen line with a style applied to the whole line.
+type styledLine struct {
+	text  string
+	style string
+}
+
+// tui is the state of the terminal browser. Reads and writes go through
+// the nearest store.
+type tui struct {
+	s *store.Store
+
+	items       []store.ListItem
+	showDeleted bool
+	filter      string
+	cursor      int
+	offset      int
+	scroll      int
+	history     []string
+	links       []string
+	lastDeleted string
+	help        bool
+
+	mode     tuiMode
+	prompt   string
+	input    string
+	onSubmit func(value string)
+	onKey    func(key tuiKey)
+
+	status string
+	quit   bool
+
+	entries map[string]*doryfile.Entry
+	refBy   map[string][]store.ListItem
+}
+
+func newTUI(s *store.Store) *tui {
+	t := &tui{s: s}
+	t.reload("")
+	return t
+}
+
+// reload refreshes the item list and drops cached previews, keeping the
+// cursor on keepID when it is still listed.
+func (t *tui) reload(keepID string) {
+	t.entries = make(map[string]*doryfile.Entry)
+	t.refBy = make(map[string][]store.ListItem)
+
+	var items []store.ListItem
+	var err error
+	if t.showDeleted {
+		items, err = t.s.Deleted()
+	} else {
+		var expr query.Expr
+		if strings.TrimSpace(t.filter) != "" {
+			expr, err = query.Parse(t.filter)
+		}
+		if err == nil {
+			items, err = t.s.List(store.ListFilter{Query: expr})
+		}
+	}
+	if err != nil {
+		t.status = "Error: " + err.Error()
+		return
+	}
+	t.items = items
+	t.cursor = min(t.cursor, max(len(t.items)-1, 0))
+	for i, item := range t.items {
+		if item.ID == keepID {
+			t.cursor = i
+		}
+	}
+	t.scroll = 0
+}
+
+func (t *tui) selected() *store.ListItem {
+	if t.cursor < 0 || t.cursor >= len(t.items) {
+		return nil
+	}
+	return &t.items[t.cursor]
+}
+
+func (t *tui) entry(id string) *doryfile.Entry {
+	if entry, ok := t.entries[id]; ok {
+		return entry
+	}
+	entry, err := t.s.GetEntry(id)
+	if err != nil {
+		entry = nil
+	}
+	t.entries[id] = entry
+	return entry
+}
+
+func (t *tui) referencedBy(id string) []store.ListItem {
+	if items, ok := t.refBy[id]; ok {
+		return items
+	}
+	items, _ := t.s.ReferencedBy(id)
+	t.refBy[id] = items
+	return items
+}
+
+func (t *tui) move(delta int) {
+	if len(t.items) == 0 {
+		return
+	}
+	t.cursor = min(max(t.cursor+delta, 0), len(t.items)-1)
+	t.scroll = 0
+}
+
+func (t *tui) handleKey(key tuiKey) {
+	switch t.mode {
+	case tuiInput:
+		t.handleInput(key)
+		return
+	case tuiConfirm:
+		t.mode = tuiBrowse
+		t.onKey(key)
+		return
+	}
+
+	t.status = ""
+	switch {
+	case key.name == "ctrl-c" || key.r == 'q':
+		t.quit = true
+	case key.name == "down" || key.r == 'j':
+		t.move(1)
+	case key.name == "up" || key.r == 'k':
+		t.move(-1)
+	case key.name == "pgdn":
+		t.move(10)
+	case key.name == "pgup":
+		t.move(-10)
+	case key.name == "home" || key.r == 'g':
+		t.move(-len(t.items))
+	case key.name == "end" || key.r == 'G':
+		t.move(len(t.items))
+	case key.r == 'J':
+		t.scroll++
+	case key.r == 'K':
+		t.scroll = max(t.scroll-1, 0)
+	case key.r == '?':
+		t.help = !t.help
+	case key.r == '/':
+		t.startInput("Filter: ", t.filter, func(value string) {
+			t.filter = value
+			t.reload(t.selectedID())
+		})
+	case key.name == "esc":
+		if t.filter != "" {
+			t.filter = ""
+			t.reload(t.selectedID())
+		}
+	case key.r >= '1' && key.r <= '9':
+		t.follow(int(key.r - '1'))
+	case key.r == 'b' || key.name == "backspace" || key.name == "left":
+		t.back()
+	case key.r == 'D':
+		t.showDeleted = !t.showDeleted
+		t.cursor = 0
+		t.reload("")
+	case t.showDeleted && key.r == 'r':
+		if item := t.selected(); item != nil {
+			t.restore(*item)
+		}
+	case key.r == 'u':
+		t.undo()
+	case t.showDeleted:
+		// Edits only apply to live items.
+	case key.r == 's':
+		t.editSeverity()
+	case key.r == 't':
+		t.editTag()
+	case key.r == 'p':
+		t.togglePin()
+	case key.r == 'd':
+		t.confirmDelete()
+	}
+}
+
+func (t *tui) handleInput(key tuiKey) {
+	switch key.name {
+	case "enter":
+		t.mode = tuiBrowse
+		t.onSubmit(strings.TrimSpace(t.input))
+	case "esc", "ctrl-c":
+		t.mode = tuiBrowse
+	case "backspace":
+		if _, size := utf8.DecodeLastRuneInString(t.input); size > 0 {
+			t.input = t.input[:len(t.input)-size]
+		}
+	default:
+		if key.r != 0 {
+			t.input += string(key.r)
+		}
+	}
+}
+
+func (t *tui) startInput(prompt, value string, onSubmit func(string)) {
+	t.mode = tuiInput
+	t.prompt = prompt
+	t.input = value
+	t.onSubmit = onSubmit
+}
+
+func (t *tui) selectedID() string {
+	if item := t.selected(); item != nil {
+		return item.ID
+	}
+	return ""
+}
+
+// follow jumps to the numbered ref or referencing item of the selection.
+func (t *tui) follow(n int) {
+	if n >= len(t.links) {
+		return
+	}
+	current := t.selectedID()
+	if !t.jump(t.links[n]) {
+		return
+	}
+	t.history = append(t.history, current)
+}
+
+func (t *tui) back() {
+	if len(t.history) == 0 {
+		return
+	}
+	id := t.history[len(t.history)-1]
+	t.history = t.history[:len(t.history)-1]
+	t.jump(id)
+}
+
+// jump moves the cursor to id, clearing the filter when it hides the item.
+func (t *tui) jump(id string) bool {
+	for i, item := range t.items {
+		if item.ID == id {
+			t.cursor, t.scroll = i, 0
+			return true
+		}
+	}
+	if t.showDeleted || t.filter != "" {
+		t.showDeleted, t.filter = false, ""
+		t.reload(id)
+		if t.selectedID() == id {
+			return true
+		}
+	}
+	t.status = id + " is not a live item"
+	return false
+}
+
+func (t *tui) editSeverity() {
+	item := t.selected()
+	if item == nil {
+		return
+	}
+	if item.Type != "lesson" {
+		t.status = "Severity only applies to lessons"
+		return
+	}
+	id := item.ID
+	t.startInput("Severity (critical, high, normal, low): ", string(item.Severity), func(value string) {
+		if err := validateSeverityFlag(models.Severity(value)); err != nil || value == "" {
+			t.status = fmt.Sprintf("Invalid severity %q", value)
+			return
+		}
+		t.applyEdit(id, store.BulkEdit{Severity: value}, "severity "+value)
+	})
+}
+
+func (t *tui) editTag() {
+	item := t.selected()
+	if item == nil {
+		return
+	}
+	id := item.ID
+	current := item.Topic
+	if current == "" {
+		current = item.Domain
+	}
+	t.startInput("Tag: ", current, func(value string) {
+		if value == "" {
+			t.status = "Tag must not be empty"
+			return
+		}
+		t.applyEdit(id, store.BulkEdit{Tag: value}, "tag "+value)
+	})
+}
+
+func (t *tui) togglePin() {
+	item := t.selected()
+	if item == nil {
+		return
+	}
+	pinned := !item.Pinned
+	t.applyEdit(item.ID, store.BulkEdit{Pinned: &pinned}, fmt.Sprintf("pinned %t", pinned))
+}
+
+// applyEdit writes a change to one item through the store's batch path.
+func (t *tui) applyEdit(id string, edit store.BulkEdit, what string) {
+	filter, err := query.Parse("id=" + id)
+	if err == nil {
+		_, err = t.s.BulkUpdate(filter, edit, false)
+	}
+	if err != nil {
+		t.status = "Error: " + err.Error()
+		return
+	}
+	t.reload(id)
+	t.status = fmt.Sprintf("Set %s on %s", what, id)
+}
+
+func (t *tui) confirmDelete() {
+	item := t.selected()
+	if item == nil {
+		return
+	}
+	id := item.ID
+	referencing := t.referencedBy(id)
+	t.mode = tuiConfirm
+	if len(referencing) == 0 {
+		t.prompt = fmt.Sprintf("Delete %s? [y/N] ", id)
+	} else {
+		t.prompt = fmt.Sprintf("Delete %s? %d items reference it: [c] delete and unlink, [y] delete and keep refs, [N] cancel ", id, len(referencing))
+	}
+	t.onKey = func(key tuiKey) {
+		switch {
+		case key.r == 'y' || key.r == 'Y':
+			t.remove(id, referencing, false)
+		case len(referencing) > 0 && (key.r == 'c' || key.r == 'C'):
+			t.remove(id, referencing, true)
+		default:
+			t.status = "Aborted"
+		}
+	}
+}
+
+func (t *tui) remove(id string, referencing []store.ListItem, cascade bool) {
+	referencingIDs := make([]string, 0, len(referencing))
+	for _, item := range referencing {
+		referencingIDs = append(referencingIDs, item.ID)
+	}
+	if !t.runHooks(plugin.HookBeforeRemove, map[string]interface{}{
+		"id":             id,
+		"referenced_by":  referencingIDs,
+		"cascade_unlink": cascade,
+	}) {
+		return
+	}
+
+	var unlinked []string
+	var err error
+	if cascade {
+		unlinked, err = t.s.RemoveUnlinking(id)
+	} else {
+		err = t.s.Remove(id)
+	}
+	if err != nil {
+		t.status = "Error: " + err.Error()
+		return
+	}
+	t.runHooks(plugin.HookAfterRemove, map[string]interface{}{
+		"id":       id,
+		"unlinked": unlinked,
+	})
+
+	t.lastDeleted = id
+	t.reload("")
+	if t.status == "" {
+		t.status = fmt.Sprintf("Deleted %s (u to undo)", id)
+	}
+}
+
+func (t *tui) undo() {
+	if t.lastDeleted == "" {
+		t.status = "Nothing to undo"
+		return
+	}
+	for _, item := range t.deletedItems() {
+		if item.ID == t.lastDeleted {
+			t.restore(item)
+			return
+		}
+	}
+	t.status = t.lastDeleted + " can no longer be restored"
+	t.lastDeleted = ""
+}
+
+func (t *tui) deletedItems() []store.ListItem {
+	items, err := t.s.Deleted()
+	if err != nil {
+		t.status = "Error: " + err.Error()
+	}
+	return items
+}
+
+// restore brings a deleted item back, running the create hooks around it.
+func (t *tui) restore(item store.ListItem) {
+	context := map[string]interface{}{
+		"type":     item.Type,
+		"oneliner": item.Oneliner,
+		"topic":    item.Topic,
+		"severity": string(item.Severity),
+		"restored": true,
+	}
+	if !t.runHooks(plugin.HookBeforeCreate, context) {
+		return
+	}
+	id, err := t.s.Restore(item.ID)
+	if err != nil {
+		t.status = "Error: " + err.Error()
+		return
+	}
+	context["id"] = id
+	t.runHooks(plugin.HookAfterCreate, context)
+
+	if t.lastDeleted == id {
+		t.lastDeleted = ""
+	}
+	t.reload(id)
+	if t.status == "" {
+		t.status = "Restored " + id
+	}
+}
+
+// runHooks runs plugin hooks and reports problems in the status line
+// instead of exiting. It returns false when a hook blocked the action.
+func (t *tui) runHooks(event plugin.HookEvent, context map[string]interface{}) bool {
+	results, err := plugin.RunHooks(doryRoot, event, context, defaultHookTimeout)
+	for _, result := range results {
+		if (result.Status == "warning" || result.Status == "error") && result.Error != "" {
+			t.status = fmt.Sprintf("Hook %s (%s): %s", result.Plugin, result.Event, result.Error)
+		}
+	}
+	if err != nil {
+		t.status = "Error: " + err.Error()
+		return false
+	}
+	return true
+}
+
+// render draws the screen as width x height lines.
+func (t *tui) render(width, height int) []string {
+	if width < 20 || height < 5 {
+		return []string{fit("Terminal too small", width)}
+	}
+	lines := make([]string, 0, height)
+
+	title := fmt.Sprintf(" dory  %d items", len(t.items))
+	if t.showDeleted {
+		title = fmt.Sprintf(" dory  %d deleted items", len(t.items))
+	}
+	if t.filter != "" && !t.showDeleted {
+		title += "  filter: " + t.filter
+	}
+	lines = append(lines, styleReverse+fit(title, width)+styleReset)
+
+	bodyHeight := height - 2
+	listWidth := min(max(width*2/5, 24), width-10)
+	previewWidth := width - listWidth - 1
+
+	if t.cursor < t.offset {
+		t.offset = t.cursor
+	}
+	if t.cursor >= t.offset+bodyHeight {
+		t.offset = t.cursor - bodyHeight + 1
+	}
+
+	preview := t.previewLines(previewWidth)
+	if t.help {
+		preview = tuiHelp()
+	}
+	t.scroll = min(t.scroll, max(len(preview)-bodyHeight, 0))
+	preview = preview[t.scroll:]
+
+	for row := 0; row < bodyHeight; row++ {
+		left := strings.Repeat(" ", listWidth)
+		if i := t.offset + row; i < len(t.items) {
+			text := fit(listRow(t.items[i]), listWidth)
+			if i == t.cursor {
+				left = styleReverse + text + styleReset
+			} else {
+				left = text
+			}
+		}
+		right := strings.Repeat(" ", previewWidth)
+		if row < len(preview) {
+			right = preview[row].style + fit(preview[row].text, previewWidth) + styleReset
+		}
+		lines = append(lines, left+styleDim+"│"+styleReset+right)
+	}
+
+	switch t.mode {
+	case tuiInput:
+		lines = append(lines, fit(t.prompt+t.input+"_", width))
+	case tuiConfirm:
+		lines = append(lines, styleBold+fit(t.prompt, width)+styleReset)
+	default:
+		status := t.status
+		if status == "" {
+			status = "/ filter  1-9 follow ref  b back  s severity  t tag  p pin  d delete  D deleted  ? help  q quit"
+		}
+		lines = append(lines, styleDim+fit(status, width)+styleReset)
+	}
+	return lines
+}
+
+func listRow(item store.ListItem) string {
+	mark := "  "
+	switch item.Severity {
+	case models.SeverityCritical:
+		mark = "!!"
+	case models.SeverityHigh:
+		mark = "! "
+	}
+	pin := " "
+	if item.Pinned {
+		pin = "*"
+	}
+	id := item.Alias
+	if id == "" {
+		id = item.ID
+		if len(id) > 12 {
+			id = id[:12]
+		}
+	}
+	return fmt.Sprintf("%s%s %-12s %s", pin, mark, id, item.Oneliner)
+}
+
+// previewLines renders the selected item and numbers its links.
+func (t *tui) previewLines(width int) []styledLine {
+	t.links = nil
+	item := t.selected()
+	if item == nil {
+		return []styledLine{{text: "No items"}}
+	}
+
+	var lines []styledLine
+	for _, text := range wrapText(item.Oneliner, width) {
+		lines = append(lines, styledLine{text: text, style: styleBold})
+	}
+	meta := []string{item.ID, item.Type}
+	if tag := item.Topic + item.Domain; tag != "" {
+		meta = append(meta, "#"+tag)
+	}
+	if item.Severity != "" {
+		meta = append(meta, string(item.Severity))
+	}
+	if item.Pinned {
+		meta = append(meta, "pinned")
+	}
+	meta = append(meta, item.Created)
+	lines = append(lines, styledLine{text: strings.Join(meta, "  "), style: styleDim}, styledLine{})
+
+	entry := t.entry(item.ID)
+	if t.showDeleted || entry == nil {
+		lines = append(lines, styledLine{text: "(deleted: press r to restore)", style: styleDim})
+		return lines
+	}
+
+	addLink := func(id, label string) {
+		t.links = append(t.links, id)
+		n := len(t.links)
+		text := fmt.Sprintf("  [%d] %s", n, label)
+		if n > 9 {
+			text = "      " + label
+		}
+		lines = append(lines, styledLine{text: text})
+	}
+	if len(entry.Refs) > 0 {
+		lines = append(lines, styledLine{text: "Refs:", style: styleDim})
+		for _, ref := range entry.Refs {
+			label := ref
+			if target := t.entry(ref); target != nil {
+				label += "  " + target.Oneliner
+			}
+			addLink(ref, label)
+		}
+	}
+	if referencing := t.referencedBy(item.ID); len(referencing) > 0 {
+		lines = append(lines, styledLine{text: "Referenced by:", style: styleDim})
+		for _, ref := range referencing {
+			addLink(ref.ID, ref.ID+"  "+ref.Oneliner)
+		}
+	}
+	if len(t.links) > 0 {
+		lines = append(lines, styledLine{})
+	}
+
+	return append(lines, renderMarkdown(entry.Body, width)...)
+}
+
+func tuiHelp() []styledLine {
+	lines := []styledLine{{text: "Keys", style: styleBold}}
+	for _, text := range []string{
+		"j/k, arrows   move",
+		"PgUp/PgDn     move by page",
+		"g/G           first/last item",
+		"J/K           scroll preview",
+		"/             filter (query syntax, e.g. tag:auth AND severity>=high)",
+		"Esc           clear filter",
+		"1-9           follow a numbered ref",
+		"b, Left       back",
+		"s             set severity (lessons)",
+		"t             set tag",
+		"p             pin/unpin",
+		"d             delete",
+		"u             undo last delete",
+		"D             show deleted items (r restores)",
+		"?             toggle help",
+		"q             quit",
+	} {
+		lines = append(lines, styledLine{text: "  " + text})
+	}
+	return lines
+}
+
+// renderMarkdown turns a markdown body into wrapped, styled lines.
+func renderMarkdown(body string, width int) []styledLine {
+	var lines []styledLine
+	inFence := false
+	for _, raw := range strings.Split(strings.TrimRight(body, "\n"), "\n") {
+		trimmed := strings.TrimSpace(raw)
+		if strings.HasPrefix(trimmed, "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			lines = append(lines, styledLine{text: "  " + raw, style: styleDim})
+			continue
+		}
+
+		text, style, indent := stripInline(trimmed), "", ""
+		switch {
+		case strings.HasPrefix(trimmed, "#"):
+			text = stripInline(strings.TrimSpace(strings.TrimLeft(trimmed, "#")))
+			style = styleBold
+		case strings.HasPrefix(trimmed, "- ") || strings.HasPrefix(trimmed, "* "):
+			text = "• " + stripInline(trimmed[2:])
+			indent = "  "
+		case strings.HasPrefix(trimmed, ">"):
+			text = "│ " + stripInline(strings.TrimSpace(trimmed[1:]))
+			style = styleDim
+		}
+		if text == "" {
+			lines = append(lines, styledLine{})
+			continue
+		}
+		for i, part := range wrapText(text, width-len(indent)) {
+			if i > 0 {
+				part = indent + part
+			}
+			lines = append(lines, styledLine{text: part, style: style})
+		}
+	}
+	return lines
+}
+
+func stripInline(text string) string {
+	return strings.NewReplacer("**", "", "__", "", "`", "").Replace(text)
+}
+
+// wrapText breaks text into lines of at most width runes at spaces,
+// splitting words that are longer than a line.
+func wrapText(text string, width int) []string {
+	if width < 1 {
+		return nil
+	}
+	var lines []string
+	var line []rune
+	for _, word := range strings.Fields(text) {
+		w := []rune(word)
+		for len(w) > width {
+			if len(line) > 0 {
+				lines = append(lines, string(line))
+				line = nil
+			}
+			lines = append(lines, string(w[:width]))
+			w = w[width:]
+		}
+		switch {
+		case len(line) == 0:
+			line = w
+		case len(line)+1+len(w) <= width:
+			line = append(append(line, ' '), w...)
+		default:
+			lines = append(lines, string(line))
+			line = w
+		}
+	}
+	if len(line) > 0 {
+		lines = append(lines, string(line))
+	}
+	return lines
+}
+
+// fit pads or truncates text to exactly width runes.
+func fit(text string, width int) string {
+	runes := []rune(text)
+	if len(runes) > width {
+		if width <= 1 {
+			return string(runes[:width])
+		}
+		return string(runes[:width-1]) + "…"
+	}
+	return text + strings.Repeat(" ", width-len(runes))
+}
+
+// parseKeys decodes raw terminal input into keypresses.
+func parseKeys(b []byte) []tuiKey {
+	var keys []tuiKey
+	for len(b) > 0 {
+		if b[0] == 0x1b {
+			if len(b) >= 3 && (b[1] == '[' || b[1] == 'O') {
+				name, size := escapeKey(b)
+				if name != "" {
+					keys = append(keys, tuiKey{name: name})
+				}
+				b = b[size:]
+				continue
+			}
+			keys = append(keys, tuiKey{name: "esc"})
+			b = b[1:]
+			continue
+		}
+		switch b[0] {
+		case 3:
+			keys = append(keys, tuiKey{name: "ctrl-c"})
+		case '\r', '\n':
+			keys = append(keys, tuiKey{name: "enter"})
+		case 127, 8:
+			keys = append(keys, tuiKey{name: "backspace"})
+		case '\t':
+			keys = append(keys, tuiKey{name: "tab"})
+		default:
+			r, size := utf8.DecodeRune(b)
+			if r >= ' ' && r != utf8.RuneError {
+				keys = append(keys, tuiKey{r: r})
+			}
+			b = b[size:]
+			continue
+		}
+		b = b[1:]
+	}
+	return keys
+}
+
+// escapeKey decodes a CSI or SS3 sequence at the start of b.
+func escapeKey(b []byte) (string, int) {
+	end := 2
+	for end < len(b) && (b[end] < 0x40 || b[end] > 0x7e) {
+		end++
+	}
+	if end == len(b) {
+		return "", len(b)
+	}
+	seq := string(b[2:end])
+	final := b[end]
+	size := end + 1
+	switch final {
+	case 'A':
+		return "up", size
+	case 'B':
+		return "down", size
+	case 'C':
+		return "right", size
+	case 'D':
+		return "left", size
+	case 'H':
+		return "home", size
+	case 'F':
+		return "end", size
+	case '~':
+		switch seq {
+		case "1", "7":
+			return "home", size
+		case "4", "8":
+			return "end", size
+		case "5":
+			return "pgup", size
+		case "6":
+			return "pgdn", size
+		case "3":
+			return "delete", size
+		}
+	}
+	return "", size
+}
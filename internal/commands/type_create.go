@@ -83,6 +83,7 @@ var typeCreateCmd = &cobra.Command{
 
 		s := store.New(doryRoot)
 		defer s.Close()
+		applyRefPolicy(cmd, s)
 
 		id, err := s.CreateCustom(itemType, oneliner, topic, body, refs)
 		CheckError(err)
@@ -121,7 +122,11 @@ func init() {
 	typeCreateCmd.Flags().StringP("topic", "t", "", "Alias for --tag (deprecated)")
 	typeCreateCmd.Flags().StringP("body", "b", "", "Full markdown body content (use - to read from stdin)")
 	typeCreateCmd.Flags().StringSliceP("refs", "R", []string{}, "References to other items (comma-separated, e.g., L-abc123,D-def456)")
+	addRefPolicyFlag(typeCreateCmd)
 	typeCreateCmd.Flags().Duration("validate-timeout", 2*time.Second, "Custom type validation timeout")
+	typeCreateCmd.ValidArgsFunction = completeCustomTypes
+	typeCreateCmd.RegisterFlagCompletionFunc("tag", completeTags)
+	typeCreateCmd.RegisterFlagCompletionFunc("refs", completeRefs)
 	typeCreateCmd.Flags().MarkHidden("topic")
 	typeCmd.AddCommand(typeCreateCmd)
 }
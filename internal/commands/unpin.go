@@ -0,0 +1,21 @@
+package commands
+
+import "github.com/spf13/cobra"
+
+var unpinCmd = &cobra.Command{
+	Use:   "unpin <id>",
+	Short: "Unpin an item",
+	Long: `Unpin an item so it appears in context only by severity or recency again.
+
+The ID may be a full ID, an alias (L-42), or any unique prefix.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		RequireStore()
+		setPinned(cmd, args[0], false)
+	},
+}
+
+func init() {
+	unpinCmd.ValidArgsFunction = completeIDs(1)
+	RootCmd.AddCommand(unpinCmd)
+}
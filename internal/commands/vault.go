@@ -0,0 +1,364 @@
+package commands
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/sibellavia/dory/internal/doryfile"
+	"gopkg.in/yaml.v3"
+)
+
+// vaultFront is the frontmatter of a vault note. Hash is the content hash
+// at the last export or import, which tells vault edits and dory edits apart.
+type vaultFront struct {
+	ID       string    `yaml:"id,omitempty"`
+	Type     string    `yaml:"type,omitempty"`
+	Oneliner string    `yaml:"oneliner,omitempty"`
+	Tags     vaultTags `yaml:"tags,omitempty"`
+	Severity string    `yaml:"severity,omitempty"`
+	Pinned   bool      `yaml:"pinned,omitempty"`
+	Alias    string    `yaml:"alias,omitempty"`
+	Created  string    `yaml:"created,omitempty"`
+	Refs     []string  `yaml:"refs,omitempty"`
+	Hash     string    `yaml:"dory-hash,omitempty"`
+}
+
+// vaultKeys are the frontmatter keys dory owns. Other keys in a note, such
+// as aliases or cssclasses, belong to the user and are kept on rewrite.
+var vaultKeys = func() map[string]bool {
+	keys := make(map[string]bool)
+	t := reflect.TypeOf(vaultFront{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		keys[name] = true
+	}
+	return keys
+}()
+
+// vaultTags accepts tags as a list or a single string, with or without '#'.
+type vaultTags []string
+
+func (t *vaultTags) UnmarshalYAML(node *yaml.Node) error {
+	var tags []string
+	if node.Kind == yaml.ScalarNode {
+		tags = strings.FieldsFunc(node.Value, func(r rune) bool { return r == ',' || r == ' ' })
+	} else if err := node.Decode(&tags); err != nil {
+		return err
+	}
+	*t = nil
+	for _, tag := range tags {
+		if tag = strings.TrimPrefix(strings.TrimSpace(tag), "#"); tag != "" {
+			*t = append(*t, tag)
+		}
+	}
+	return nil
+}
+
+// vaultNote is one markdown file in a vault that belongs to dory.
+type vaultNote struct {
+	Path  string
+	Front vaultFront
+	Raw   *yaml.Node // frontmatter mapping, including keys dory does not use
+	Body  string
+	Err   error
+}
+
+// tag is the note's dory tag: its first tag.
+func (n *vaultNote) tag() string {
+	if len(n.Front.Tags) == 0 {
+		return ""
+	}
+	return n.Front.Tags[0]
+}
+
+// refs returns the note's refs as item IDs, unwrapping [[wikilinks]].
+func (n *vaultNote) refs() []string {
+	refs := make([]string, 0, len(n.Front.Refs))
+	for _, ref := range n.Front.Refs {
+		ref = strings.TrimSpace(ref)
+		ref = strings.TrimSuffix(strings.TrimPrefix(ref, "[["), "]]")
+		ref, _, _ = strings.Cut(ref, "|")
+		if ref = strings.TrimSpace(ref); ref != "" {
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// hash is the content hash of the note as it is now.
+func (n *vaultNote) hash() string {
+	return vaultHash(n.Front.Type, n.Front.Oneliner, n.tag(), n.Front.Severity, n.Front.Pinned, n.refs(), n.Body)
+}
+
+// entryHash is the content hash of an item as dory has it.
+func entryHash(entry *doryfile.Entry) string {
+	return vaultHash(entry.Type, entry.Oneliner, entryTag(entry), entry.Severity, entry.Pinned, entry.Refs, entry.Body)
+}
+
+// vaultHash hashes the fields a vault note can edit. Trailing whitespace
+// is ignored, as the log does not keep it.
+func vaultHash(itemType, oneliner, tag, severity string, pinned bool, refs []string, body string) string {
+	lines := strings.Split(strings.TrimSpace(body), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		itemType,
+		strings.TrimSpace(oneliner),
+		tag,
+		severity,
+		fmt.Sprint(pinned),
+		strings.Join(refs, ","),
+		strings.Join(lines, "\n"),
+	}, "\x00")))
+	return hex.EncodeToString(sum[:8])
+}
+
+func entryTag(entry *doryfile.Entry) string {
+	if entry.Topic != "" {
+		return entry.Topic
+	}
+	return entry.Domain
+}
+
+// setEntryTag sets an item's tag in the field it already uses, as bulk
+// edits do; an untagged convention takes a domain, anything else a topic.
+func setEntryTag(entry *doryfile.Entry, tag string) {
+	switch {
+	case entry.Topic != "" && entry.Domain != "":
+		entry.Topic, entry.Domain = tag, tag
+	case entry.Domain != "" || (entry.Topic == "" && entry.Type == "convention"):
+		entry.Domain = tag
+	default:
+		entry.Topic = tag
+	}
+}
+
+// renderVaultNote writes an item as a vault note: frontmatter with refs as
+// [[wikilinks]] and the content hash, then the body. When the note exists,
+// its frontmatter is passed as base so the keys and extra tags the user added
+// are kept.
+func renderVaultNote(entry *doryfile.Entry, base *yaml.Node) string {
+	front := vaultFront{
+		ID:       entry.ID,
+		Type:     entry.Type,
+		Oneliner: entry.Oneliner,
+		Severity: entry.Severity,
+		Pinned:   entry.Pinned,
+		Alias:    entry.Alias,
+		Created:  entry.Created.UTC().Format(time.RFC3339),
+		Hash:     entryHash(entry),
+	}
+	if tag := entryTag(entry); tag != "" {
+		front.Tags = vaultTags{tag}
+		for _, extra := range baseTags(base) {
+			if extra != tag {
+				front.Tags = append(front.Tags, extra)
+			}
+		}
+	}
+	for _, ref := range entry.Refs {
+		front.Refs = append(front.Refs, "[["+ref+"]]")
+	}
+
+	// Encoding a plain struct cannot fail.
+	var node yaml.Node
+	_ = node.Encode(front)
+	if base != nil {
+		node = mergeFront(base, &node)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("---\n")
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	_ = enc.Encode(&node)
+	_ = enc.Close()
+	buf.WriteString("---\n")
+	if body := strings.TrimSpace(entry.Body); body != "" {
+		buf.WriteString("\n" + body + "\n")
+	}
+	return buf.String()
+}
+
+// mergeFront returns base with dory's keys replaced by those in front, in
+// place, and dory's keys base lacks appended. Dory keys front leaves out
+// (omitted as empty) are dropped; every other key in base is kept as is.
+func mergeFront(base, front *yaml.Node) yaml.Node {
+	values := make(map[string]*yaml.Node)
+	for i := 0; i+1 < len(front.Content); i += 2 {
+		values[front.Content[i].Value] = front.Content[i+1]
+	}
+	merged := *base
+	merged.Content = nil
+	written := make(map[string]bool)
+	for i := 0; i+1 < len(base.Content); i += 2 {
+		key := base.Content[i].Value
+		if !vaultKeys[key] {
+			merged.Content = append(merged.Content, base.Content[i], base.Content[i+1])
+			continue
+		}
+		if value := values[key]; value != nil && !written[key] {
+			merged.Content = append(merged.Content, base.Content[i], value)
+			written[key] = true
+		}
+	}
+	for i := 0; i+1 < len(front.Content); i += 2 {
+		if !written[front.Content[i].Value] {
+			merged.Content = append(merged.Content, front.Content[i], front.Content[i+1])
+		}
+	}
+	return merged
+}
+
+// baseTags returns the tags after the first in a note's frontmatter: the
+// user's own, which dory does not track.
+func baseTags(base *yaml.Node) []string {
+	if base == nil {
+		return nil
+	}
+	for i := 0; i+1 < len(base.Content); i += 2 {
+		if base.Content[i].Value != "tags" {
+			continue
+		}
+		var tags vaultTags
+		if base.Content[i+1].Decode(&tags) != nil || len(tags) < 2 {
+			return nil
+		}
+		return tags[1:]
+	}
+	return nil
+}
+
+// parseVaultNote reads a note. It returns nil for markdown files that are
+// not dory notes: no frontmatter, or frontmatter with neither id nor type.
+func parseVaultNote(path, content string) *vaultNote {
+	raw, body, ok := splitFrontmatter(strings.ReplaceAll(content, "\r\n", "\n"))
+	if !ok {
+		return nil
+	}
+	note := &vaultNote{Path: path, Body: strings.TrimSpace(body)}
+	if err := yaml.Unmarshal([]byte(raw), &note.Front); err != nil {
+		var probe map[string]interface{}
+		if yaml.Unmarshal([]byte(raw), &probe) != nil || (probe["id"] == nil && probe["type"] == nil) {
+			return nil
+		}
+		note.Err = fmt.Errorf("invalid frontmatter: %w", err)
+		return note
+	}
+	if note.Front.ID == "" && note.Front.Type == "" {
+		return nil
+	}
+	var doc yaml.Node
+	if yaml.Unmarshal([]byte(raw), &doc) == nil && len(doc.Content) == 1 && doc.Content[0].Kind == yaml.MappingNode {
+		note.Raw = doc.Content[0]
+	}
+	return note
+}
+
+// readVault returns the dory notes under dir, skipping hidden directories
+// such as .obsidian and .trash. Paths are relative to dir.
+func readVault(dir string) ([]*vaultNote, error) {
+	var notes []*vaultNote
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == dir {
+				return filepath.SkipAll
+			}
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(d.Name(), ".md") {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if note := parseVaultNote(rel, string(data)); note != nil {
+			notes = append(notes, note)
+		}
+		return nil
+	})
+	return notes, err
+}
+
+// VaultFileResult is what happened to one note on export or import.
+type VaultFileResult struct {
+	Path   string `json:"path" yaml:"path"`
+	ID     string `json:"id,omitempty" yaml:"id,omitempty"`
+	Status string `json:"status" yaml:"status"`
+	Detail string `json:"detail,omitempty" yaml:"detail,omitempty"`
+}
+
+// VaultResult summarizes a vault export or import. Unchanged notes are
+// only counted.
+type VaultResult struct {
+	Dir       string            `json:"dir" yaml:"dir"`
+	Unchanged int               `json:"unchanged" yaml:"unchanged"`
+	Files     []VaultFileResult `json:"files" yaml:"files"`
+	Conflicts int               `json:"conflicts" yaml:"conflicts"`
+}
+
+func (r *VaultResult) add(path, id, status, detail string) {
+	if status == "unchanged" {
+		r.Unchanged++
+		return
+	}
+	if status == "conflict" {
+		r.Conflicts++
+	}
+	r.Files = append(r.Files, VaultFileResult{Path: path, ID: id, Status: status, Detail: detail})
+}
+
+func (r *VaultResult) print() {
+	for _, file := range r.Files {
+		line := fmt.Sprintf("%-9s %s", file.Status, file.Path)
+		if file.Detail != "" {
+			line += "  (" + file.Detail + ")"
+		}
+		fmt.Println(line)
+	}
+	counts := make(map[string]int)
+	var order []string
+	for _, file := range r.Files {
+		if counts[file.Status] == 0 {
+			order = append(order, file.Status)
+		}
+		counts[file.Status]++
+	}
+	parts := []string{fmt.Sprintf("%d unchanged", r.Unchanged)}
+	for _, status := range order {
+		parts = append(parts, fmt.Sprintf("%d %s", counts[status], status))
+	}
+	if len(r.Files) > 0 {
+		fmt.Println()
+	}
+	fmt.Printf("%s: %s\n", r.Dir, strings.Join(parts, ", "))
+}
+
+// writeVaultNote writes content to path under dir, creating directories.
+func writeVaultNote(dir, path, content string) error {
+	full := filepath.Join(dir, path)
+	if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(full, []byte(content), 0644)
+}
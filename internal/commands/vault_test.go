@@ -0,0 +1,233 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sibellavia/dory/internal/models"
+	"github.com/sibellavia/dory/internal/store"
+)
+
+func TestVaultSync(t *testing.T) {
+	dir := t.TempDir()
+	s := store.New(filepath.Join(dir, ".dory"))
+	defer s.Close()
+	if err := s.Init("project", ""); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	decision, err := s.Decide("Use Postgres", "db", "", "# Use Postgres\n\nBoring is good.", nil)
+	if err != nil {
+		t.Fatalf("decide: %v", err)
+	}
+	lesson, err := s.Learn("Vacuum nightly", "db", models.SeverityHigh, "Autovacuum lagged.", []string{decision})
+	if err != nil {
+		t.Fatalf("learn: %v", err)
+	}
+
+	vault := filepath.Join(dir, "vault")
+	export := func(force bool) *VaultResult {
+		t.Helper()
+		items, err := s.List(store.ListFilter{})
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		result, err := exportVault(s, items, vault, force)
+		if err != nil {
+			t.Fatalf("export: %v", err)
+		}
+		return result
+	}
+	importNotes := func() *VaultResult {
+		t.Helper()
+		result, err := importVault(s, vault, false)
+		if err != nil {
+			t.Fatalf("import: %v", err)
+		}
+		return result
+	}
+	status := func(result *VaultResult, id string) string {
+		for _, file := range result.Files {
+			if file.ID == id {
+				return file.Status
+			}
+		}
+		return "unchanged"
+	}
+	editNote := func(itemType, id, old, new string) {
+		t.Helper()
+		path := filepath.Join(vault, itemType, id+".md")
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(strings.Replace(string(data), old, new, 1)), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if result := export(false); len(result.Files) != 2 || result.Files[0].Status != "written" {
+		t.Fatalf("expected two written notes, got %+v", result)
+	}
+	note, err := os.ReadFile(filepath.Join(vault, "lesson", lesson+".md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(note), "'[["+decision+"]]'") || !strings.Contains(string(note), "dory-hash: ") {
+		t.Fatalf("expected a wikilink ref and a hash:\n%s", note)
+	}
+	if result := importNotes(); result.Unchanged != 2 || len(result.Files) != 0 {
+		t.Fatalf("expected an untouched vault to import nothing, got %+v", result)
+	}
+
+	// A vault edit becomes an update event.
+	editNote("lesson", lesson, "Autovacuum lagged.", "Autovacuum lagged behind writes.")
+	if got := status(importNotes(), lesson); got != "updated" {
+		t.Fatalf("expected the edited note to update, got %s", got)
+	}
+	entry, err := s.GetEntry(lesson)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Body != "Autovacuum lagged behind writes." || len(entry.Refs) != 1 {
+		t.Fatalf("unexpected entry after import: %+v", entry)
+	}
+
+	// Re-tagging in the vault keeps the tag in the field the item uses.
+	editNote("lesson", lesson, "  - db\n", "  - postgres\n")
+	if got := status(importNotes(), lesson); got != "updated" {
+		t.Fatalf("expected the re-tagged note to update, got %s", got)
+	}
+	if entry, err = s.GetEntry(lesson); err != nil {
+		t.Fatal(err)
+	}
+	if entry.Topic != "postgres" || entry.Domain != "" {
+		t.Fatalf("expected only the lesson's topic to change, got topic %q domain %q", entry.Topic, entry.Domain)
+	}
+
+	// Edits on both sides are a conflict both ways.
+	editNote("decision", decision, "Boring is good.", "Boring is great.")
+	dec, err := s.GetEntry(decision)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dec.Oneliner = "Use PostgreSQL"
+	if err := s.UpdateEntry(dec); err != nil {
+		t.Fatal(err)
+	}
+	result := importNotes()
+	if got := status(result, decision); got != "conflict" || result.Conflicts != 1 {
+		t.Fatalf("expected a conflict on import, got %s", got)
+	}
+	if got := status(export(false), decision); got != "conflict" {
+		t.Fatalf("expected a conflict on export, got %s", got)
+	}
+	if got := status(export(true), decision); got != "written" {
+		t.Fatalf("expected --force to overwrite the note, got %s", got)
+	}
+
+	// New notes become items; notes of deleted items are removed.
+	newNote := filepath.Join(vault, "inbox", "retry.md")
+	if err := os.MkdirAll(filepath.Dir(newNote), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(newNote, []byte("---\ntype: lesson\ntags: '#api'\n---\n# Retry on 429\n\nBack off.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	result = importNotes()
+	if len(result.Files) != 1 || result.Files[0].Status != "created" {
+		t.Fatalf("expected the new note to be created, got %+v", result.Files)
+	}
+	created, err := s.GetEntry(result.Files[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.Oneliner != "Retry on 429" || created.Topic != "api" || created.Severity != "normal" {
+		t.Fatalf("unexpected created item %+v", created)
+	}
+	if data, _ := os.ReadFile(newNote); !strings.Contains(string(data), "id: "+created.ID) {
+		t.Fatalf("expected the note to gain its id:\n%s", data)
+	}
+
+	if _, err := s.RemoveUnlinking(lesson); err != nil {
+		t.Fatal(err)
+	}
+	if got := status(export(false), lesson); got != "removed" {
+		t.Fatalf("expected the deleted item's note to be removed, got %s", got)
+	}
+}
+
+func TestVaultKeepsUserFrontmatter(t *testing.T) {
+	dir := t.TempDir()
+	s := store.New(filepath.Join(dir, ".dory"))
+	defer s.Close()
+	if err := s.Init("project", ""); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	id, err := s.Learn("Vacuum nightly", "db", models.SeverityHigh, "Autovacuum lagged.", nil)
+	if err != nil {
+		t.Fatalf("learn: %v", err)
+	}
+	vault := filepath.Join(dir, "vault")
+	export := func() {
+		t.Helper()
+		items, err := s.List(store.ListFilter{})
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		if _, err := exportVault(s, items, vault, false); err != nil {
+			t.Fatalf("export: %v", err)
+		}
+	}
+	export()
+
+	path := filepath.Join(vault, "lesson", id+".md")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := strings.Replace(string(data), "tags:\n  - db\n", "aliases: [vacuum]\ntags:\n  - db\n  - postgres\ncssclasses: wide\n", 1)
+	content = strings.Replace(content, "Autovacuum lagged.", "Autovacuum lagged behind writes.", 1)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	check := func(step string) {
+		t.Helper()
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{"aliases: [vacuum]\n", "  - db\n  - postgres\n", "cssclasses: wide\n"} {
+			if !strings.Contains(string(data), want) {
+				t.Fatalf("%s dropped %q:\n%s", step, want, data)
+			}
+		}
+	}
+
+	result, err := importVault(s, vault, false)
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if len(result.Files) != 1 || result.Files[0].Status != "updated" {
+		t.Fatalf("expected the note to update, got %+v", result.Files)
+	}
+	check("import")
+
+	entry, err := s.GetEntry(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Topic != "db" {
+		t.Fatalf("expected the first tag to stay the item's tag, got %+v", entry)
+	}
+	entry.Oneliner = "Vacuum every night"
+	if err := s.UpdateEntry(entry); err != nil {
+		t.Fatal(err)
+	}
+	export()
+	check("export")
+	if data, _ := os.ReadFile(path); !strings.Contains(string(data), "oneliner: Vacuum every night") {
+		t.Fatalf("expected the export to update the oneliner:\n%s", data)
+	}
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/sibellavia/dory/internal/fileio"
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectConfigFileName is the project settings file under .dory.
+const ProjectConfigFileName = "config.yaml"
+
+// ProjectConfig stores per-project dory settings.
+type ProjectConfig struct {
+	Version int `yaml:"version" json:"version"`
+	// IncludeGlobal merges the user-global store into reads by default.
+	IncludeGlobal bool `yaml:"include_global,omitempty" json:"include_global,omitempty"`
+	// Aliases gives new items a short sequential alias such as L-42.
+	Aliases bool `yaml:"aliases,omitempty" json:"aliases,omitempty"`
+	// ContextRanking weighs items for `dory context --budget`.
+	ContextRanking *ContextRanking `yaml:"context_ranking,omitempty" json:"context_ranking,omitempty"`
+	// Docs configures the managed regions `dory sync-docs` regenerates.
+	Docs *DocsConfig `yaml:"docs,omitempty" json:"docs,omitempty"`
+}
+
+// DocsConfig lists the files with dory-managed regions and named region
+// specs. Paths are relative to the project root.
+type DocsConfig struct {
+	Files   []string              `yaml:"files,omitempty" json:"files,omitempty"`
+	Regions map[string]DocsRegion `yaml:"regions,omitempty" json:"regions,omitempty"`
+}
+
+// DocsRegion selects the items of a managed region, like the flags of
+// `dory export`. A marker names it with region=<name>.
+type DocsRegion struct {
+	Tag    string `yaml:"tag,omitempty" json:"tag,omitempty"`
+	Type   string `yaml:"type,omitempty" json:"type,omitempty"`
+	Filter string `yaml:"filter,omitempty" json:"filter,omitempty"`
+}
+
+// AddDocsFile records path as a file with managed regions, reporting
+// whether it was new.
+func (c *ProjectConfig) AddDocsFile(path string) bool {
+	if c.Docs == nil {
+		c.Docs = &DocsConfig{}
+	}
+	for _, file := range c.Docs.Files {
+		if file == path {
+			return false
+		}
+	}
+	c.Docs.Files = append(c.Docs.Files, path)
+	return true
+}
+
+// ContextRanking weighs the signals used to rank items for a token-budgeted
+// context. Each signal is scaled to 0..1 before weighting.
+type ContextRanking struct {
+	Severity   float64 `yaml:"severity" json:"severity"`
+	Recency    float64 `yaml:"recency" json:"recency"`
+	Tag        float64 `yaml:"tag" json:"tag"`
+	Centrality float64 `yaml:"centrality" json:"centrality"`
+	// RecencyHalfLifeDays is the age at which the recency signal halves.
+	RecencyHalfLifeDays float64 `yaml:"recency_half_life_days" json:"recency_half_life_days"`
+}
+
+// DefaultContextRanking returns the built-in ranking weights.
+func DefaultContextRanking() ContextRanking {
+	return ContextRanking{
+		Severity:            3,
+		Recency:             2,
+		Tag:                 2,
+		Centrality:          1,
+		RecencyHalfLifeDays: 14,
+	}
+}
+
+// Ranking returns the configured context ranking, or the defaults.
+func (c *ProjectConfig) Ranking() ContextRanking {
+	if c == nil || c.ContextRanking == nil {
+		return DefaultContextRanking()
+	}
+	return *c.ContextRanking
+}
+
+// Default returns an empty v1 project config.
+func Default() *ProjectConfig {
+	return &ProjectConfig{Version: 1}
+}
+
+// Path returns the project config path under .dory.
+func Path(doryRoot string) string {
+	return filepath.Join(doryRoot, ProjectConfigFileName)
+}
+
+// Load reads project config or returns defaults if missing.
+func Load(doryRoot string) (*ProjectConfig, error) {
+	data, err := os.ReadFile(Path(doryRoot))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Default(), nil
+		}
+		return nil, err
+	}
+
+	cfg := Default()
+	// Pre-fill ranking defaults so a partial context_ranking section only
+	// overrides the weights it names.
+	ranking := DefaultContextRanking()
+	cfg.ContextRanking = &ranking
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, err
+	}
+	if cfg.ContextRanking != nil && *cfg.ContextRanking == DefaultContextRanking() {
+		cfg.ContextRanking = nil
+	}
+	if cfg.Version == 0 {
+		cfg.Version = 1
+	}
+	return cfg, nil
+}
+
+// Save writes project config under .dory.
+func Save(doryRoot string, cfg *ProjectConfig) error {
+	if cfg == nil {
+		cfg = Default()
+	}
+	if cfg.Version == 0 {
+		cfg.Version = 1
+	}
+	if err := os.MkdirAll(doryRoot, 0755); err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	return fileio.WriteFileAtomic(Path(doryRoot), data, 0644)
+}
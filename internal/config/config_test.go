@@ -0,0 +1,54 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProjectConfigRoundTrip(t *testing.T) {
+	doryRoot := filepath.Join(t.TempDir(), ".dory")
+
+	cfg, err := Load(doryRoot)
+	if err != nil {
+		t.Fatalf("load default config: %v", err)
+	}
+	if cfg.Version != 1 || cfg.IncludeGlobal {
+		t.Fatalf("unexpected default config: %+v", cfg)
+	}
+
+	cfg.IncludeGlobal = true
+	if err := Save(doryRoot, cfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	cfg, err = Load(doryRoot)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if !cfg.IncludeGlobal {
+		t.Fatal("expected include_global to persist")
+	}
+}
+
+func TestContextRankingPartialOverride(t *testing.T) {
+	doryRoot := filepath.Join(t.TempDir(), ".dory")
+	if err := os.MkdirAll(doryRoot, 0755); err != nil {
+		t.Fatal(err)
+	}
+	data := []byte("version: 1\ncontext_ranking:\n  recency: 0\n")
+	if err := os.WriteFile(filepath.Join(doryRoot, ProjectConfigFileName), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(doryRoot)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	ranking := cfg.Ranking()
+	want := DefaultContextRanking()
+	want.Recency = 0
+	if ranking != want {
+		t.Fatalf("expected %+v, got %+v", want, ranking)
+	}
+}
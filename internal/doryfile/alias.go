@@ -0,0 +1,52 @@
+package doryfile
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// NextAlias returns the next sequential alias for an ID prefix (e.g. "L-42").
+// The counter only advances once an entry carrying the alias is appended.
+func (df *DoryFile) NextAlias(prefix string) string {
+	return fmt.Sprintf("%s-%d", prefix, df.Index.AliasSeq[prefix]+1)
+}
+
+// noteAlias raises the alias counter past an applied alias.
+// Counters never go down, so aliases of deleted items are not reused.
+func (df *DoryFile) noteAlias(alias string) {
+	prefix, n, ok := ParseAlias(alias)
+	if !ok {
+		return
+	}
+	if df.Index.AliasSeq == nil {
+		df.Index.AliasSeq = make(map[string]int)
+	}
+	if n > df.Index.AliasSeq[prefix] {
+		df.Index.AliasSeq[prefix] = n
+	}
+}
+
+// ParseAlias splits an alias like "L-42" into its prefix and number.
+// Numbers never start with 0, which keeps aliases apart from ID prefixes
+// such as "L-01K".
+func ParseAlias(alias string) (string, int, bool) {
+	prefix, digits, ok := strings.Cut(alias, "-")
+	if !ok || prefix == "" || digits == "" || digits[0] == '0' {
+		return "", 0, false
+	}
+	n, err := strconv.Atoi(digits)
+	if err != nil || n <= 0 {
+		return "", 0, false
+	}
+	return prefix, n, true
+}
+
+// RaiseAliasSeq raises alias counters to at least seq, so a store rebuilt
+// from live items never reuses aliases of items deleted before.
+func (df *DoryFile) RaiseAliasSeq(seq map[string]int) error {
+	for prefix, n := range seq {
+		df.noteAlias(fmt.Sprintf("%s-%d", prefix, n))
+	}
+	return df.saveIndex()
+}
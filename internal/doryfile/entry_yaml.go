@@ -45,6 +45,13 @@ func (e Entry) MarshalYAML() (interface{}, error) {
 		)
 	}
 
+	if e.Alias != "" {
+		addField("alias", e.Alias)
+	}
+	if e.Pinned {
+		addField("pinned", "true")
+	}
+
 	if e.Body != "" {
 		// Strip trailing spaces from lines (YAML literal blocks can't preserve them).
 		body := e.Body
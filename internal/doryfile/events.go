@@ -0,0 +1,95 @@
+package doryfile
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Event ops as recorded in the log.
+const (
+	OpItemCreate = opItemCreate
+	OpItemUpdate = opItemUpdate
+	OpItemDelete = opItemDelete
+	OpState      = opState
+	OpTask       = opTask
+	OpQuestion   = opQuestion
+	OpCompact    = opCompact
+)
+
+// Event is one record of the knowledge log. At is zero for events written
+// before event times were recorded.
+type Event struct {
+	Seq      uint64
+	Op       string
+	At       time.Time
+	ID       string
+	Item     *Entry
+	State    *State
+	Task     *Task
+	Question *Question
+}
+
+// Events calls fn for every event in the log, oldest first, until fn
+// returns false.
+func (df *DoryFile) Events(fn func(Event) bool) error {
+	if _, err := df.knowledge.Seek(0, 0); err != nil {
+		return fmt.Errorf("failed to seek knowledge file: %w", err)
+	}
+	reader := bufio.NewReader(df.knowledge)
+	header, err := reader.ReadString('\n')
+	if err != nil || strings.TrimSpace(header) != MagicHeader {
+		return fmt.Errorf("invalid dory file header: expected %s", MagicHeader)
+	}
+
+	_, err = df.walkEvents(reader, int64(len(header)), 0, func(seq uint64, payloadOffset int64, payload []byte) (bool, error) {
+		var ev logEvent
+		if err := yaml.Unmarshal(payload, &ev); err != nil {
+			return false, corruptionError(payloadOffset, "invalid event yaml: %v", err)
+		}
+		event := Event{Seq: seq, Op: ev.Op, At: ev.At, ID: ev.ID, Item: ev.Item, State: ev.State, Task: ev.Task, Question: ev.Question}
+		switch {
+		case ev.Item != nil:
+			event.ID = ev.Item.ID
+		case ev.Task != nil:
+			event.ID = ev.Task.ID
+		case ev.Question != nil:
+			event.ID = ev.Question.ID
+		}
+		return fn(event), nil
+	})
+	return err
+}
+
+// AppendEvents writes events to the log as given, in a single write followed
+// by one index save. Seqs continue from the end of the log.
+func (df *DoryFile) AppendEvents(events []Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	logged := make([]*logEvent, len(events))
+	for i, event := range events {
+		ev := &logEvent{Op: event.Op, At: event.At, Item: event.Item, State: event.State, Task: event.Task, Question: event.Question}
+		if event.Op == opItemDelete {
+			ev.ID = event.ID
+		}
+		if err := ev.validate(); err != nil {
+			return fmt.Errorf("event %d: %w", event.Seq, err)
+		}
+		logged[i] = ev
+	}
+
+	placed, err := df.appendEvents(logged)
+	if err != nil {
+		return err
+	}
+	for i, ev := range logged {
+		if err := df.applyEvent(placed[i].seq, ev, placed[i].offset, placed[i].length); err != nil {
+			return err
+		}
+	}
+	return df.saveIndex()
+}
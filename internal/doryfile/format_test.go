@@ -429,3 +429,87 @@ func TestDeleteThenReuseIDSurvivesReopen(t *testing.T) {
 		t.Fatalf("expected deleted list to be cleared for reused ID, got %v", df2.Index.Deleted)
 	}
 }
+
+func TestLegacyStringStateGetsTaskAndQuestionIDs(t *testing.T) {
+	root := filepath.Join(t.TempDir(), ".dory")
+	if err := os.MkdirAll(root, 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+
+	knowledge := MagicHeader + "\n" + EventDelim + "\n" +
+		"op: state.update\nstate:\n  next:\n    - write tests\n    - ship it\n  open_questions:\n    - which db?\n"
+	if err := os.WriteFile(filepath.Join(root, KnowledgeFile), []byte(knowledge), 0644); err != nil {
+		t.Fatalf("write knowledge: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, IndexFile), []byte("format: "+IndexFormat+"\nproject: test\n"), 0644); err != nil {
+		t.Fatalf("write index: %v", err)
+	}
+
+	df, err := Open(root)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer df.Close()
+
+	state := df.Index.State
+	if len(state.Next) != 2 || state.Next[0].ID != "T-1" || state.Next[1].ID != "T-2" {
+		t.Fatalf("expected legacy next steps to become T-1/T-2, got %+v", state.Next)
+	}
+	if state.Next[0].Text != "write tests" || state.Next[0].Status != TaskTodo {
+		t.Fatalf("unexpected first task: %+v", state.Next[0])
+	}
+	if len(state.OpenQuestions) != 1 || state.OpenQuestions[0].ID != "Q-1" || state.OpenQuestions[0].Status != QuestionOpen {
+		t.Fatalf("expected legacy question to become open Q-1, got %+v", state.OpenQuestions)
+	}
+	if got := df.NextTaskID(); got != "T-3" {
+		t.Fatalf("expected next task ID T-3, got %s", got)
+	}
+}
+
+func TestAppendTaskSurvivesFullReplay(t *testing.T) {
+	root := filepath.Join(t.TempDir(), ".dory")
+	if err := os.MkdirAll(root, 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+
+	df, err := Create(root, "test", "")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := df.UpdateState(&State{Goal: "goal", Next: []Task{{Text: "first"}}}); err != nil {
+		t.Fatalf("update state: %v", err)
+	}
+	if err := df.AppendTask(&Task{ID: df.NextTaskID(), Text: "second", Status: TaskTodo}); err != nil {
+		t.Fatalf("append task: %v", err)
+	}
+	if err := df.AppendTask(&Task{ID: "T-1", Text: "first", Status: TaskDone}); err != nil {
+		t.Fatalf("update task: %v", err)
+	}
+	if err := df.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	// Drop the snapshot to force full replay.
+	if err := os.WriteFile(filepath.Join(root, IndexFile), []byte("format: "+IndexFormat+"\nproject: test\n"), 0644); err != nil {
+		t.Fatalf("write index: %v", err)
+	}
+
+	reopened, err := Open(root)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer reopened.Close()
+
+	state := reopened.Index.State
+	if state.Goal != "goal" || len(state.Next) != 2 {
+		t.Fatalf("unexpected replayed state: %+v", state)
+	}
+	first, ok := state.FindTask("T-1")
+	if !ok || first.Status != TaskDone {
+		t.Fatalf("expected T-1 done after replay, got %+v", state.Next)
+	}
+	second, ok := state.FindTask("T-2")
+	if !ok || second.Text != "second" || second.Status != TaskTodo {
+		t.Fatalf("expected T-2 todo after replay, got %+v", state.Next)
+	}
+}
@@ -29,6 +29,7 @@ func (df *DoryFile) loadIndex() error {
 	if index.State == nil {
 		index.State = &State{}
 	}
+	normalizeState(index.State)
 
 	df.Index = &index
 	df.nextSeq = df.Index.AppliedSeq
@@ -62,6 +63,8 @@ func (df *DoryFile) saveIndex() error {
 			Oneliner:     entry.Oneliner,
 			Created:      entry.Created,
 			Refs:         append([]string(nil), entry.Refs...),
+			Alias:        entry.Alias,
+			Pinned:       entry.Pinned,
 			BodyOffset:   entry.Offset,
 			BodyLen:      entry.BodyLen,
 			LastEventSeq: df.nextSeq,
@@ -2,6 +2,7 @@ package doryfile
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -87,6 +88,47 @@ func Open(dir string) (*DoryFile, error) {
 	return df, nil
 }
 
+// OpenAt opens a storage read-only as it was after event seq, replaying the
+// log from the start and ignoring the snapshot. A zero seq replays every
+// event. The index file is optional, so a bare knowledge file can be read.
+func OpenAt(dir string, seq uint64) (*DoryFile, error) {
+	knowledgePath := filepath.Join(dir, KnowledgeFile)
+	indexPath := filepath.Join(dir, IndexFile)
+
+	f, err := os.Open(knowledgePath)
+	if err != nil {
+		return nil, err
+	}
+
+	df := &DoryFile{
+		Dir:           dir,
+		KnowledgePath: knowledgePath,
+		IndexPath:     indexPath,
+		knowledge:     f,
+		entries:       make(map[string]*MemoryEntry),
+		stopSeq:       seq,
+	}
+	if err := df.loadIndex(); err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			f.Close()
+			return nil, err
+		}
+		df.Index = &Index{Format: IndexFormat, State: &State{}}
+	}
+
+	reader := bufio.NewReader(f)
+	header, err := reader.ReadString('\n')
+	if err != nil || strings.TrimSpace(header) != MagicHeader {
+		f.Close()
+		return nil, fmt.Errorf("invalid dory file header: expected %s", MagicHeader)
+	}
+	if err := df.replayAll(int64(len(header))); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return df, nil
+}
+
 // scan reads the knowledge file and builds the in-memory index.
 func (df *DoryFile) scan() error {
 	if _, err := df.knowledge.Seek(0, 0); err != nil {
@@ -117,6 +159,11 @@ func (df *DoryFile) scanEvents(reader *bufio.Reader, startPos int64) error {
 	}
 
 	// Fallback to full replay.
+	return df.replayAll(startPos)
+}
+
+// replayAll rebuilds the in-memory index from the first event.
+func (df *DoryFile) replayAll(startPos int64) error {
 	df.entries = make(map[string]*MemoryEntry)
 	df.nextSeq = 0
 	df.logOffset = startPos
@@ -126,8 +173,7 @@ func (df *DoryFile) scanEvents(reader *bufio.Reader, startPos int64) error {
 	if _, err := df.knowledge.Seek(startPos, 0); err != nil {
 		return fmt.Errorf("failed to seek replay start: %w", err)
 	}
-	reader = bufio.NewReader(df.knowledge)
-	return df.replayEvents(reader, startPos, 1)
+	return df.replayEvents(bufio.NewReader(df.knowledge), startPos, 1)
 }
 
 func (df *DoryFile) hydrateFromSnapshot(startPos int64) bool {
@@ -150,6 +196,8 @@ func (df *DoryFile) hydrateFromSnapshot(startPos int64) bool {
 			Oneliner: head.Oneliner,
 			Created:  head.Created,
 			Refs:     append([]string(nil), head.Refs...),
+			Alias:    head.Alias,
+			Pinned:   head.Pinned,
 		}
 	}
 	if df.Index.State == nil {
@@ -161,9 +209,34 @@ func (df *DoryFile) hydrateFromSnapshot(startPos int64) bool {
 }
 
 func (df *DoryFile) replayEvents(reader *bufio.Reader, startPos int64, minSeq uint64) error {
-	pos := startPos
-	seq := minSeq - 1
+	pos, err := df.walkEvents(reader, startPos, minSeq-1, func(seq uint64, payloadOffset int64, payload []byte) (bool, error) {
+		if df.stopSeq > 0 && seq > df.stopSeq {
+			return false, nil
+		}
+		var ev logEvent
+		if err := yaml.Unmarshal(payload, &ev); err != nil {
+			return false, corruptionError(payloadOffset, "invalid event yaml: %v", err)
+		}
+		if err := df.applyEvent(seq, &ev, payloadOffset, len(payload)); err != nil {
+			return false, corruptionError(payloadOffset, "%v", err)
+		}
+		return true, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	df.logOffset = pos
+	df.Index.AppliedSeq = df.nextSeq
+	df.Index.LogOffset = df.logOffset
+	return nil
+}
 
+// walkEvents reads events from startPos, numbering them after seq, and
+// calls fn with each raw payload until fn returns false or the log ends.
+// It returns the position reached.
+func (df *DoryFile) walkEvents(reader *bufio.Reader, startPos int64, seq uint64, fn func(seq uint64, payloadOffset int64, payload []byte) (bool, error)) (int64, error) {
+	pos := startPos
 	for {
 		lineStart := pos
 		line, err := reader.ReadString('\n')
@@ -171,7 +244,7 @@ func (df *DoryFile) replayEvents(reader *bufio.Reader, startPos int64, minSeq ui
 			break
 		}
 		if err != nil && err != io.EOF {
-			return fmt.Errorf("failed reading event delimiter: %w", err)
+			return pos, fmt.Errorf("failed reading event delimiter: %w", err)
 		}
 		pos += int64(len(line))
 
@@ -182,42 +255,27 @@ func (df *DoryFile) replayEvents(reader *bufio.Reader, startPos int64, minSeq ui
 			continue
 		}
 		if !isDelimiterLine(line) {
-			return corruptionError(lineStart, "expected delimiter %q, got %q", EventDelim, strings.TrimRight(line, "\r\n"))
+			return pos, corruptionError(lineStart, "expected delimiter %q, got %q", EventDelim, strings.TrimRight(line, "\r\n"))
 		}
 
 		payloadOffset := pos
 		payload, newPos, newReader, err := df.readEventPayload(reader, pos)
 		if err != nil {
-			return err
+			return pos, err
 		}
 		pos = newPos
 		reader = newReader
 
 		seq++
-		if seq < minSeq {
-			if err == io.EOF {
-				break
-			}
-			continue
-		}
-
-		var ev logEvent
-		if err := yaml.Unmarshal(payload, &ev); err != nil {
-			return corruptionError(payloadOffset, "invalid event yaml: %v", err)
-		}
-		if err := df.applyEvent(seq, &ev, payloadOffset, len(payload)); err != nil {
-			return corruptionError(payloadOffset, "%v", err)
+		more, fnErr := fn(seq, payloadOffset, payload)
+		if fnErr != nil {
+			return pos, fnErr
 		}
-
-		if err == io.EOF {
+		if !more || err == io.EOF {
 			break
 		}
 	}
-
-	df.logOffset = pos
-	df.Index.AppliedSeq = df.nextSeq
-	df.Index.LogOffset = df.logOffset
-	return nil
+	return pos, nil
 }
 
 func (df *DoryFile) readEventPayload(reader *bufio.Reader, startPos int64) ([]byte, int64, *bufio.Reader, error) {
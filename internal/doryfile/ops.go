@@ -5,6 +5,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/sibellavia/dory/internal/fileio"
 	"gopkg.in/yaml.v3"
@@ -28,36 +29,92 @@ func (df *DoryFile) Append(entry *Entry) error {
 	return df.saveIndex()
 }
 
-func (df *DoryFile) appendEvent(ev *logEvent) (int64, int, uint64, error) {
-	if df.knowledge == nil {
-		return 0, 0, 0, fmt.Errorf("knowledge file is not open")
+// AppendBatch adds several entries in a single write followed by one index
+// save, so a bulk edit is never left half applied by a failed marshal.
+func (df *DoryFile) AppendBatch(entries []*Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	events := make([]*logEvent, len(entries))
+	for i, entry := range entries {
+		op := opItemCreate
+		if _, exists := df.entries[entry.ID]; exists {
+			op = opItemUpdate
+		}
+		events[i] = &logEvent{Op: op, Item: entry}
 	}
-	stat, err := df.knowledge.Stat()
+
+	placed, err := df.appendEvents(events)
 	if err != nil {
-		return 0, 0, 0, err
+		return err
 	}
-	start := stat.Size()
+	for i, ev := range events {
+		if err := df.applyEvent(placed[i].seq, ev, placed[i].offset, placed[i].length); err != nil {
+			return err
+		}
+	}
+	return df.saveIndex()
+}
+
+// placedEvent records where an appended event's payload landed.
+type placedEvent struct {
+	offset int64
+	length int
+	seq    uint64
+}
 
-	payload, err := marshalEvent(ev)
+func (df *DoryFile) appendEvent(ev *logEvent) (int64, int, uint64, error) {
+	placed, err := df.appendEvents([]*logEvent{ev})
 	if err != nil {
 		return 0, 0, 0, err
 	}
+	return placed[0].offset, placed[0].length, placed[0].seq, nil
+}
 
-	seq := df.nextSeq + 1
-	if _, err := df.knowledge.WriteString(EventDelim + "\n"); err != nil {
-		return 0, 0, 0, err
+func (df *DoryFile) appendEvents(events []*logEvent) ([]placedEvent, error) {
+	if df.knowledge == nil {
+		return nil, fmt.Errorf("knowledge file is not open")
 	}
-	if _, err := df.knowledge.Write(payload); err != nil {
-		return 0, 0, 0, err
+	stat, err := df.knowledge.Stat()
+	if err != nil {
+		return nil, err
+	}
+	offset := stat.Size()
+
+	var buf []byte
+	placed := make([]placedEvent, 0, len(events))
+	seq := df.nextSeq
+	now := eventTime()
+	for _, ev := range events {
+		if ev.At.IsZero() {
+			ev.At = now
+		}
+		payload, err := marshalEvent(ev)
+		if err != nil {
+			return nil, err
+		}
+		seq++
+		buf = append(buf, EventDelim+"\n"...)
+		payloadOffset := offset + int64(len(buf))
+		buf = append(buf, payload...)
+		placed = append(placed, placedEvent{offset: payloadOffset, length: len(payload), seq: seq})
+	}
+
+	if _, err := df.knowledge.Write(buf); err != nil {
+		return nil, err
 	}
 	if err := df.knowledge.Sync(); err != nil {
-		return 0, 0, 0, err
+		return nil, err
 	}
 
-	payloadOffset := start + int64(len(EventDelim)+1)
 	df.nextSeq = seq
-	df.logOffset = payloadOffset + int64(len(payload))
-	return payloadOffset, len(payload), seq, nil
+	df.logOffset = offset + int64(len(buf))
+	return placed, nil
+}
+
+// eventTime is the time recorded on events written now.
+func eventTime() time.Time {
+	return time.Now().UTC().Truncate(time.Second)
 }
 
 func marshalEvent(ev *logEvent) ([]byte, error) {
@@ -128,7 +185,9 @@ func (df *DoryFile) removeDeletedID(id string) bool {
 
 // UpdateState updates the session state.
 func (df *DoryFile) UpdateState(state *State) error {
-	ev := &logEvent{Op: opState, State: cloneState(state)}
+	copied := cloneState(state)
+	normalizeState(copied)
+	ev := &logEvent{Op: opState, State: copied}
 	payloadOffset, payloadLen, seq, err := df.appendEvent(ev)
 	if err != nil {
 		return err
@@ -139,7 +198,8 @@ func (df *DoryFile) UpdateState(state *State) error {
 	return df.saveIndex()
 }
 
-// Compact rewrites the knowledge file, removing deleted entries.
+// Compact rewrites the knowledge file, removing deleted entries, and ends
+// it with a compact marker event.
 func (df *DoryFile) Compact() error {
 	entries := df.sortedLiveEntries()
 	state := cloneState(df.Index.State)
@@ -165,7 +225,9 @@ func (df *DoryFile) Compact() error {
 	seq := uint64(0)
 	currentOffset := int64(len(header))
 
+	now := eventTime()
 	writeEvent := func(ev *logEvent) (int64, int, uint64, error) {
+		ev.At = now
 		payload, err := marshalEvent(ev)
 		if err != nil {
 			return 0, 0, 0, err
@@ -206,6 +268,16 @@ func (df *DoryFile) Compact() error {
 		df.nextSeq = eventSeq
 	}
 
+	// The marker ends the rewritten events, so a reader following the log
+	// can tell them from events appended after compaction.
+	_, _, markerSeq, err := writeEvent(&logEvent{Op: opCompact})
+	if err != nil {
+		tmpFile.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	df.nextSeq = markerSeq
+
 	if err := tmpFile.Sync(); err != nil {
 		tmpFile.Close()
 		os.Remove(tmpPath)
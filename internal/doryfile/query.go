@@ -23,6 +23,11 @@ func (df *DoryFile) sortedLiveEntries() []*Entry {
 	return entries
 }
 
+// IsEmpty reports whether the state holds nothing worth keeping.
+func (s *State) IsEmpty() bool {
+	return isStateEmpty(s)
+}
+
 func isStateEmpty(state *State) bool {
 	if state == nil {
 		return true
@@ -33,6 +38,8 @@ func isStateEmpty(state *State) bool {
 		len(state.Next) == 0 &&
 		len(state.WorkingFiles) == 0 &&
 		len(state.OpenQuestions) == 0 &&
+		state.TaskSeq == 0 &&
+		state.QuestionSeq == 0 &&
 		state.LastUpdated == ""
 }
 
@@ -78,6 +85,11 @@ func (df *DoryFile) Entries() map[string]*MemoryEntry {
 	return result
 }
 
+// Seq returns the sequence number of the last applied event.
+func (df *DoryFile) Seq() uint64 {
+	return df.nextSeq
+}
+
 // DumpKnowledge returns the raw knowledge file content.
 func (df *DoryFile) DumpKnowledge() (string, error) {
 	data, err := os.ReadFile(df.KnowledgePath)
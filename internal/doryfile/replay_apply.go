@@ -3,17 +3,15 @@ package doryfile
 import "fmt"
 
 func (df *DoryFile) applyEvent(seq uint64, ev *logEvent, payloadOffset int64, payloadLen int) error {
+	if err := ev.validate(); err != nil {
+		return err
+	}
 	switch ev.Op {
 	case opItemCreate, opItemUpdate:
-		if ev.Item == nil || ev.Item.ID == "" {
-			return fmt.Errorf("invalid %s event: missing item", ev.Op)
-		}
 		df.entries[ev.Item.ID] = memoryEntryFromEntry(ev.Item, payloadOffset, payloadLen)
 		df.removeDeletedID(ev.Item.ID)
+		df.noteAlias(ev.Item.Alias)
 	case opItemDelete:
-		if ev.ID == "" {
-			return fmt.Errorf("invalid %s event: missing id", ev.Op)
-		}
 		delete(df.entries, ev.ID)
 		if !containsString(df.Index.Deleted, ev.ID) {
 			df.Index.Deleted = append(df.Index.Deleted, ev.ID)
@@ -21,11 +19,14 @@ func (df *DoryFile) applyEvent(seq uint64, ev *logEvent, payloadOffset int64, pa
 	case opState:
 		if ev.State != nil {
 			df.Index.State = cloneState(ev.State)
+			normalizeState(df.Index.State)
 		}
+	case opTask:
+		upsertTask(df.Index.State, ev.Task)
+	case opQuestion:
+		upsertQuestion(df.Index.State, ev.Question)
 	case opCompact:
 		// Metadata marker only.
-	default:
-		return fmt.Errorf("unknown event op %q", ev.Op)
 	}
 
 	if seq > df.nextSeq {
@@ -34,6 +35,32 @@ func (df *DoryFile) applyEvent(seq uint64, ev *logEvent, payloadOffset int64, pa
 	return nil
 }
 
+// validate checks that an event has the payload its op needs.
+func (ev *logEvent) validate() error {
+	switch ev.Op {
+	case opItemCreate, opItemUpdate:
+		if ev.Item == nil || ev.Item.ID == "" {
+			return fmt.Errorf("invalid %s event: missing item", ev.Op)
+		}
+	case opItemDelete:
+		if ev.ID == "" {
+			return fmt.Errorf("invalid %s event: missing id", ev.Op)
+		}
+	case opTask:
+		if ev.Task == nil || ev.Task.ID == "" {
+			return fmt.Errorf("invalid %s event: missing task", ev.Op)
+		}
+	case opQuestion:
+		if ev.Question == nil || ev.Question.ID == "" {
+			return fmt.Errorf("invalid %s event: missing question", ev.Op)
+		}
+	case opState, opCompact:
+	default:
+		return fmt.Errorf("unknown event op %q", ev.Op)
+	}
+	return nil
+}
+
 func memoryEntryFromEntry(entry *Entry, payloadOffset int64, payloadLen int) *MemoryEntry {
 	return &MemoryEntry{
 		Offset:   payloadOffset,
@@ -45,6 +72,8 @@ func memoryEntryFromEntry(entry *Entry, payloadOffset int64, payloadLen int) *Me
 		Oneliner: entry.Oneliner,
 		Created:  entry.Created,
 		Refs:     append([]string(nil), entry.Refs...),
+		Alias:    entry.Alias,
+		Pinned:   entry.Pinned,
 	}
 }
 
@@ -62,8 +91,8 @@ func cloneState(state *State) *State {
 		return nil
 	}
 	copied := *state
-	copied.Next = append([]string(nil), state.Next...)
+	copied.Next = append([]Task(nil), state.Next...)
 	copied.WorkingFiles = append([]string(nil), state.WorkingFiles...)
-	copied.OpenQuestions = append([]string(nil), state.OpenQuestions...)
+	copied.OpenQuestions = append([]Question(nil), state.OpenQuestions...)
 	return &copied
 }
@@ -0,0 +1,146 @@
+package doryfile
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	taskIDPrefix     = "T-"
+	questionIDPrefix = "Q-"
+)
+
+// AppendTask records a single task change without rewriting the whole state.
+func (df *DoryFile) AppendTask(task *Task) error {
+	if task == nil || task.ID == "" {
+		return fmt.Errorf("task id is required")
+	}
+	copied := *task
+	ev := &logEvent{Op: opTask, Task: &copied}
+	payloadOffset, payloadLen, seq, err := df.appendEvent(ev)
+	if err != nil {
+		return err
+	}
+	if err := df.applyEvent(seq, ev, payloadOffset, payloadLen); err != nil {
+		return err
+	}
+	return df.saveIndex()
+}
+
+// AppendQuestion records a single question change without rewriting the whole state.
+func (df *DoryFile) AppendQuestion(question *Question) error {
+	if question == nil || question.ID == "" {
+		return fmt.Errorf("question id is required")
+	}
+	copied := *question
+	ev := &logEvent{Op: opQuestion, Question: &copied}
+	payloadOffset, payloadLen, seq, err := df.appendEvent(ev)
+	if err != nil {
+		return err
+	}
+	if err := df.applyEvent(seq, ev, payloadOffset, payloadLen); err != nil {
+		return err
+	}
+	return df.saveIndex()
+}
+
+// NextTaskID returns the ID the next new task will receive.
+func (df *DoryFile) NextTaskID() string {
+	return fmt.Sprintf("%s%d", taskIDPrefix, df.Index.State.TaskSeq+1)
+}
+
+// NextQuestionID returns the ID the next new question will receive.
+func (df *DoryFile) NextQuestionID() string {
+	return fmt.Sprintf("%s%d", questionIDPrefix, df.Index.State.QuestionSeq+1)
+}
+
+// FindTask returns the task with the given ID, if present.
+func (state *State) FindTask(id string) (*Task, bool) {
+	for i := range state.Next {
+		if strings.EqualFold(state.Next[i].ID, id) {
+			return &state.Next[i], true
+		}
+	}
+	return nil, false
+}
+
+// FindQuestion returns the question with the given ID, if present.
+func (state *State) FindQuestion(id string) (*Question, bool) {
+	for i := range state.OpenQuestions {
+		if strings.EqualFold(state.OpenQuestions[i].ID, id) {
+			return &state.OpenQuestions[i], true
+		}
+	}
+	return nil, false
+}
+
+// normalizeState assigns IDs and default statuses to tasks and questions.
+// Legacy logs stored both lists as plain strings, so entries may arrive bare.
+func normalizeState(state *State) {
+	if state == nil {
+		return
+	}
+	for _, task := range state.Next {
+		if n := seqFromID(task.ID, taskIDPrefix); n > state.TaskSeq {
+			state.TaskSeq = n
+		}
+	}
+	for i := range state.Next {
+		if state.Next[i].ID == "" {
+			state.TaskSeq++
+			state.Next[i].ID = fmt.Sprintf("%s%d", taskIDPrefix, state.TaskSeq)
+		}
+		if state.Next[i].Status == "" {
+			state.Next[i].Status = TaskTodo
+		}
+	}
+
+	for _, question := range state.OpenQuestions {
+		if n := seqFromID(question.ID, questionIDPrefix); n > state.QuestionSeq {
+			state.QuestionSeq = n
+		}
+	}
+	for i := range state.OpenQuestions {
+		if state.OpenQuestions[i].ID == "" {
+			state.QuestionSeq++
+			state.OpenQuestions[i].ID = fmt.Sprintf("%s%d", questionIDPrefix, state.QuestionSeq)
+		}
+		if state.OpenQuestions[i].Status == "" {
+			state.OpenQuestions[i].Status = QuestionOpen
+		}
+	}
+}
+
+func upsertTask(state *State, task *Task) {
+	if existing, ok := state.FindTask(task.ID); ok {
+		*existing = *task
+	} else {
+		state.Next = append(state.Next, *task)
+	}
+	if n := seqFromID(task.ID, taskIDPrefix); n > state.TaskSeq {
+		state.TaskSeq = n
+	}
+}
+
+func upsertQuestion(state *State, question *Question) {
+	if existing, ok := state.FindQuestion(question.ID); ok {
+		*existing = *question
+	} else {
+		state.OpenQuestions = append(state.OpenQuestions, *question)
+	}
+	if n := seqFromID(question.ID, questionIDPrefix); n > state.QuestionSeq {
+		state.QuestionSeq = n
+	}
+}
+
+func seqFromID(id, prefix string) int {
+	if !strings.HasPrefix(id, prefix) {
+		return 0
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(id, prefix))
+	if err != nil {
+		return 0
+	}
+	return n
+}
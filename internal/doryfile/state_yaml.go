@@ -0,0 +1,33 @@
+package doryfile
+
+import "gopkg.in/yaml.v3"
+
+// UnmarshalYAML accepts legacy plain-string next steps.
+func (t *Task) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*t = Task{Text: value.Value}
+		return nil
+	}
+	type rawTask Task
+	var raw rawTask
+	if err := value.Decode(&raw); err != nil {
+		return err
+	}
+	*t = Task(raw)
+	return nil
+}
+
+// UnmarshalYAML accepts legacy plain-string open questions.
+func (q *Question) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*q = Question{Text: value.Value}
+		return nil
+	}
+	type rawQuestion Question
+	var raw rawQuestion
+	if err := value.Decode(&raw); err != nil {
+		return err
+	}
+	*q = Question(raw)
+	return nil
+}
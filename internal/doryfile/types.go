@@ -20,31 +20,66 @@ const (
 	opItemUpdate = "item.update"
 	opItemDelete = "item.delete"
 	opState      = "state.update"
+	opTask       = "state.task"
+	opQuestion   = "state.question"
 	opCompact    = "compact"
 )
 
+// Task statuses.
+const (
+	TaskTodo  = "todo"
+	TaskDoing = "doing"
+	TaskDone  = "done"
+)
+
+// Question statuses.
+const (
+	QuestionOpen     = "open"
+	QuestionAnswered = "answered"
+)
+
 // Entry represents a single knowledge item.
 type Entry struct {
-	ID       string    `yaml:"id"`
-	Type     string    `yaml:"type"`
-	Topic    string    `yaml:"topic,omitempty"`
-	Domain   string    `yaml:"domain,omitempty"`
-	Severity string    `yaml:"severity,omitempty"`
-	Oneliner string    `yaml:"oneliner"`
-	Created  time.Time `yaml:"created"`
-	Refs     []string  `yaml:"refs,omitempty"`
-	Body     string    `yaml:"body,omitempty"`
+	ID       string    `json:"id" yaml:"id"`
+	Type     string    `json:"type" yaml:"type"`
+	Topic    string    `json:"topic,omitempty" yaml:"topic,omitempty"`
+	Domain   string    `json:"domain,omitempty" yaml:"domain,omitempty"`
+	Severity string    `json:"severity,omitempty" yaml:"severity,omitempty"`
+	Oneliner string    `json:"oneliner" yaml:"oneliner"`
+	Created  time.Time `json:"created" yaml:"created"`
+	Refs     []string  `json:"refs,omitempty" yaml:"refs,omitempty"`
+	Alias    string    `json:"alias,omitempty" yaml:"alias,omitempty"`
+	Pinned   bool      `json:"pinned,omitempty" yaml:"pinned,omitempty"`
+	Body     string    `json:"body,omitempty" yaml:"body,omitempty"`
+}
+
+// Task is a trackable next step in session state.
+type Task struct {
+	ID     string `json:"id" yaml:"id"`
+	Text   string `json:"text" yaml:"text"`
+	Status string `json:"status" yaml:"status"`
+}
+
+// Question is an open question in session state.
+type Question struct {
+	ID         string `json:"id" yaml:"id"`
+	Text       string `json:"text" yaml:"text"`
+	Status     string `json:"status" yaml:"status"`
+	Answer     string `json:"answer,omitempty" yaml:"answer,omitempty"`
+	AnsweredBy string `json:"answered_by,omitempty" yaml:"answered_by,omitempty"`
 }
 
 // State represents session state.
 type State struct {
-	Goal          string   `yaml:"goal,omitempty"`
-	Progress      string   `yaml:"progress,omitempty"`
-	Blocker       string   `yaml:"blocker,omitempty"`
-	Next          []string `yaml:"next,omitempty"`
-	WorkingFiles  []string `yaml:"working_files,omitempty"`
-	OpenQuestions []string `yaml:"open_questions,omitempty"`
-	LastUpdated   string   `yaml:"last_updated,omitempty"`
+	Goal          string     `json:"goal,omitempty" yaml:"goal,omitempty"`
+	Progress      string     `json:"progress,omitempty" yaml:"progress,omitempty"`
+	Blocker       string     `json:"blocker,omitempty" yaml:"blocker,omitempty"`
+	Next          []Task     `json:"next,omitempty" yaml:"next,omitempty"`
+	WorkingFiles  []string   `json:"working_files,omitempty" yaml:"working_files,omitempty"`
+	OpenQuestions []Question `json:"open_questions,omitempty" yaml:"open_questions,omitempty"`
+	TaskSeq       int        `json:"task_seq,omitempty" yaml:"task_seq,omitempty"`
+	QuestionSeq   int        `json:"question_seq,omitempty" yaml:"question_seq,omitempty"`
+	LastUpdated   string     `json:"last_updated,omitempty" yaml:"last_updated,omitempty"`
 }
 
 // SnapshotHead stores current-head metadata for snapshots.
@@ -56,6 +91,8 @@ type SnapshotHead struct {
 	Oneliner     string    `yaml:"oneliner"`
 	Created      time.Time `yaml:"created"`
 	Refs         []string  `yaml:"refs,omitempty"`
+	Alias        string    `yaml:"alias,omitempty"`
+	Pinned       bool      `yaml:"pinned,omitempty"`
 	BodyOffset   int64     `yaml:"body_offset"`
 	BodyLen      int       `yaml:"body_len"`
 	LastEventSeq uint64    `yaml:"last_event_seq"`
@@ -71,6 +108,7 @@ type Index struct {
 	AppliedSeq  uint64                   `yaml:"applied_seq,omitempty"`
 	LogOffset   int64                    `yaml:"log_offset,omitempty"`
 	Heads       map[string]*SnapshotHead `yaml:"heads,omitempty"`
+	AliasSeq    map[string]int           `yaml:"alias_seq,omitempty"`
 }
 
 // MemoryEntry holds offset and metadata for fast lookup (in-memory only).
@@ -84,6 +122,8 @@ type MemoryEntry struct {
 	Oneliner string
 	Created  time.Time
 	Refs     []string
+	Alias    string
+	Pinned   bool
 }
 
 // DoryFile represents the dory storage.
@@ -96,6 +136,8 @@ type DoryFile struct {
 
 	nextSeq   uint64
 	logOffset int64
+	// stopSeq ends replay after this event when set (see OpenAt).
+	stopSeq uint64
 
 	// In-memory index (computed on open).
 	entries map[string]*MemoryEntry
@@ -103,10 +145,15 @@ type DoryFile struct {
 
 type logEvent struct {
 	Op string `yaml:"op"`
-
-	Item  *Entry `yaml:"item,omitempty"`
-	ID    string `yaml:"id,omitempty"`
-	State *State `yaml:"state,omitempty"`
+	// At is when the event was written. Logs written before it was
+	// recorded have none.
+	At time.Time `yaml:"at,omitempty"`
+
+	Item     *Entry    `yaml:"item,omitempty"`
+	ID       string    `yaml:"id,omitempty"`
+	State    *State    `yaml:"state,omitempty"`
+	Task     *Task     `yaml:"task,omitempty"`
+	Question *Question `yaml:"question,omitempty"`
 }
 
 // CorruptionError indicates malformed knowledge log content.
@@ -0,0 +1,136 @@
+package harvest
+
+import (
+	"strings"
+	"testing"
+)
+
+const claudeSession = `{"type":"user","message":{"role":"user","content":"Fix the failing tests"}}
+{"type":"assistant","message":{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"go test ./..."}}]}}
+{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","is_error":true,"content":"Exit code 1\n--- FAIL: TestParse\nparse_test.go:12: undefined: tokenize"}]}}
+{"type":"assistant","message":{"role":"assistant","content":[{"type":"tool_use","id":"t2","name":"Bash","input":{"command":"cd pkg && go test ./... -run TestParse"}}]}}
+{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t2","is_error":true,"content":"Exit code 1\nparse_test.go:12: undefined: tokenize"}]}}
+{"type":"assistant","message":{"role":"assistant","content":[{"type":"text","text":"The tokenizer was renamed to lex in the refactor."},{"type":"tool_use","id":"t3","name":"Edit","input":{"file_path":"/src/parse.go","old_string":"tokenize(","new_string":"lex("}}]}}
+{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t3","content":"updated"}]}}
+{"type":"assistant","message":{"role":"assistant","content":[{"type":"tool_use","id":"t4","name":"Edit","input":{"file_path":"/src/util.go","old_string":"max := 10","new_string":"max := 100"}}]}}
+{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t4","content":"updated"}]}}
+{"type":"user","isMeta":true,"message":{"role":"user","content":"Lesson: meta lines are not part of the conversation"}}
+{"type":"assistant","message":{"role":"assistant","content":[{"type":"text","text":"Raising the limit was unrelated to the failure. Undoing it."},{"type":"tool_use","id":"t5","name":"Edit","input":{"file_path":"/src/util.go","old_string":"max := 100","new_string":"max := 10"}}]}}
+{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t5","content":"updated"}]}}
+{"type":"assistant","message":{"role":"assistant","content":[{"type":"tool_use","id":"t6","name":"Bash","input":{"command":"go test ./..."}}]}}
+{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t6","content":"ok  example/pkg 0.01s"}]}}
+{"type":"assistant","message":{"role":"assistant","content":[{"type":"text","text":"All green.\n\n**Lesson:** rename the parser call sites whenever the lexer API changes."}]}}
+not json
+`
+
+const codexSession = `{"timestamp":"2026-01-01T00:00:00Z","type":"session_meta","payload":{"id":"s1","cwd":"/src"}}
+{"type":"response_item","payload":{"type":"message","role":"user","content":[{"type":"input_text","text":"<environment_context>cwd</environment_context>"}]}}
+{"type":"response_item","payload":{"type":"message","role":"user","content":[{"type":"input_text","text":"Make the build pass"}]}}
+{"type":"response_item","payload":{"type":"function_call","name":"shell","arguments":"{\"command\":[\"bash\",\"-lc\",\"make build\"]}","call_id":"c1"}}
+{"type":"response_item","payload":{"type":"function_call_output","call_id":"c1","output":"{\"output\":\"cc: error: missing -lssl\",\"metadata\":{\"exit_code\":2}}"}}
+{"type":"response_item","payload":{"type":"function_call","name":"shell","arguments":"{\"command\":[\"bash\",\"-lc\",\"make build\"]}","call_id":"c2"}}
+{"type":"response_item","payload":{"type":"function_call_output","call_id":"c2","output":"{\"output\":\"cc: error: missing -lssl\",\"metadata\":{\"exit_code\":2}}"}}
+{"type":"response_item","payload":{"type":"custom_tool_call","name":"apply_patch","call_id":"c3","input":"*** Begin Patch\n*** Update File: Makefile\n@@\n-LIBS =\n+LIBS = -lssl\n*** End Patch"}}
+{"type":"response_item","payload":{"type":"custom_tool_call_output","call_id":"c3","output":"Success"}}
+{"type":"response_item","payload":{"type":"function_call","name":"shell","arguments":"{\"command\":[\"bash\",\"-lc\",\"make build\"]}","call_id":"c4"}}
+{"type":"response_item","payload":{"type":"function_call_output","call_id":"c4","output":"{\"output\":\"built\",\"metadata\":{\"exit_code\":0}}"}}
+{"type":"response_item","payload":{"type":"message","role":"assistant","content":[{"type":"output_text","text":"Note: the build needs libssl linked explicitly on this image."}]}}
+`
+
+func TestParse(t *testing.T) {
+	events, err := Parse(strings.NewReader(claudeSession))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var commands, edits, failed int
+	for _, e := range events {
+		switch e.Kind {
+		case EventCommand:
+			commands++
+		case EventEdit:
+			edits++
+		case EventUser:
+			if strings.Contains(e.Text, "meta lines") {
+				t.Fatalf("expected meta lines to be skipped: %v", e)
+			}
+		}
+		if e.Failed {
+			failed++
+		}
+	}
+	if commands != 3 || edits != 3 || failed != 2 {
+		t.Fatalf("expected 3 commands, 3 edits, 2 failures, got %d, %d, %d: %v", commands, edits, failed, events)
+	}
+
+	events, err = Parse(strings.NewReader(codexSession))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 6 || events[0].Text != "Make the build pass" || events[1].Command != "make build" || !events[1].Failed || events[4].Failed {
+		t.Fatalf("unexpected codex events: %v", events)
+	}
+	if edit := events[3]; edit.File != "Makefile" || edit.Old != "LIBS =" || edit.New != "LIBS = -lssl" {
+		t.Fatalf("unexpected patch edit: %+v", edit)
+	}
+}
+
+func TestFind(t *testing.T) {
+	events, err := Parse(strings.NewReader(claudeSession))
+	if err != nil {
+		t.Fatal(err)
+	}
+	drafts := Find(events)
+	if len(drafts) != 3 {
+		t.Fatalf("expected 3 drafts, got %+v", drafts)
+	}
+
+	revert, retry, note := drafts[0], drafts[1], drafts[2]
+	if revert.Signal != SignalRevert || revert.Line != 11 || revert.Oneliner != "Raising the limit was unrelated to the failure" {
+		t.Fatalf("unexpected revert draft %+v", revert)
+	}
+	if !strings.Contains(revert.Body, "- max := 100\n+ max := 10") {
+		t.Fatalf("expected the reverted diff in the body:\n%s", revert.Body)
+	}
+
+	if retry.Signal != SignalRetry || retry.Oneliner != "go test: parse_test.go:12: undefined: tokenize" {
+		t.Fatalf("unexpected retry draft %+v", retry)
+	}
+	for _, want := range []string{"## Symptom", "failed 2 times", "undefined: tokenize", "## Root Cause\n\nThe tokenizer was renamed", "- Edited `/src/parse.go`"} {
+		if !strings.Contains(retry.Body, want) {
+			t.Fatalf("expected %q in the retry body:\n%s", want, retry.Body)
+		}
+	}
+	if strings.Contains(retry.Body, "util.go") {
+		t.Fatalf("expected the reverted edit to be left out of the fix:\n%s", retry.Body)
+	}
+
+	if note.Signal != SignalNote || note.Oneliner != "rename the parser call sites whenever the lexer API changes" {
+		t.Fatalf("unexpected note draft %+v", note)
+	}
+
+	events, err = Parse(strings.NewReader(codexSession))
+	if err != nil {
+		t.Fatal(err)
+	}
+	drafts = Find(events)
+	if len(drafts) != 2 || drafts[0].Oneliner != "make build: cc: error: missing -lssl" || !strings.Contains(drafts[0].Body, "- Edited `Makefile`") {
+		t.Fatalf("unexpected codex drafts %+v", drafts)
+	}
+	if drafts[1].Signal != SignalNote || !strings.Contains(drafts[1].Body, "- Edited `Makefile`") {
+		t.Fatalf("unexpected codex note %+v", drafts[1])
+	}
+}
+
+func TestCommandKey(t *testing.T) {
+	for command, want := range map[string]string{
+		"go test ./...":                   "go test",
+		"cd pkg && go test -run X ./...":  "go test",
+		"GOFLAGS=-mod=mod go build ./...": "go build",
+		"pytest -x tests/":                "pytest",
+		"cd /tmp":                         "",
+	} {
+		if got := commandKey(command); got != want {
+			t.Errorf("commandKey(%q) = %q, want %q", command, got, want)
+		}
+	}
+}
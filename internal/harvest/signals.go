@@ -0,0 +1,365 @@
+package harvest
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Signals a draft can come from.
+const (
+	SignalRetry  = "retry"  // a command failed repeatedly, then passed
+	SignalNote   = "note"   // a "lesson:" or "note:" written in a message
+	SignalRevert = "revert" // an edit was undone
+)
+
+// Draft is a proposed lesson, with a body in the sections of the lesson
+// template filled from the turns around the signal.
+type Draft struct {
+	Signal   string
+	Line     int
+	Oneliner string
+	Body     string
+}
+
+const (
+	maxOneliner    = 100
+	maxOutputLines = 15
+	maxParagraph   = 600
+)
+
+var (
+	notePhrase = regexp.MustCompile(`(?im)^[ \t>*_-]*(?:lessons? learned|lesson|note to self|note|nb|til|gotcha)[*_]*[ \t]*[:—–]+[*_ \t]*(.+)`)
+	errorLine  = regexp.MustCompile(`(?i)error|fail|panic|cannot|can't|undefined|not found|no such|denied|exception|traceback|invalid`)
+	revertCmd  = regexp.MustCompile(`^git\s+(?:checkout\s+(?:\S+\s+)?--|restore)\s+(.+)$`)
+	envAssign  = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*=\S*$`)
+	shellSep   = regexp.MustCompile(`&&|\|\||;|\|`)
+	blankLine  = regexp.MustCompile(`\n\s*\n`)
+)
+
+// Find returns the drafts suggested by a session, in transcript order.
+func Find(events []Event) []Draft {
+	var drafts []Draft
+	drafts = append(drafts, findRetries(events)...)
+	drafts = append(drafts, findNotes(events)...)
+	drafts = append(drafts, findReverts(events)...)
+	sort.SliceStable(drafts, func(i, j int) bool { return drafts[i].Line < drafts[j].Line })
+	return drafts
+}
+
+// findRetries proposes a lesson when the same command failed at least twice
+// and then passed: whatever changed in between was the fix.
+func findRetries(events []Event) []Draft {
+	var drafts []Draft
+	failures := make(map[string][]int)
+	for i, e := range events {
+		if e.Kind != EventCommand {
+			continue
+		}
+		key := commandKey(e.Command)
+		if key == "" {
+			continue
+		}
+		if e.Failed {
+			failures[key] = append(failures[key], i)
+			continue
+		}
+		failed := failures[key]
+		delete(failures, key)
+		if len(failed) < 2 {
+			continue
+		}
+		first, last := failed[0], failed[len(failed)-1]
+		symptom := firstErrorLine(events[last].Text)
+		oneliner := key + " failed"
+		if symptom != "" {
+			oneliner = key + ": " + symptom
+		}
+
+		var body body
+		body.section("Symptom", fmt.Sprintf("`%s` failed %d times before it passed. Last error:\n\n%s",
+			events[last].Command, len(failed), codeBlock(events[last].Text)))
+		body.section("Root Cause", reasoning(events[last+1:i]))
+		fix := changes(events[first+1 : i])
+		fix = append(fix, fmt.Sprintf("- Ran `%s`, which passed", e.Command))
+		body.section("Fix", strings.Join(fix, "\n"))
+		drafts = append(drafts, body.draft(SignalRetry, e.Line, oneliner))
+	}
+	return drafts
+}
+
+// findNotes proposes a lesson for each "lesson: ..." or "note: ..." line in
+// the conversation.
+func findNotes(events []Event) []Draft {
+	var drafts []Draft
+	for i, e := range events {
+		if e.Kind != EventUser && e.Kind != EventAssistant {
+			continue
+		}
+		for _, paragraph := range paragraphs(e.Text) {
+			for _, m := range notePhrase.FindAllStringSubmatch(paragraph, -1) {
+				if len(strings.Fields(m[1])) < 4 {
+					continue
+				}
+				var body body
+				body.section("Symptom", recentSymptom(events[:i]))
+				body.section("Root Cause", clip(strings.TrimSpace(m[1]), maxParagraph))
+				body.section("Fix", strings.Join(changes(sinceLastUser(events[:i])), "\n"))
+				drafts = append(drafts, body.draft(SignalNote, e.Line, firstSentence(m[1])))
+			}
+		}
+	}
+	return drafts
+}
+
+// findReverts proposes a lesson when an edit is undone, either by an edit
+// that swaps it back or by restoring the file with git.
+func findReverts(events []Event) []Draft {
+	var drafts []Draft
+	for i, e := range events {
+		if e.Failed {
+			continue
+		}
+		var undone int
+		switch e.Kind {
+		case EventEdit:
+			undone = undoneEdit(events, i)
+		case EventCommand:
+			undone = restoredEdit(events, i)
+		default:
+			continue
+		}
+		if undone < 0 {
+			continue
+		}
+		edit := events[undone]
+		why := reasoning(events[undone+1 : i])
+		oneliner := "Reverted change to " + filepath.Base(edit.File)
+		if why != "" {
+			oneliner = firstSentence(why)
+		}
+
+		var body body
+		body.section("Symptom", recentSymptom(events[undone+1:i]))
+		body.section("Root Cause", why)
+		body.section("Fix", fmt.Sprintf("Reverted the change to `%s`:\n\n%s", edit.File, diffBlock(edit.Old, edit.New)))
+		drafts = append(drafts, body.draft(SignalRevert, e.Line, oneliner))
+	}
+	return drafts
+}
+
+// undoneEdit returns the earlier edit that the edit at i swaps back, or -1.
+func undoneEdit(events []Event, i int) int {
+	e := events[i]
+	if strings.TrimSpace(e.Old) == "" && strings.TrimSpace(e.New) == "" {
+		return -1
+	}
+	for j := i - 1; j >= 0; j-- {
+		prev := events[j]
+		if prev.Kind != EventEdit || prev.Failed || prev.File != e.File {
+			continue
+		}
+		if strings.TrimSpace(prev.New) == strings.TrimSpace(e.Old) && strings.TrimSpace(prev.Old) == strings.TrimSpace(e.New) {
+			return j
+		}
+	}
+	return -1
+}
+
+// restoredEdit returns the last edit to a file the git command at i
+// restores, or -1.
+func restoredEdit(events []Event, i int) int {
+	m := revertCmd.FindStringSubmatch(strings.TrimSpace(firstSegment(events[i].Command)))
+	if m == nil || strings.Contains(m[1], "--staged") {
+		return -1
+	}
+	files := strings.Fields(m[1])
+	for j := i - 1; j >= 0; j-- {
+		prev := events[j]
+		if prev.Kind != EventEdit || prev.Failed {
+			continue
+		}
+		for _, file := range files {
+			if file == prev.File || strings.HasSuffix(prev.File, "/"+strings.TrimPrefix(file, "./")) {
+				return j
+			}
+		}
+	}
+	return -1
+}
+
+// commandKey identifies a command across retries: the program and its
+// subcommand, ignoring a leading cd and environment assignments.
+func commandKey(command string) string {
+	fields := strings.Fields(firstSegment(command))
+	for len(fields) > 0 && envAssign.MatchString(fields[0]) {
+		fields = fields[1:]
+	}
+	if len(fields) == 0 {
+		return ""
+	}
+	if len(fields) > 1 && !strings.HasPrefix(fields[1], "-") {
+		return fields[0] + " " + fields[1]
+	}
+	return fields[0]
+}
+
+// firstSegment returns the first command of a shell line that is not a cd.
+func firstSegment(command string) string {
+	for _, segment := range shellSep.Split(command, -1) {
+		segment = strings.TrimSpace(segment)
+		if segment != "" && segment != "cd" && !strings.HasPrefix(segment, "cd ") {
+			return segment
+		}
+	}
+	return ""
+}
+
+// reasoning is what the assistant said in events, up to a paragraph.
+func reasoning(events []Event) string {
+	for _, e := range events {
+		if e.Kind == EventAssistant {
+			return clip(paragraphs(e.Text)[0], maxParagraph)
+		}
+	}
+	return ""
+}
+
+// recentSymptom is the output of the last failed command in events, or else
+// the last thing the user said.
+func recentSymptom(events []Event) string {
+	for j := len(events) - 1; j >= 0; j-- {
+		e := events[j]
+		if e.Kind == EventCommand && e.Failed {
+			return fmt.Sprintf("`%s` failed:\n\n%s", e.Command, codeBlock(e.Text))
+		}
+		if e.Kind == EventUser {
+			return quote(clip(e.Text, maxParagraph))
+		}
+	}
+	return ""
+}
+
+// sinceLastUser returns the events after the last user message.
+func sinceLastUser(events []Event) []Event {
+	for j := len(events) - 1; j >= 0; j-- {
+		if events[j].Kind == EventUser {
+			return events[j+1:]
+		}
+	}
+	return events
+}
+
+// changes lists the files edited in events, once each, leaving out edits
+// that were undone.
+func changes(events []Event) []string {
+	reverted := make(map[int]bool)
+	for i, e := range events {
+		if e.Kind == EventEdit && !e.Failed {
+			if j := undoneEdit(events, i); j >= 0 {
+				reverted[i], reverted[j] = true, true
+			}
+		}
+	}
+	var lines []string
+	seen := make(map[string]bool)
+	for i, e := range events {
+		if e.Kind == EventEdit && !e.Failed && !reverted[i] && !seen[e.File] {
+			seen[e.File] = true
+			lines = append(lines, fmt.Sprintf("- Edited `%s`", e.File))
+		}
+	}
+	return lines
+}
+
+func firstErrorLine(output string) string {
+	var fallback string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || exitCodeLine.MatchString(line) {
+			continue
+		}
+		if errorLine.MatchString(line) {
+			return clip(line, maxOneliner)
+		}
+		if fallback == "" {
+			fallback = clip(line, maxOneliner)
+		}
+	}
+	return fallback
+}
+
+func firstSentence(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if i := strings.Index(text, ". "); i > 0 {
+		text = text[:i]
+	}
+	return clip(strings.TrimSuffix(text, "."), maxOneliner)
+}
+
+func paragraphs(text string) []string {
+	var out []string
+	for _, p := range blankLine.Split(strings.TrimSpace(text), -1) {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	if len(out) == 0 {
+		return []string{""}
+	}
+	return out
+}
+
+func clip(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return strings.TrimSpace(string(runes[:limit-3])) + "..."
+}
+
+func codeBlock(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) > maxOutputLines {
+		lines = append(lines[:maxOutputLines], "...")
+	}
+	return "```\n" + strings.Join(lines, "\n") + "\n```"
+}
+
+func diffBlock(old, new string) string {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(new), "\n") {
+		lines = append(lines, "- "+line)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(old), "\n") {
+		lines = append(lines, "+ "+line)
+	}
+	if len(lines) > 2*maxOutputLines {
+		lines = append(lines[:2*maxOutputLines], "...")
+	}
+	return "```diff\n" + strings.Join(lines, "\n") + "\n```"
+}
+
+func quote(text string) string {
+	return "> " + strings.ReplaceAll(text, "\n", "\n> ")
+}
+
+// body builds a draft body from the lesson template's sections, leaving out
+// the ones the transcript had nothing for.
+type body struct {
+	sections []string
+}
+
+func (b *body) section(title, text string) {
+	if text = strings.TrimSpace(text); text != "" {
+		b.sections = append(b.sections, "## "+title+"\n\n"+text)
+	}
+}
+
+func (b *body) draft(signal string, line int, oneliner string) Draft {
+	oneliner = strings.TrimSpace(oneliner)
+	text := "# " + oneliner + "\n\n" + strings.Join(b.sections, "\n\n") + "\n"
+	return Draft{Signal: signal, Line: line, Oneliner: oneliner, Body: text}
+}
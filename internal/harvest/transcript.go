@@ -0,0 +1,364 @@
+// Package harvest reads agent session transcripts and finds the moments
+// worth keeping as lessons: commands that failed until something was fixed,
+// notes the agent or user wrote down, and edits that were undone.
+package harvest
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Event kinds.
+const (
+	EventUser      = "user"
+	EventAssistant = "assistant"
+	EventCommand   = "command"
+	EventEdit      = "edit"
+)
+
+// Event is one step of a session, the same for every transcript format.
+type Event struct {
+	Line    int    // transcript line the event starts on
+	Kind    string // EventUser, EventAssistant, EventCommand, or EventEdit
+	Text    string // message text, or the output of a command
+	Command string
+	Failed  bool // the command exited non-zero, or the edit was rejected
+	File    string
+	Old     string // text an edit replaced
+	New     string // text an edit wrote
+}
+
+var exitCodeLine = regexp.MustCompile(`(?m)^\s*(?:Exit code|exit status|Process exited with code):?\s*(-?\d+)`)
+
+// Parse reads a JSON Lines transcript. It understands Claude Code sessions
+// (user/assistant messages with tool_use and tool_result blocks) and Codex
+// rollouts (response items, wrapped in a payload or not). Lines it does not
+// recognize are skipped.
+func Parse(r io.Reader) ([]Event, error) {
+	p := &parser{calls: make(map[string][]int)}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 1024*1024), 64*1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		data := strings.TrimSpace(scanner.Text())
+		if data == "" {
+			continue
+		}
+		var l rawLine
+		if err := json.Unmarshal([]byte(data), &l); err != nil {
+			continue
+		}
+		p.line = lineNo
+		p.parseLine(&l)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return p.events, nil
+}
+
+type rawLine struct {
+	IsMeta  bool            `json:"isMeta"`
+	Message *rawMessage     `json:"message"`
+	Payload json.RawMessage `json:"payload"`
+	rawItem
+}
+
+type rawMessage struct {
+	Role    string          `json:"role"`
+	Content json.RawMessage `json:"content"`
+}
+
+// rawItem is a Codex response item.
+type rawItem struct {
+	Type      string          `json:"type"`
+	Role      string          `json:"role"`
+	Content   json.RawMessage `json:"content"`
+	Name      string          `json:"name"`
+	Arguments string          `json:"arguments"`
+	Input     json.RawMessage `json:"input"`
+	CallID    string          `json:"call_id"`
+	Output    json.RawMessage `json:"output"`
+	Action    json.RawMessage `json:"action"`
+}
+
+// rawBlock is a content block of a Claude message, or a text part of a
+// Codex message.
+type rawBlock struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text"`
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Input     json.RawMessage `json:"input"`
+	ToolUseID string          `json:"tool_use_id"`
+	Content   json.RawMessage `json:"content"`
+	IsError   bool            `json:"is_error"`
+}
+
+type parser struct {
+	line   int
+	events []Event
+	calls  map[string][]int // tool call id -> events awaiting its result
+}
+
+func (p *parser) parseLine(l *rawLine) {
+	switch {
+	case l.Message != nil:
+		if !l.IsMeta {
+			p.parseClaude(l.Message)
+		}
+	case len(l.Payload) > 0 && l.Payload[0] == '{':
+		var item rawItem
+		if json.Unmarshal(l.Payload, &item) == nil {
+			p.parseCodex(&item)
+		}
+	default:
+		p.parseCodex(&l.rawItem)
+	}
+}
+
+func (p *parser) add(e Event) int {
+	e.Line = p.line
+	p.events = append(p.events, e)
+	return len(p.events) - 1
+}
+
+func (p *parser) addMessage(role, text string) {
+	text = strings.TrimSpace(text)
+	// System reminders, command wrappers, and injected instructions are not
+	// part of the conversation.
+	if text == "" || strings.HasPrefix(text, "<") {
+		return
+	}
+	switch role {
+	case "user":
+		p.add(Event{Kind: EventUser, Text: text})
+	case "assistant":
+		p.add(Event{Kind: EventAssistant, Text: text})
+	}
+}
+
+// result fills in the output of the command or edit started by call id.
+func (p *parser) result(id, output string, failed bool) {
+	for _, i := range p.calls[id] {
+		e := &p.events[i]
+		e.Text = strings.TrimSpace(output)
+		e.Failed = failed || exitFailed(output)
+	}
+	delete(p.calls, id)
+}
+
+func (p *parser) parseClaude(m *rawMessage) {
+	var text string
+	if json.Unmarshal(m.Content, &text) == nil {
+		p.addMessage(m.Role, text)
+		return
+	}
+	var blocks []rawBlock
+	if json.Unmarshal(m.Content, &blocks) != nil {
+		return
+	}
+	for _, b := range blocks {
+		switch b.Type {
+		case "text":
+			p.addMessage(m.Role, b.Text)
+		case "tool_use":
+			p.toolUse(b.ID, b.Name, b.Input)
+		case "tool_result":
+			p.result(b.ToolUseID, blockText(b.Content), b.IsError)
+		}
+	}
+}
+
+func (p *parser) toolUse(id, name string, input json.RawMessage) {
+	var in struct {
+		Command  json.RawMessage `json:"command"`
+		Cmd      json.RawMessage `json:"cmd"`
+		FilePath string          `json:"file_path"`
+		Old      string          `json:"old_string"`
+		New      string          `json:"new_string"`
+		Content  string          `json:"content"`
+		Edits    []struct {
+			Old string `json:"old_string"`
+			New string `json:"new_string"`
+		} `json:"edits"`
+	}
+	if json.Unmarshal(input, &in) != nil {
+		return
+	}
+	var started []int
+	switch name {
+	case "Bash", "shell", "shell_command", "exec_command", "local_shell":
+		command := commandString(in.Command)
+		if command == "" {
+			command = commandString(in.Cmd)
+		}
+		if command != "" {
+			started = append(started, p.add(Event{Kind: EventCommand, Command: command}))
+		}
+	case "Edit":
+		started = append(started, p.add(Event{Kind: EventEdit, File: in.FilePath, Old: in.Old, New: in.New}))
+	case "MultiEdit":
+		for _, edit := range in.Edits {
+			started = append(started, p.add(Event{Kind: EventEdit, File: in.FilePath, Old: edit.Old, New: edit.New}))
+		}
+	case "Write":
+		started = append(started, p.add(Event{Kind: EventEdit, File: in.FilePath, New: in.Content}))
+	}
+	if id != "" && len(started) > 0 {
+		p.calls[id] = started
+	}
+}
+
+func (p *parser) parseCodex(item *rawItem) {
+	switch item.Type {
+	case "message":
+		var text string
+		if json.Unmarshal(item.Content, &text) == nil {
+			p.addMessage(item.Role, text)
+			return
+		}
+		var parts []rawBlock
+		if json.Unmarshal(item.Content, &parts) == nil {
+			for _, part := range parts {
+				p.addMessage(item.Role, part.Text)
+			}
+		}
+	case "function_call":
+		p.toolUse(item.CallID, item.Name, json.RawMessage(item.Arguments))
+	case "local_shell_call":
+		p.toolUse(item.CallID, "local_shell", item.Action)
+	case "custom_tool_call":
+		var patch string
+		if item.Name == "apply_patch" && json.Unmarshal(item.Input, &patch) == nil {
+			var started []int
+			for _, e := range parsePatch(patch) {
+				started = append(started, p.add(e))
+			}
+			if item.CallID != "" && len(started) > 0 {
+				p.calls[item.CallID] = started
+			}
+		}
+	case "function_call_output", "custom_tool_call_output":
+		output, failed := codexOutput(item.Output)
+		p.result(item.CallID, output, failed)
+	}
+}
+
+// codexOutput unwraps a tool output, which is plain text or a JSON object
+// with the output and its exit code.
+func codexOutput(raw json.RawMessage) (string, bool) {
+	var text string
+	if json.Unmarshal(raw, &text) != nil {
+		text = string(raw)
+	}
+	var wrapped struct {
+		Output   string `json:"output"`
+		Metadata struct {
+			ExitCode int `json:"exit_code"`
+		} `json:"metadata"`
+	}
+	if strings.HasPrefix(strings.TrimSpace(text), "{") && json.Unmarshal([]byte(text), &wrapped) == nil {
+		return wrapped.Output, wrapped.Metadata.ExitCode != 0
+	}
+	return text, false
+}
+
+// commandString returns a shell command given as a string or as an argv,
+// unwrapping `bash -lc "..."`.
+func commandString(raw json.RawMessage) string {
+	var command string
+	if json.Unmarshal(raw, &command) == nil {
+		return strings.TrimSpace(command)
+	}
+	var argv []string
+	if json.Unmarshal(raw, &argv) != nil || len(argv) == 0 {
+		return ""
+	}
+	if len(argv) >= 3 && (argv[len(argv)-2] == "-lc" || argv[len(argv)-2] == "-c") {
+		return strings.TrimSpace(argv[len(argv)-1])
+	}
+	return strings.Join(argv, " ")
+}
+
+// blockText joins the text of a tool_result, which is a string or a list
+// of blocks.
+func blockText(raw json.RawMessage) string {
+	var text string
+	if json.Unmarshal(raw, &text) == nil {
+		return text
+	}
+	var blocks []rawBlock
+	if json.Unmarshal(raw, &blocks) != nil {
+		return ""
+	}
+	var parts []string
+	for _, b := range blocks {
+		if b.Text != "" {
+			parts = append(parts, b.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+func exitFailed(output string) bool {
+	m := exitCodeLine.FindStringSubmatch(output)
+	if m == nil {
+		return false
+	}
+	code, err := strconv.Atoi(m[1])
+	return err == nil && code != 0
+}
+
+// parsePatch turns an apply_patch body into one edit per updated file, with
+// the removed lines as Old and the added lines as New.
+func parsePatch(patch string) []Event {
+	var events []Event
+	var current *Event
+	var old, added []string
+	flush := func() {
+		if current != nil {
+			current.Old = strings.Join(old, "\n")
+			current.New = strings.Join(added, "\n")
+			events = append(events, *current)
+		}
+		current, old, added = nil, nil, nil
+	}
+	for _, line := range strings.Split(patch, "\n") {
+		switch {
+		case strings.HasPrefix(line, "*** Update File: "), strings.HasPrefix(line, "*** Add File: "):
+			flush()
+			file := line[strings.Index(line, ": ")+2:]
+			current = &Event{Kind: EventEdit, File: strings.TrimSpace(file)}
+		case strings.HasPrefix(line, "***"):
+			if !strings.HasPrefix(line, "*** Move to: ") && !strings.HasPrefix(line, "*** End of File") {
+				flush()
+			}
+		case current == nil:
+		case strings.HasPrefix(line, "-"):
+			old = append(old, line[1:])
+		case strings.HasPrefix(line, "+"):
+			added = append(added, line[1:])
+		}
+	}
+	flush()
+	return events
+}
+
+// String describes e in one line, for debugging and test failures.
+func (e Event) String() string {
+	switch e.Kind {
+	case EventCommand:
+		return fmt.Sprintf("%d command %q failed=%v", e.Line, e.Command, e.Failed)
+	case EventEdit:
+		return fmt.Sprintf("%d edit %s failed=%v", e.Line, e.File, e.Failed)
+	default:
+		return fmt.Sprintf("%d %s %q", e.Line, e.Kind, e.Text)
+	}
+}
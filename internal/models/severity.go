@@ -9,3 +9,37 @@ const (
 	SeverityNormal   Severity = "normal"
 	SeverityLow      Severity = "low"
 )
+
+// Rank orders severities from low (1) to critical (4). Unknown values rank 0.
+func (s Severity) Rank() int {
+	switch s {
+	case SeverityLow:
+		return 1
+	case SeverityNormal:
+		return 2
+	case SeverityHigh:
+		return 3
+	case SeverityCritical:
+		return 4
+	default:
+		return 0
+	}
+}
+
+// Valid reports whether s is one of the known severity levels.
+func (s Severity) Valid() bool {
+	return s.Rank() > 0
+}
+
+// Compare returns -1, 0, or 1 when s is lower than, equal to, or higher than other.
+func (s Severity) Compare(other Severity) int {
+	a, b := s.Rank(), other.Rank()
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,75 @@
+package plugin
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const embedMethod = "dory.embed"
+
+// EmbeddingProvider returns the single enabled plugin with the embeddings capability.
+func EmbeddingProvider(plugins []PluginInfo) (PluginInfo, error) {
+	var providers []PluginInfo
+	for _, p := range plugins {
+		if p.Enabled && p.Capabilities.Embeddings {
+			providers = append(providers, p)
+		}
+	}
+
+	switch len(providers) {
+	case 0:
+		return PluginInfo{}, fmt.Errorf("no enabled plugin provides embeddings (set capabilities.embeddings: true in its manifest)")
+	case 1:
+		return providers[0], nil
+	}
+	names := make([]string, 0, len(providers))
+	for _, p := range providers {
+		names = append(names, p.Name)
+	}
+	return PluginInfo{}, fmt.Errorf("embeddings are provided by multiple enabled plugins: %s", strings.Join(names, ", "))
+}
+
+// Embed asks a plugin to embed texts. Plugins must return
+// {"vectors": [[...], ...]} with one vector per text, all of equal length.
+func Embed(info PluginInfo, texts []string, timeout time.Duration) ([][]float64, error) {
+	result, stderr, _, err := Invoke(info, embedMethod, map[string]interface{}{
+		"api_version": APIVersionV1,
+		"texts":       texts,
+	}, timeout)
+	if err != nil {
+		if stderr != "" {
+			return nil, fmt.Errorf("embedding via plugin %q failed: %w (%s)", info.Name, err, stderr)
+		}
+		return nil, fmt.Errorf("embedding via plugin %q failed: %w", info.Name, err)
+	}
+
+	raw, ok := result["vectors"].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("plugin %q returned invalid embedding response: missing array field \"vectors\"", info.Name)
+	}
+	if len(raw) != len(texts) {
+		return nil, fmt.Errorf("plugin %q returned %d vectors for %d texts", info.Name, len(raw), len(texts))
+	}
+
+	vectors := make([][]float64, 0, len(raw))
+	for i, item := range raw {
+		values, ok := item.([]interface{})
+		if !ok || len(values) == 0 {
+			return nil, fmt.Errorf("plugin %q returned an invalid vector at index %d", info.Name, i)
+		}
+		vector := make([]float64, 0, len(values))
+		for _, value := range values {
+			f, ok := value.(float64)
+			if !ok {
+				return nil, fmt.Errorf("plugin %q returned a non-numeric vector value at index %d", info.Name, i)
+			}
+			vector = append(vector, f)
+		}
+		if len(vectors) > 0 && len(vector) != len(vectors[0]) {
+			return nil, fmt.Errorf("plugin %q returned vectors of different lengths", info.Name)
+		}
+		vectors = append(vectors, vector)
+	}
+	return vectors, nil
+}
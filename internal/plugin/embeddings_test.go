@@ -0,0 +1,60 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEmbedFixture(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell fixture is POSIX-only")
+	}
+
+	fixtureAbs, err := filepath.Abs(filepath.Join("testdata", "fixture-plugin.sh"))
+	if err != nil {
+		t.Fatalf("abs fixture path: %v", err)
+	}
+	if err := os.Chmod(fixtureAbs, 0755); err != nil {
+		t.Fatalf("chmod fixture: %v", err)
+	}
+	info := PluginInfo{
+		Name:         "fixture",
+		APIVersion:   APIVersionV1,
+		Command:      []string{"./fixture-plugin.sh"},
+		Dir:          filepath.Dir(fixtureAbs),
+		Enabled:      true,
+		Capabilities: Capabilities{Embeddings: true},
+	}
+
+	vectors, err := Embed(info, []string{"first", "second"}, 2*time.Second)
+	if err != nil {
+		t.Fatalf("embed: %v", err)
+	}
+	if len(vectors) != 2 || len(vectors[0]) != 3 || vectors[1][1] != 1 {
+		t.Fatalf("unexpected vectors: %v", vectors)
+	}
+
+	if _, err := Embed(info, []string{"only one"}, 2*time.Second); err == nil || !strings.Contains(err.Error(), "2 vectors for 1 texts") {
+		t.Fatalf("expected count mismatch error, got %v", err)
+	}
+}
+
+func TestEmbeddingProviderSelection(t *testing.T) {
+	disabled := PluginInfo{Name: "off", Capabilities: Capabilities{Embeddings: true}}
+	if _, err := EmbeddingProvider([]PluginInfo{disabled}); err == nil {
+		t.Fatal("expected error when no enabled provider exists")
+	}
+
+	a := PluginInfo{Name: "a", Enabled: true, Capabilities: Capabilities{Embeddings: true}}
+	b := PluginInfo{Name: "b", Enabled: true, Capabilities: Capabilities{Embeddings: true}}
+	if provider, err := EmbeddingProvider([]PluginInfo{disabled, a}); err != nil || provider.Name != "a" {
+		t.Fatalf("expected provider a, got %v (%v)", provider.Name, err)
+	}
+	if _, err := EmbeddingProvider([]PluginInfo{a, b}); err == nil {
+		t.Fatal("expected error for multiple providers")
+	}
+}
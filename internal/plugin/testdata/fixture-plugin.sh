@@ -12,6 +12,9 @@ case "$line" in
   *'"method":"dory.command.run"'*)
     printf '%s\n' '{"id":"req-1","result":{"output":"fixture command output\n","message":"fixture done"}}'
     ;;
+  *'"method":"dory.embed"'*)
+    printf '%s\n' '{"id":"req-1","result":{"vectors":[[1,0,0.5],[0,1,0.5]]}}'
+    ;;
   *)
     printf '%s\n' '{"id":"req-1","error":{"code":404,"message":"unknown method"}}'
     ;;
@@ -15,6 +15,8 @@ type Capabilities struct {
 	Commands []string `yaml:"commands,omitempty" json:"commands,omitempty"`
 	Hooks    []string `yaml:"hooks,omitempty" json:"hooks,omitempty"`
 	Types    []string `yaml:"types,omitempty" json:"types,omitempty"`
+	// Embeddings marks a plugin that turns text into vectors via dory.embed.
+	Embeddings bool `yaml:"embeddings,omitempty" json:"embeddings,omitempty"`
 	// Store remains parsed so we can explicitly reject it at validation time.
 	Store bool `yaml:"store,omitempty" json:"store,omitempty"`
 }
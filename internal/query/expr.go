@@ -0,0 +1,214 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sibellavia/dory/internal/models"
+)
+
+// StatusActive is the status of every live item. Items have no lifecycle
+// yet, so status:active matches everything and other statuses match nothing.
+const StatusActive = "active"
+
+// Fields lists the field names an expression may filter on.
+var Fields = []string{"id", "type", "tag", "topic", "domain", "severity", "created", "ref", "text", "status", "pinned"}
+
+// Item is the view of a knowledge item that expressions are evaluated against.
+type Item struct {
+	ID       string
+	Type     string
+	Topic    string
+	Domain   string
+	Severity models.Severity
+	Oneliner string
+	Created  time.Time
+	Refs     []string
+	Pinned   bool
+}
+
+// Expr is a compiled filter expression.
+type Expr interface {
+	Match(item Item) bool
+}
+
+type operator string
+
+const (
+	opContains operator = ":"
+	opEQ       operator = "="
+	opNE       operator = "!="
+	opGT       operator = ">"
+	opGTE      operator = ">="
+	opLT       operator = "<"
+	opLTE      operator = "<="
+)
+
+func (op operator) ordered() bool {
+	switch op {
+	case opGT, opGTE, opLT, opLTE:
+		return true
+	}
+	return false
+}
+
+type andExpr struct{ left, right Expr }
+
+func (e andExpr) Match(item Item) bool { return e.left.Match(item) && e.right.Match(item) }
+
+type orExpr struct{ left, right Expr }
+
+func (e orExpr) Match(item Item) bool { return e.left.Match(item) || e.right.Match(item) }
+
+type notExpr struct{ inner Expr }
+
+func (e notExpr) Match(item Item) bool { return !e.inner.Match(item) }
+
+// comparison tests one field against a value.
+type comparison struct {
+	field string
+	op    operator
+	value string
+
+	severity models.Severity
+	date     string
+	instant  time.Time
+	exact    bool // no trailing * wildcard
+}
+
+func newComparison(field string, op operator, value string) (Expr, error) {
+	c := comparison{field: field, op: op, value: strings.ToLower(value)}
+	switch field {
+	case "severity":
+		c.severity = models.Severity(c.value)
+		if !c.severity.Valid() {
+			return nil, fmt.Errorf("invalid severity %q (expected: critical, high, normal, low)", value)
+		}
+	case "created":
+		if t, err := time.Parse("2006-01-02", value); err == nil {
+			c.date = t.Format("2006-01-02")
+		} else if t, err := time.Parse(time.RFC3339, value); err == nil {
+			c.instant = t
+		} else {
+			return nil, fmt.Errorf("invalid date %q (expected YYYY-MM-DD or RFC3339)", value)
+		}
+	case "pinned":
+		if op != opContains && op != opEQ && op != opNE {
+			return nil, fmt.Errorf("field %s does not support %s", field, op)
+		}
+		pinned, err := strconv.ParseBool(c.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pinned value %q (expected true or false)", value)
+		}
+		c.value = strconv.FormatBool(pinned)
+	case "id", "type", "tag", "topic", "domain", "ref", "text", "status":
+		if op.ordered() {
+			return nil, fmt.Errorf("field %s does not support %s", field, op)
+		}
+	default:
+		return nil, fmt.Errorf("unknown field %q (expected one of: %s)", field, strings.Join(Fields, ", "))
+	}
+	return c, nil
+}
+
+// Equals returns an expression matching items whose field equals value,
+// case-insensitively and without wildcards. Use it to filter on a value taken
+// from data (a tag name, say) rather than formatting it into query source.
+func Equals(field, value string) (Expr, error) {
+	expr, err := newComparison(field, opEQ, value)
+	if err != nil {
+		return nil, err
+	}
+	c, ok := expr.(comparison)
+	if !ok {
+		return expr, nil
+	}
+	c.exact = true
+	return c, nil
+}
+
+func (c comparison) Match(item Item) bool {
+	switch c.field {
+	case "severity":
+		if item.Severity == "" {
+			return false
+		}
+		return c.compare(item.Severity.Compare(c.severity))
+	case "created":
+		if c.instant.IsZero() {
+			return c.compare(strings.Compare(item.Created.Format("2006-01-02"), c.date))
+		}
+		return c.compare(item.Created.Compare(c.instant))
+	case "id":
+		return c.equals(item.ID)
+	case "type":
+		return c.equals(item.Type)
+	case "topic":
+		return c.equals(item.Topic)
+	case "domain":
+		return c.equals(item.Domain)
+	case "status":
+		return c.equals(StatusActive)
+	case "pinned":
+		return c.equals(strconv.FormatBool(item.Pinned))
+	case "tag":
+		if c.op == opNE {
+			return !c.matchValue(item.Topic) && !c.matchValue(item.Domain)
+		}
+		return c.matchValue(item.Topic) || c.matchValue(item.Domain)
+	case "ref":
+		for _, ref := range item.Refs {
+			if c.matchValue(ref) {
+				return c.op != opNE
+			}
+		}
+		return c.op == opNE
+	case "text":
+		text := strings.ToLower(item.Oneliner)
+		switch c.op {
+		case opEQ:
+			return text == c.value
+		case opNE:
+			return text != c.value
+		default:
+			return strings.Contains(text, c.value)
+		}
+	}
+	return false
+}
+
+// compare applies an ordered operator to the result of a three-way comparison.
+func (c comparison) compare(cmp int) bool {
+	switch c.op {
+	case opGT:
+		return cmp > 0
+	case opGTE:
+		return cmp >= 0
+	case opLT:
+		return cmp < 0
+	case opLTE:
+		return cmp <= 0
+	case opNE:
+		return cmp != 0
+	default:
+		return cmp == 0
+	}
+}
+
+func (c comparison) equals(actual string) bool {
+	if c.op == opNE {
+		return !c.matchValue(actual)
+	}
+	return c.matchValue(actual)
+}
+
+// matchValue compares case-insensitively; a trailing * matches a prefix.
+func (c comparison) matchValue(actual string) bool {
+	actual = strings.ToLower(actual)
+	if prefix, ok := strings.CutSuffix(c.value, "*"); ok && !c.exact {
+		return strings.HasPrefix(actual, prefix)
+	}
+	return actual == c.value
+}
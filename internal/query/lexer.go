@@ -0,0 +1,74 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokLParen
+	tokRParen
+	tokAnd
+	tokOr
+	tokNot
+	tokTerm
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// lex splits an expression into parentheses, keywords, and terms.
+// A term runs until whitespace or a parenthesis; double quotes may appear
+// inside a term to protect spaces (text:"connection pool").
+func lex(input string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(input) {
+		c := input[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{kind: tokLParen, text: "(", pos: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokRParen, text: ")", pos: i})
+			i++
+		default:
+			start := i
+			for i < len(input) {
+				c := input[i]
+				if c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '(' || c == ')' {
+					break
+				}
+				if c == '"' {
+					end := strings.IndexByte(input[i+1:], '"')
+					if end < 0 {
+						return nil, fmt.Errorf("unterminated quote at position %d", i+1)
+					}
+					i += end + 2
+					continue
+				}
+				i++
+			}
+			text := input[start:i]
+			kind := tokTerm
+			switch strings.ToUpper(text) {
+			case "AND":
+				kind = tokAnd
+			case "OR":
+				kind = tokOr
+			case "NOT":
+				kind = tokNot
+			}
+			tokens = append(tokens, token{kind: kind, text: text, pos: start + 1})
+		}
+	}
+	return append(tokens, token{kind: tokEOF, pos: len(input) + 1}), nil
+}
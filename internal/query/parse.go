@@ -0,0 +1,152 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Parse compiles a filter expression such as
+//
+//	type:lesson AND (tag:auth OR tag:api) AND severity>=high AND NOT status:superseded
+//
+// Terms are combined with AND, OR, NOT, and parentheses; adjacent terms
+// without an operator are joined with AND. A bare word matches the oneliner.
+func Parse(input string) (Expr, error) {
+	if strings.TrimSpace(input) == "" {
+		return nil, fmt.Errorf("query is empty")
+	}
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos)
+	}
+	return expr, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orExpr{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch p.peek().kind {
+		case tokAnd:
+			p.next()
+		case tokNot, tokLParen, tokTerm:
+			// Implicit AND between adjacent terms.
+		default:
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andExpr{left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokNot:
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{inner: inner}, nil
+	case tokLParen:
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokRParen {
+			return nil, fmt.Errorf("expected ) at position %d", closing.pos)
+		}
+		return inner, nil
+	case tokTerm:
+		return parseTerm(tok)
+	case tokEOF:
+		return nil, fmt.Errorf("unexpected end of query")
+	default:
+		return nil, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos)
+	}
+}
+
+// parseTerm splits a term into field, operator, and value.
+func parseTerm(tok token) (Expr, error) {
+	text := tok.text
+	i := strings.IndexAny(text, ":=!<>")
+	if i < 0 || strings.HasPrefix(text, `"`) {
+		return newComparison("text", opContains, unquote(text))
+	}
+	if i == 0 {
+		return nil, fmt.Errorf("missing field name in %q at position %d", text, tok.pos)
+	}
+
+	field := strings.ToLower(text[:i])
+	rest := text[i:]
+	var op operator
+	for _, candidate := range []operator{opGTE, opLTE, opNE, opContains, opEQ, opGT, opLT} {
+		if strings.HasPrefix(rest, string(candidate)) {
+			op = candidate
+			break
+		}
+	}
+	if op == "" {
+		return nil, fmt.Errorf("invalid operator in %q at position %d", text, tok.pos)
+	}
+
+	value := unquote(rest[len(op):])
+	if value == "" {
+		return nil, fmt.Errorf("missing value in %q at position %d", text, tok.pos)
+	}
+	expr, err := newComparison(field, op, value)
+	if err != nil {
+		return nil, fmt.Errorf("%s (at position %d)", err, tok.pos)
+	}
+	return expr, nil
+}
+
+func unquote(value string) string {
+	return strings.ReplaceAll(value, `"`, "")
+}
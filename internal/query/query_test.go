@@ -0,0 +1,96 @@
+package query
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sibellavia/dory/internal/models"
+)
+
+func sampleItems() []Item {
+	day := func(s string) time.Time {
+		t, _ := time.Parse("2006-01-02", s)
+		return t
+	}
+	return []Item{
+		{ID: "L-1", Type: "lesson", Topic: "auth", Severity: models.SeverityCritical, Oneliner: "Tokens expire silently", Created: day("2026-02-01")},
+		{ID: "L-2", Type: "lesson", Topic: "api", Severity: models.SeverityNormal, Oneliner: "Paginate list endpoints", Created: day("2026-03-01")},
+		{ID: "L-3", Type: "lesson", Topic: "api", Severity: models.SeverityHigh, Oneliner: "Rate limit per key", Created: day("2025-12-01")},
+		{ID: "D-1", Type: "decision", Topic: "auth", Oneliner: "Use OIDC", Created: day("2026-02-10"), Refs: []string{"L-1"}, Pinned: true},
+	}
+}
+
+func matchIDs(t *testing.T, input string) string {
+	t.Helper()
+	expr, err := Parse(input)
+	if err != nil {
+		t.Fatalf("parse %q: %v", input, err)
+	}
+	var ids []string
+	for _, item := range sampleItems() {
+		if expr.Match(item) {
+			ids = append(ids, item.ID)
+		}
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestParseAndMatch(t *testing.T) {
+	cases := map[string]string{
+		`type:lesson AND (tag:auth OR tag:api) AND severity>=high AND created>2026-01-01 AND NOT status:superseded`: "L-1",
+		`type:lesson severity>=high`:         "L-1,L-3",
+		`severity<normal OR severity=normal`: "L-2",
+		`NOT type:lesson`:                    "D-1",
+		`tag:au*`:                            "L-1,D-1",
+		`ref:L-1`:                            "D-1",
+		`created<=2026-02-01`:                "L-1,L-3",
+		`created:2026-03-01`:                 "L-2",
+		`text:"list endpoints"`:              "L-2",
+		`paginate`:                           "L-2",
+		`pinned:true`:                        "D-1",
+		`pinned=false type:lesson`:           "L-1,L-2,L-3",
+	}
+	for input, want := range cases {
+		if got := matchIDs(t, input); got != want {
+			t.Errorf("%s: got %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"severity>=urgent",
+		"color:red",
+		"tag>api",
+		"(type:lesson",
+		"type:lesson AND",
+		`text:"open`,
+		":lesson",
+		"pinned:maybe",
+		"pinned>true",
+	} {
+		if _, err := Parse(input); err == nil {
+			t.Errorf("expected error for %q", input)
+		}
+	}
+}
+
+func TestEquals(t *testing.T) {
+	item := Item{ID: "L-9", Type: "lesson", Topic: `say "hi"\*`}
+	expr, err := Equals("tag", `Say "hi"\*`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !expr.Match(item) {
+		t.Fatal("expected quotes, backslashes, and * to match literally")
+	}
+	expr, err = Equals("tag", `say`+"*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expr.Match(item) {
+		t.Fatal("expected a trailing * not to act as a wildcard")
+	}
+}
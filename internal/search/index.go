@@ -0,0 +1,202 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// Index is an inverted index with term positions, ranked with BM25.
+type Index struct {
+	Docs        map[string]*DocInfo         `json:"docs"`
+	Postings    map[string]map[string][]int `json:"postings"`
+	TotalLength int                         `json:"total_length"`
+}
+
+// DocInfo tracks an indexed document version and its distinct terms.
+type DocInfo struct {
+	Version int64    `json:"version"`
+	Length  int      `json:"length"`
+	Terms   []string `json:"terms"`
+}
+
+// Result is a ranked search match.
+type Result struct {
+	ID    string
+	Score float64
+}
+
+// NewIndex returns an empty index.
+func NewIndex() *Index {
+	return &Index{
+		Docs:     make(map[string]*DocInfo),
+		Postings: make(map[string]map[string][]int),
+	}
+}
+
+// Version returns the indexed version of a document.
+func (ix *Index) Version(id string) (int64, bool) {
+	doc, ok := ix.Docs[id]
+	if !ok {
+		return 0, false
+	}
+	return doc.Version, true
+}
+
+// IDs returns all indexed document IDs.
+func (ix *Index) IDs() []string {
+	ids := make([]string, 0, len(ix.Docs))
+	for id := range ix.Docs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Add indexes text for a document, replacing any earlier version.
+func (ix *Index) Add(id string, version int64, text string) {
+	ix.Remove(id)
+
+	terms := Terms(text)
+	distinct := make([]string, 0)
+	for pos, term := range terms {
+		docs, ok := ix.Postings[term]
+		if !ok {
+			docs = make(map[string][]int)
+			ix.Postings[term] = docs
+		}
+		if _, seen := docs[id]; !seen {
+			distinct = append(distinct, term)
+		}
+		docs[id] = append(docs[id], pos)
+	}
+
+	ix.Docs[id] = &DocInfo{Version: version, Length: len(terms), Terms: distinct}
+	ix.TotalLength += len(terms)
+}
+
+// Remove drops a document from the index.
+func (ix *Index) Remove(id string) {
+	doc, ok := ix.Docs[id]
+	if !ok {
+		return
+	}
+	for _, term := range doc.Terms {
+		docs := ix.Postings[term]
+		delete(docs, id)
+		if len(docs) == 0 {
+			delete(ix.Postings, term)
+		}
+	}
+	ix.TotalLength -= doc.Length
+	delete(ix.Docs, id)
+}
+
+// Search returns documents matching every clause, best first.
+// allow, when non-nil, restricts the candidate documents.
+func (ix *Index) Search(q *Query, allow func(id string) bool) []Result {
+	if q == nil || len(q.Clauses) == 0 || len(ix.Docs) == 0 {
+		return nil
+	}
+
+	n := float64(len(ix.Docs))
+	avgLen := float64(ix.TotalLength) / n
+	if avgLen == 0 {
+		avgLen = 1
+	}
+
+	scores := make(map[string]float64)
+	for i, clause := range q.Clauses {
+		freqs := ix.clauseFrequencies(clause)
+		idf := math.Log(1 + (n-float64(len(freqs))+0.5)/(float64(len(freqs))+0.5))
+
+		next := make(map[string]float64)
+		for id, tf := range freqs {
+			if i > 0 {
+				if _, ok := scores[id]; !ok {
+					continue
+				}
+			}
+			if allow != nil && !allow(id) {
+				continue
+			}
+			docLen := float64(ix.Docs[id].Length)
+			weight := float64(tf) * (bm25K1 + 1) / (float64(tf) + bm25K1*(1-bm25B+bm25B*docLen/avgLen))
+			next[id] = scores[id] + idf*weight
+		}
+		scores = next
+		if len(scores) == 0 {
+			return nil
+		}
+	}
+
+	results := make([]Result, 0, len(scores))
+	for id, score := range scores {
+		results = append(results, Result{ID: id, Score: score})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].ID < results[j].ID
+	})
+	return results
+}
+
+// clauseFrequencies returns per-document match counts for a clause.
+func (ix *Index) clauseFrequencies(clause Clause) map[string]int {
+	freqs := make(map[string]int)
+
+	if clause.Phrase() {
+		first := ix.Postings[clause.Terms[0]]
+		for id, positions := range first {
+			count := 0
+			for _, pos := range positions {
+				if ix.phraseAt(id, clause.Terms, pos) {
+					count++
+				}
+			}
+			if count > 0 {
+				freqs[id] = count
+			}
+		}
+		return freqs
+	}
+
+	term := clause.Terms[0]
+	if !clause.Prefix {
+		for id, positions := range ix.Postings[term] {
+			freqs[id] = len(positions)
+		}
+		return freqs
+	}
+
+	for indexed, docs := range ix.Postings {
+		if !strings.HasPrefix(indexed, term) {
+			continue
+		}
+		for id, positions := range docs {
+			freqs[id] += len(positions)
+		}
+	}
+	return freqs
+}
+
+func (ix *Index) phraseAt(id string, terms []string, start int) bool {
+	for offset, term := range terms[1:] {
+		if !containsInt(ix.Postings[term][id], start+offset+1) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsInt(sorted []int, target int) bool {
+	i := sort.SearchInts(sorted, target)
+	return i < len(sorted) && sorted[i] == target
+}
@@ -0,0 +1,69 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Clause is one required part of a query: a word, a prefix, or a phrase.
+type Clause struct {
+	Terms  []string `json:"terms"`
+	Prefix bool     `json:"prefix,omitempty"`
+}
+
+// Phrase reports whether the clause matches a sequence of words.
+func (c Clause) Phrase() bool {
+	return len(c.Terms) > 1
+}
+
+// Query is a parsed search query. Every clause must match.
+type Query struct {
+	Clauses []Clause
+}
+
+// ParseQuery parses words, "quoted phrases", and prefix* words.
+func ParseQuery(input string) (*Query, error) {
+	q := &Query{}
+	rest := strings.TrimSpace(input)
+	for rest != "" {
+		if rest[0] == '"' {
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated phrase in query %q", input)
+			}
+			terms := Terms(rest[1 : end+1])
+			if len(terms) > 0 {
+				q.Clauses = append(q.Clauses, Clause{Terms: terms})
+			}
+			rest = strings.TrimSpace(rest[end+2:])
+			continue
+		}
+
+		word := rest
+		if i := strings.IndexAny(rest, " \t\""); i >= 0 {
+			word = rest[:i]
+		}
+		rest = strings.TrimSpace(rest[len(word):])
+
+		// Punctuated words ("e-mail") split into several required terms;
+		// only the last keeps the prefix marker.
+		prefix := strings.HasSuffix(word, "*")
+		terms := Terms(strings.TrimSuffix(word, "*"))
+		for i, term := range terms {
+			q.Clauses = append(q.Clauses, Clause{Terms: []string{term}, Prefix: prefix && i == len(terms)-1})
+		}
+	}
+
+	if len(q.Clauses) == 0 {
+		return nil, fmt.Errorf("search query is empty")
+	}
+	return q, nil
+}
+
+// matchesTerm reports whether an indexed term satisfies a query term.
+func matchesTerm(indexed, queried string, prefix bool) bool {
+	if prefix {
+		return strings.HasPrefix(indexed, queried)
+	}
+	return indexed == queried
+}
@@ -0,0 +1,134 @@
+package search
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	q, err := ParseQuery(`sendgrid "domain verification" verif*`)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(q.Clauses) != 3 {
+		t.Fatalf("expected 3 clauses, got %+v", q.Clauses)
+	}
+	if q.Clauses[0].Terms[0] != "sendgrid" || q.Clauses[0].Prefix {
+		t.Fatalf("unexpected word clause: %+v", q.Clauses[0])
+	}
+	if !q.Clauses[1].Phrase() || q.Clauses[1].Terms[1] != "verification" {
+		t.Fatalf("unexpected phrase clause: %+v", q.Clauses[1])
+	}
+	if !q.Clauses[2].Prefix || q.Clauses[2].Terms[0] != "verif" {
+		t.Fatalf("unexpected prefix clause: %+v", q.Clauses[2])
+	}
+
+	if _, err := ParseQuery(`"unterminated`); err == nil {
+		t.Fatal("expected unterminated phrase to fail")
+	}
+	if _, err := ParseQuery("  "); err == nil {
+		t.Fatal("expected empty query to fail")
+	}
+}
+
+func TestIndexSearchRanksAndMatchesPhrases(t *testing.T) {
+	ix := NewIndex()
+	ix.Add("L-1", 1, "SendGrid requires domain verification before sending mail")
+	ix.Add("L-2", 1, "Domain names must be lowercase. Verification of email happens later")
+	ix.Add("L-3", 1, "Mail mail mail: the mailer retries mail three times")
+
+	results := ix.Search(mustParse(t, `"domain verification"`), nil)
+	if len(results) != 1 || results[0].ID != "L-1" {
+		t.Fatalf("expected phrase to match only L-1, got %+v", results)
+	}
+
+	results = ix.Search(mustParse(t, "domain verification"), nil)
+	if len(results) != 2 {
+		t.Fatalf("expected both domain docs, got %+v", results)
+	}
+
+	results = ix.Search(mustParse(t, "mail"), nil)
+	if len(results) != 2 || results[0].ID != "L-3" {
+		t.Fatalf("expected L-3 to rank first for repeated term, got %+v", results)
+	}
+
+	results = ix.Search(mustParse(t, "mail*"), nil)
+	if len(results) != 2 {
+		t.Fatalf("expected prefix to match mail and mailer docs, got %+v", results)
+	}
+
+	results = ix.Search(mustParse(t, "mail"), func(id string) bool { return id != "L-3" })
+	if len(results) != 1 || results[0].ID != "L-1" {
+		t.Fatalf("expected filter to exclude L-3, got %+v", results)
+	}
+}
+
+func TestIndexReplaceAndRemove(t *testing.T) {
+	ix := NewIndex()
+	ix.Add("L-1", 1, "old words here")
+	ix.Add("L-1", 2, "new words")
+
+	if v, ok := ix.Version("L-1"); !ok || v != 2 {
+		t.Fatalf("expected version 2, got %d %v", v, ok)
+	}
+	if results := ix.Search(mustParse(t, "old"), nil); len(results) != 0 {
+		t.Fatalf("expected replaced text to be gone, got %+v", results)
+	}
+
+	ix.Remove("L-1")
+	if len(ix.Docs) != 0 || len(ix.Postings) != 0 || ix.TotalLength != 0 {
+		t.Fatalf("expected empty index after remove, got %+v", ix)
+	}
+}
+
+func TestSnippetHighlightsMatches(t *testing.T) {
+	text := strings.Repeat("filler ", 40) + "SendGrid requires domain verification first. " + strings.Repeat("tail ", 40)
+	snippet, spans := Snippet(text, mustParse(t, `sendgrid "domain verification"`), 80)
+
+	if len(spans) != 2 || snippet[spans[0].Start:spans[0].End] != "SendGrid" || snippet[spans[1].Start:spans[1].End] != "domain verification" {
+		t.Fatalf("expected highlighted matches, got %q %v", snippet, spans)
+	}
+	if !strings.HasPrefix(snippet, "…") || !strings.HasSuffix(snippet, "…") {
+		t.Fatalf("expected truncated snippet markers, got %q", snippet)
+	}
+}
+
+func TestSnippetIgnoresMarkdownBold(t *testing.T) {
+	snippet, spans := Snippet("Use **bold** for \x01emphasis\x02.", mustParse(t, "pool"), 80)
+	if len(spans) != 0 || snippet != "Use **bold** for emphasis." {
+		t.Fatalf("expected no match and the text unchanged, got %q %v", snippet, spans)
+	}
+}
+
+func mustParse(t *testing.T, input string) *Query {
+	t.Helper()
+	q, err := ParseQuery(input)
+	if err != nil {
+		t.Fatalf("parse %q: %v", input, err)
+	}
+	return q
+}
+
+func TestCorpusRanksRephrasingsFirst(t *testing.T) {
+	c := NewCorpus()
+	c.Add("L-1", "Connection pool exhausts under load")
+	c.Add("L-2", "Tokens expire silently after an hour")
+	c.Add("D-1", "Use Redis for session storage")
+
+	results := c.Similar("DB connection pool gets exhausted under heavy load", nil)
+	if len(results) == 0 || results[0].ID != "L-1" || results[0].Score < 0.5 {
+		t.Fatalf("expected L-1 to rank first above 0.5, got %+v", results)
+	}
+
+	only := NewCorpus()
+	only.Add("L-1", "Paginate list endpoints")
+	results = only.Similar("Paginate list endpoints", nil)
+	if len(results) != 1 || results[0].Score < 0.999 {
+		t.Fatalf("expected an exact copy to score 1, got %+v", results)
+	}
+
+	results = c.Similar("Connection pool", func(id string) bool { return id != "L-1" })
+	if len(results) != 0 {
+		t.Fatalf("expected filtered corpus to have no matches, got %+v", results)
+	}
+}
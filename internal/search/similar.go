@@ -0,0 +1,142 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// stopwords are dropped before similarity scoring so that phrasing
+// ("the", "is", "when") does not make unrelated items look alike.
+var stopwords = map[string]bool{
+	"a": true, "about": true, "after": true, "all": true, "an": true, "and": true,
+	"are": true, "as": true, "at": true, "be": true, "before": true, "but": true,
+	"by": true, "can": true, "do": true, "does": true, "for": true, "from": true,
+	"has": true, "have": true, "how": true, "if": true, "in": true, "into": true,
+	"is": true, "it": true, "its": true, "may": true, "must": true, "no": true,
+	"not": true, "of": true, "on": true, "or": true, "our": true, "should": true,
+	"so": true, "than": true, "that": true, "the": true, "their": true, "then": true,
+	"there": true, "these": true, "this": true, "to": true, "under": true,
+	"use": true, "was": true, "we": true, "were": true, "what": true, "when": true,
+	"where": true, "which": true, "while": true, "who": true, "why": true,
+	"will": true, "with": true, "you": true,
+}
+
+// Corpus scores documents against each other with TF-IDF cosine similarity.
+type Corpus struct {
+	docs map[string]map[string]int
+	df   map[string]int
+}
+
+// NewCorpus returns an empty corpus.
+func NewCorpus() *Corpus {
+	return &Corpus{
+		docs: make(map[string]map[string]int),
+		df:   make(map[string]int),
+	}
+}
+
+// Add adds or replaces a document.
+func (c *Corpus) Add(id, text string) {
+	if old, ok := c.docs[id]; ok {
+		for term := range old {
+			c.df[term]--
+		}
+	}
+	counts := termCounts(text)
+	c.docs[id] = counts
+	for term := range counts {
+		c.df[term]++
+	}
+}
+
+// Len returns the number of documents in the corpus.
+func (c *Corpus) Len() int {
+	return len(c.docs)
+}
+
+// Similar ranks documents by cosine similarity to text, best first.
+// The text is weighted as if it were part of the corpus, so an exact copy
+// of a single existing document still scores 1. Documents rejected by
+// allow (when non-nil) and documents with no shared terms are skipped.
+func (c *Corpus) Similar(text string, allow func(id string) bool) []Result {
+	query := termCounts(text)
+	if len(query) == 0 {
+		return nil
+	}
+
+	total := float64(len(c.docs) + 1)
+	idf := func(term string) float64 {
+		df := c.df[term]
+		if _, ok := query[term]; ok {
+			df++
+		}
+		return math.Log(1 + total/float64(df))
+	}
+
+	queryVec, queryNorm := weigh(query, idf)
+	var results []Result
+	for id, doc := range c.docs {
+		if allow != nil && !allow(id) {
+			continue
+		}
+		docVec, docNorm := weigh(doc, idf)
+		dot := 0.0
+		for term, w := range queryVec {
+			dot += w * docVec[term]
+		}
+		if dot == 0 {
+			continue
+		}
+		results = append(results, Result{ID: id, Score: dot / (queryNorm * docNorm)})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].ID < results[j].ID
+	})
+	return results
+}
+
+// weigh turns term counts into TF-IDF weights using log-scaled term frequency.
+func weigh(counts map[string]int, idf func(string) float64) (map[string]float64, float64) {
+	vec := make(map[string]float64, len(counts))
+	norm := 0.0
+	for term, n := range counts {
+		w := (1 + math.Log(float64(n))) * idf(term)
+		vec[term] = w
+		norm += w * w
+	}
+	return vec, math.Sqrt(norm)
+}
+
+func termCounts(text string) map[string]int {
+	counts := make(map[string]int)
+	for _, term := range Terms(text) {
+		if len(term) < 2 || stopwords[term] {
+			continue
+		}
+		counts[stem(term)]++
+	}
+	return counts
+}
+
+// stem strips common English inflections so that "exhausted", "exhausts",
+// and "exhaust" count as one term. It is deliberately crude.
+func stem(term string) string {
+	switch {
+	case len(term) > 4 && strings.HasSuffix(term, "ies"):
+		return term[:len(term)-3] + "y"
+	case len(term) > 5 && strings.HasSuffix(term, "ing"):
+		return term[:len(term)-3]
+	case len(term) > 4 && strings.HasSuffix(term, "ed"):
+		return term[:len(term)-2]
+	case len(term) > 5 && strings.HasSuffix(term, "ly"):
+		return term[:len(term)-2]
+	case len(term) > 3 && strings.HasSuffix(term, "s") && !strings.HasSuffix(term, "ss"):
+		return term[:len(term)-1]
+	}
+	return term
+}
@@ -0,0 +1,143 @@
+package search
+
+import "strings"
+
+// Span is a highlighted range of a snippet, as byte offsets.
+type Span struct {
+	Start int `json:"start" yaml:"start"`
+	End   int `json:"end" yaml:"end"`
+}
+
+// Markers bracket matches while a snippet is built. They are control
+// characters, stripped from the text first, so they cannot collide with
+// anything the text contains (such as markdown bold).
+const (
+	openMark  = '\x01'
+	closeMark = '\x02'
+)
+
+// Snippet returns an excerpt of text around the first match, with
+// whitespace collapsed, and the spans of the matched words in it. No spans
+// means nothing in text matched.
+func Snippet(text string, q *Query, width int) (string, []Span) {
+	text = strings.Map(func(r rune) rune {
+		if r == openMark || r == closeMark {
+			return -1
+		}
+		return r
+	}, text)
+	tokens := Tokenize(text)
+	matched := matchedTokens(tokens, q)
+
+	first := -1
+	for i := range tokens {
+		if matched[i] {
+			first = i
+			break
+		}
+	}
+
+	start, end := 0, len(text)
+	if first >= 0 {
+		start = tokens[first].Start - width/3
+		if start < 0 {
+			start = 0
+		}
+	}
+	if end-start > width {
+		end = start + width
+	}
+	// Keep whole words at the window edges.
+	if start > 0 {
+		if i := strings.IndexAny(text[start:], " \t\n"); i >= 0 && start+i < tokens[first].Start {
+			start += i + 1
+		}
+	}
+	if end < len(text) {
+		if i := strings.LastIndexAny(text[start:end], " \t\n"); i > 0 {
+			end = start + i
+		}
+	}
+
+	var sb strings.Builder
+	if start > 0 {
+		sb.WriteString("… ")
+	}
+	pos := start
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		if token.Start < start || token.End > end || !matched[i] {
+			continue
+		}
+		// Adjacent matches separated only by spaces share one highlight,
+		// so phrases read as a single marked span.
+		last := i
+		for last+1 < len(tokens) && matched[last+1] && tokens[last+1].End <= end &&
+			strings.TrimSpace(text[tokens[last].End:tokens[last+1].Start]) == "" {
+			last++
+		}
+		sb.WriteString(text[pos:token.Start])
+		sb.WriteRune(openMark)
+		sb.WriteString(text[token.Start:tokens[last].End])
+		sb.WriteRune(closeMark)
+		pos = tokens[last].End
+		i = last
+	}
+	sb.WriteString(text[pos:end])
+	if end < len(text) {
+		sb.WriteString(" …")
+	}
+	return extractSpans(strings.Join(strings.Fields(sb.String()), " "))
+}
+
+// extractSpans removes the match markers from a snippet and returns where
+// they were.
+func extractSpans(marked string) (string, []Span) {
+	var out strings.Builder
+	var spans []Span
+	start := 0
+	for i := 0; i < len(marked); i++ {
+		switch marked[i] {
+		case openMark:
+			start = out.Len()
+		case closeMark:
+			spans = append(spans, Span{Start: start, End: out.Len()})
+		default:
+			out.WriteByte(marked[i])
+		}
+	}
+	return out.String(), spans
+}
+
+// matchedTokens flags tokens that satisfy a word, prefix, or phrase clause.
+func matchedTokens(tokens []Token, q *Query) []bool {
+	matched := make([]bool, len(tokens))
+	if q == nil {
+		return matched
+	}
+	for _, clause := range q.Clauses {
+		if !clause.Phrase() {
+			for i, token := range tokens {
+				if matchesTerm(token.Term, clause.Terms[0], clause.Prefix) {
+					matched[i] = true
+				}
+			}
+			continue
+		}
+		for i := 0; i+len(clause.Terms) <= len(tokens); i++ {
+			hit := true
+			for j, term := range clause.Terms {
+				if tokens[i+j].Term != term {
+					hit = false
+					break
+				}
+			}
+			if hit {
+				for j := range clause.Terms {
+					matched[i+j] = true
+				}
+			}
+		}
+	}
+	return matched
+}
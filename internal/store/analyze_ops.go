@@ -0,0 +1,390 @@
+package store
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/sibellavia/dory/internal/doryfile"
+)
+
+// GraphAnalysis summarizes the health of the reference graph.
+type GraphAnalysis struct {
+	Items      int         `json:"items" yaml:"items"`
+	Edges      int         `json:"edges" yaml:"edges"`
+	Orphans    []ListItem  `json:"orphans" yaml:"orphans"`
+	BrokenRefs []BrokenRef `json:"broken_refs" yaml:"broken_refs"`
+	Cycles     [][]string  `json:"cycles" yaml:"cycles"`
+	Hubs       []HubItem   `json:"hubs" yaml:"hubs"`
+}
+
+// BrokenRef is a ref to an item that does not exist.
+type BrokenRef struct {
+	From     string `json:"from" yaml:"from"`
+	Oneliner string `json:"oneliner" yaml:"oneliner"`
+	Ref      string `json:"ref" yaml:"ref"`
+	// Deleted is true when the target existed and was removed.
+	Deleted bool `json:"deleted" yaml:"deleted"`
+}
+
+// HubItem is a highly connected item.
+type HubItem struct {
+	ListItem   `yaml:",inline"`
+	InDegree   int     `json:"in_degree" yaml:"in_degree"`
+	OutDegree  int     `json:"out_degree" yaml:"out_degree"`
+	Centrality float64 `json:"centrality" yaml:"centrality"`
+}
+
+// PathStep is one item on a ref path. Via says how the previous step
+// reaches this one: "refs" or "referenced_by". It is empty on the first step.
+type PathStep struct {
+	ListItem `yaml:",inline"`
+	Via      string `json:"via,omitempty" yaml:"via,omitempty"`
+}
+
+// refGraph is the item graph with refs to missing items and to session
+// tasks and questions left out.
+type refGraph struct {
+	entries map[string]*doryfile.MemoryEntry
+	ids     []string
+	out     map[string][]string
+	in      map[string][]string
+}
+
+// AnalyzeGraph reports orphans, broken refs, cycles, and the top hubs.
+// Refs to session tasks and questions (T-1, Q-1) are not broken.
+func (s *Store) AnalyzeGraph(hubs int) (*GraphAnalysis, error) {
+	if err := s.openLatest(); err != nil {
+		return nil, err
+	}
+	g := s.refGraph()
+
+	result := &GraphAnalysis{
+		Items:      len(g.ids),
+		Orphans:    make([]ListItem, 0),
+		BrokenRefs: make([]BrokenRef, 0),
+		Cycles:     make([][]string, 0),
+		Hubs:       make([]HubItem, 0),
+	}
+
+	deleted := make(map[string]bool, len(s.df.Index.Deleted))
+	for _, id := range s.df.Index.Deleted {
+		deleted[id] = true
+	}
+	stateRefs := s.stateRefIDs()
+	for _, id := range g.ids {
+		entry := g.entries[id]
+		result.Edges += len(g.out[id])
+		for _, ref := range entry.Refs {
+			if _, ok := g.entries[ref]; ok || stateRefs[ref] {
+				continue
+			}
+			result.BrokenRefs = append(result.BrokenRefs, BrokenRef{
+				From:     id,
+				Oneliner: entry.Oneliner,
+				Ref:      ref,
+				Deleted:  deleted[ref],
+			})
+		}
+		if len(g.out[id]) == 0 && len(g.in[id]) == 0 && len(entry.Refs) == 0 {
+			result.Orphans = append(result.Orphans, toListItem(id, entry))
+		}
+	}
+
+	result.Cycles = g.cycles()
+
+	centrality := g.betweenness()
+	for _, id := range g.ids {
+		if len(g.out[id])+len(g.in[id]) == 0 {
+			continue
+		}
+		result.Hubs = append(result.Hubs, HubItem{
+			ListItem:   toListItem(id, g.entries[id]),
+			InDegree:   len(g.in[id]),
+			OutDegree:  len(g.out[id]),
+			Centrality: math.Round(centrality[id]*1000) / 1000,
+		})
+	}
+	sort.SliceStable(result.Hubs, func(i, j int) bool {
+		a, b := result.Hubs[i], result.Hubs[j]
+		if da, db := a.InDegree+a.OutDegree, b.InDegree+b.OutDegree; da != db {
+			return da > db
+		}
+		return a.Centrality > b.Centrality
+	})
+	if hubs >= 0 && len(result.Hubs) > hubs {
+		result.Hubs = result.Hubs[:hubs]
+	}
+	return result, nil
+}
+
+// Path returns the shortest ref chain from one item to another. Refs are
+// followed in both directions unless directed is set.
+func (s *Store) Path(fromRef, toRef string, directed bool) ([]PathStep, error) {
+	if err := s.openLatest(); err != nil {
+		return nil, err
+	}
+	entries := s.df.Entries()
+	from, err := resolveID(entries, fromRef)
+	if err != nil {
+		return nil, err
+	}
+	to, err := resolveID(entries, toRef)
+	if err != nil {
+		return nil, err
+	}
+	g := s.refGraph()
+
+	type hop struct {
+		prev string
+		via  string
+	}
+	visited := map[string]hop{from: {}}
+	queue := []string{from}
+	for len(queue) > 0 && !hasKey(visited, to) {
+		current := queue[0]
+		queue = queue[1:]
+		visit := func(next, via string) {
+			if hasKey(visited, next) {
+				return
+			}
+			visited[next] = hop{prev: current, via: via}
+			queue = append(queue, next)
+		}
+		for _, next := range g.out[current] {
+			visit(next, "refs")
+		}
+		if !directed {
+			for _, next := range g.in[current] {
+				visit(next, "referenced_by")
+			}
+		}
+	}
+	if !hasKey(visited, to) {
+		return nil, fmt.Errorf("no ref path from %s to %s", from, to)
+	}
+
+	var steps []PathStep
+	for id := to; ; id = visited[id].prev {
+		steps = append(steps, PathStep{ListItem: toListItem(id, entries[id]), Via: visited[id].via})
+		if id == from {
+			break
+		}
+	}
+	for i, j := 0, len(steps)-1; i < j; i, j = i+1, j-1 {
+		steps[i], steps[j] = steps[j], steps[i]
+	}
+	return steps, nil
+}
+
+func (s *Store) refGraph() *refGraph {
+	entries := s.df.Entries()
+	g := &refGraph{
+		entries: entries,
+		out:     make(map[string][]string),
+		in:      make(map[string][]string),
+	}
+	for id := range entries {
+		g.ids = append(g.ids, id)
+	}
+	sort.Strings(g.ids)
+
+	referencedBy := reverseRefs(entries)
+	for _, id := range g.ids {
+		seen := make(map[string]bool)
+		for _, ref := range entries[id].Refs {
+			if _, ok := entries[ref]; ok && !seen[ref] {
+				seen[ref] = true
+				g.out[id] = append(g.out[id], ref)
+			}
+		}
+		sort.Strings(g.out[id])
+		for _, by := range referencedBy[id] {
+			if len(g.in[id]) == 0 || g.in[id][len(g.in[id])-1] != by {
+				g.in[id] = append(g.in[id], by)
+			}
+		}
+	}
+	return g
+}
+
+// stateRefIDs returns the IDs of session tasks and questions.
+func (s *Store) stateRefIDs() map[string]bool {
+	ids := make(map[string]bool)
+	if state := s.df.Index.State; state != nil {
+		for _, task := range state.Next {
+			ids[task.ID] = true
+		}
+		for _, question := range state.OpenQuestions {
+			ids[question.ID] = true
+		}
+	}
+	return ids
+}
+
+// cycles returns one shortest cycle through the smallest ID of each
+// strongly connected component that has one.
+func (g *refGraph) cycles() [][]string {
+	index := make(map[string]int)
+	low := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+	var components [][]string
+	next := 0
+
+	var connect func(id string)
+	connect = func(id string) {
+		index[id], low[id] = next, next
+		next++
+		stack = append(stack, id)
+		onStack[id] = true
+		for _, ref := range g.out[id] {
+			if _, seen := index[ref]; !seen {
+				connect(ref)
+				low[id] = min(low[id], low[ref])
+			} else if onStack[ref] {
+				low[id] = min(low[id], index[ref])
+			}
+		}
+		if low[id] != index[id] {
+			return
+		}
+		var component []string
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component = append(component, top)
+			if top == id {
+				break
+			}
+		}
+		components = append(components, component)
+	}
+	for _, id := range g.ids {
+		if _, seen := index[id]; !seen {
+			connect(id)
+		}
+	}
+
+	cycles := make([][]string, 0)
+	for _, component := range components {
+		sort.Strings(component)
+		start := component[0]
+		if len(component) == 1 && !contains(g.out[start], start) {
+			continue
+		}
+		members := make(map[string]bool, len(component))
+		for _, id := range component {
+			members[id] = true
+		}
+		cycles = append(cycles, g.cycleThrough(start, members))
+	}
+	sort.Slice(cycles, func(i, j int) bool { return cycles[i][0] < cycles[j][0] })
+	return cycles
+}
+
+// cycleThrough returns the shortest path start -> ... -> start inside members.
+func (g *refGraph) cycleThrough(start string, members map[string]bool) []string {
+	prev := make(map[string]string)
+	queue := []string{start}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, ref := range g.out[current] {
+			if !members[ref] {
+				continue
+			}
+			if ref == start {
+				path := []string{start}
+				for id := current; id != start; id = prev[id] {
+					path = append(path, id)
+				}
+				path = append(path, start)
+				for i, j := 1, len(path)-2; i < j; i, j = i+1, j-1 {
+					path[i], path[j] = path[j], path[i]
+				}
+				return path
+			}
+			if _, seen := prev[ref]; !seen {
+				prev[ref] = current
+				queue = append(queue, ref)
+			}
+		}
+	}
+	return []string{start}
+}
+
+// betweenness computes normalized betweenness centrality over the graph
+// with refs treated as undirected links (Brandes' algorithm).
+func (g *refGraph) betweenness() map[string]float64 {
+	neighbors := make(map[string][]string, len(g.ids))
+	for _, id := range g.ids {
+		seen := make(map[string]bool)
+		for _, list := range [][]string{g.out[id], g.in[id]} {
+			for _, n := range list {
+				if n != id && !seen[n] {
+					seen[n] = true
+					neighbors[id] = append(neighbors[id], n)
+				}
+			}
+		}
+	}
+
+	centrality := make(map[string]float64, len(g.ids))
+	for _, source := range g.ids {
+		var order []string
+		preds := make(map[string][]string)
+		sigma := map[string]float64{source: 1}
+		dist := map[string]int{source: 0}
+		queue := []string{source}
+		for len(queue) > 0 {
+			v := queue[0]
+			queue = queue[1:]
+			order = append(order, v)
+			for _, w := range neighbors[v] {
+				if _, seen := dist[w]; !seen {
+					dist[w] = dist[v] + 1
+					queue = append(queue, w)
+				}
+				if dist[w] == dist[v]+1 {
+					sigma[w] += sigma[v]
+					preds[w] = append(preds[w], v)
+				}
+			}
+		}
+		delta := make(map[string]float64)
+		for i := len(order) - 1; i >= 0; i-- {
+			w := order[i]
+			for _, v := range preds[w] {
+				delta[v] += sigma[v] / sigma[w] * (1 + delta[w])
+			}
+			if w != source {
+				centrality[w] += delta[w]
+			}
+		}
+	}
+
+	// Each undirected pair was counted from both ends.
+	if n := float64(len(g.ids)); n > 2 {
+		scale := 1 / ((n - 1) * (n - 2))
+		for id := range centrality {
+			centrality[id] *= scale
+		}
+	}
+	return centrality
+}
+
+func hasKey[V any](m map[string]V, key string) bool {
+	_, ok := m[key]
+	return ok
+}
+
+func contains(items []string, target string) bool {
+	for _, item := range items {
+		if item == target {
+			return true
+		}
+	}
+	return false
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"path/filepath"
 	"testing"
-	"time"
 
 	"github.com/sibellavia/dory/internal/models"
 )
@@ -54,7 +53,7 @@ func BenchmarkOpen(b *testing.B) {
 			b.ResetTimer()
 			for i := 0; i < b.N; i++ {
 				s2 := New(root)
-				if _, err := s2.List("", "", "", time.Time{}, time.Time{}); err != nil {
+				if _, err := s2.List(ListFilter{}); err != nil {
 					b.Fatal(err)
 				}
 				if err := s2.Close(); err != nil {
@@ -110,7 +109,7 @@ func BenchmarkList(b *testing.B) {
 
 			b.ResetTimer()
 			for i := 0; i < b.N; i++ {
-				if _, err := s.List("", "", "", time.Time{}, time.Time{}); err != nil {
+				if _, err := s.List(ListFilter{}); err != nil {
 					b.Fatal(err)
 				}
 			}
@@ -0,0 +1,263 @@
+package store
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sibellavia/dory/internal/config"
+	"github.com/sibellavia/dory/internal/models"
+	"github.com/sibellavia/dory/internal/query"
+)
+
+// ContextBudget selects items for a context that must fit a token budget.
+type ContextBudget struct {
+	Tokens  int
+	Bodies  bool
+	Topic   string
+	Filter  query.Expr
+	Ranking config.ContextRanking
+}
+
+// budgetCandidate is a ranked item plus the store that can load its body.
+type budgetCandidate struct {
+	item  BudgetedItem
+	store *Store
+}
+
+// EstimateTokens approximates the model token count of text at four
+// characters per token.
+func EstimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// BudgetedContext returns session state plus the highest-ranked items that
+// fit the budget. Tokens spent on the session state count against it.
+func (st *Stack) BudgetedContext(opts ContextBudget) (*ContextResult, error) {
+	if len(st.Layers) == 0 {
+		return nil, fmt.Errorf("no stores to read")
+	}
+	if opts.Tokens <= 0 {
+		return nil, fmt.Errorf("budget must be positive")
+	}
+
+	nearest := st.Layers[0].Store
+	if err := nearest.openLatest(); err != nil {
+		return nil, err
+	}
+	result := &ContextResult{
+		Project:  nearest.df.Index.Project,
+		Critical: make([]ListItem, 0),
+		Recent:   make([]ListItem, 0),
+	}
+	if nearest.df.Index.State != nil {
+		result.State = toContextState(nearest.df.Index.State)
+	}
+
+	now := time.Now()
+	seen := make(map[string]bool)
+	var candidates []budgetCandidate
+	for _, layer := range st.Layers {
+		layerCandidates, err := layer.Store.budgetCandidates(opts, now)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", layer.Origin, err)
+		}
+		for _, candidate := range layerCandidates {
+			if seen[candidate.item.ID] {
+				continue
+			}
+			seen[candidate.item.ID] = true
+			if st.Merged() {
+				candidate.item.Origin = layer.Origin
+			}
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	budget, err := packBudget(candidates, opts, stateTokens(result.State))
+	if err != nil {
+		return nil, err
+	}
+	result.Budget = budget
+	return result, nil
+}
+
+// reservePinned moves pinned candidates out of the ranking into the
+// result's Pinned list, charging their oneliners to the budget first so they
+// are never omitted, even when they alone exceed it. Their bodies are added
+// only when they fit. It returns the candidates left to rank.
+func reservePinned(result *BudgetResult, candidates []budgetCandidate, opts ContextBudget) ([]budgetCandidate, error) {
+	ranked := make([]budgetCandidate, 0, len(candidates))
+	var pinned []budgetCandidate
+	for _, candidate := range candidates {
+		if candidate.item.Pinned {
+			pinned = append(pinned, candidate)
+			result.Used += candidate.item.Tokens
+		} else {
+			ranked = append(ranked, candidate)
+		}
+	}
+	sort.Slice(pinned, func(i, j int) bool { return pinned[i].item.ID < pinned[j].item.ID })
+	for _, candidate := range pinned {
+		item := candidate.item
+		if opts.Bodies {
+			if err := addBody(&item, candidate.store, result.Used, opts.Tokens); err != nil {
+				return nil, err
+			}
+			result.Used += item.Tokens - candidate.item.Tokens
+		}
+		result.Pinned = append(result.Pinned, item)
+	}
+	return ranked, nil
+}
+
+// addBody adds the item's body when it fits in the tokens left after used.
+func addBody(item *BudgetedItem, s *Store, used, tokens int) error {
+	entry, err := s.df.Get(item.ID)
+	if err != nil {
+		return err
+	}
+	body := strings.Join(bodyLines(entry.Oneliner, entry.Body), "\n")
+	if cost := EstimateTokens(body); body != "" && used+cost <= tokens {
+		item.Body = body
+		item.Tokens += cost
+	}
+	return nil
+}
+
+// budgetCandidates scores every item in the store that passes the filter.
+func (s *Store) budgetCandidates(opts ContextBudget, now time.Time) ([]budgetCandidate, error) {
+	if err := s.openLatest(); err != nil {
+		return nil, err
+	}
+
+	entries := s.df.Entries()
+	degree := make(map[string]int)
+	for id, entry := range entries {
+		for _, ref := range entry.Refs {
+			if _, ok := entries[ref]; ok && ref != id {
+				degree[id]++
+				degree[ref]++
+			}
+		}
+	}
+	maxDegree := 0
+	for _, d := range degree {
+		if d > maxDegree {
+			maxDegree = d
+		}
+	}
+
+	ranking := opts.Ranking
+	candidates := make([]budgetCandidate, 0, len(entries))
+	for id, entry := range entries {
+		if opts.Filter != nil && !opts.Filter.Match(toQueryItem(id, entry)) {
+			continue
+		}
+
+		score := ranking.Severity * severitySignal(entry.Type, models.Severity(entry.Severity))
+		score += ranking.Recency * recencySignal(now.Sub(entry.Created), ranking.RecencyHalfLifeDays)
+		if opts.Topic != "" && (entry.Topic == opts.Topic || entry.Domain == opts.Topic) {
+			score += ranking.Tag
+		}
+		if maxDegree > 0 {
+			score += ranking.Centrality * float64(degree[id]) / float64(maxDegree)
+		}
+
+		item := toListItem(id, entry)
+		candidates = append(candidates, budgetCandidate{
+			item: BudgetedItem{
+				ListItem: item,
+				Score:    math.Round(score*1000) / 1000,
+				Tokens:   onelinerTokens(item),
+			},
+			store: s,
+		})
+	}
+	return candidates, nil
+}
+
+// packBudget takes pinned candidates first, then greedily takes the rest by
+// score until the budget is spent. A candidate whose oneliner does not fit is
+// omitted, but smaller ones further down may still be taken. Bodies are added
+// only when they fit.
+func packBudget(candidates []budgetCandidate, opts ContextBudget, reserved int) (*BudgetResult, error) {
+	result := &BudgetResult{
+		Tokens:   opts.Tokens,
+		Used:     reserved,
+		Included: make([]BudgetedItem, 0),
+	}
+	candidates, err := reservePinned(result, candidates, opts)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].item.Score != candidates[j].item.Score {
+			return candidates[i].item.Score > candidates[j].item.Score
+		}
+		return candidates[i].item.ID < candidates[j].item.ID
+	})
+
+	for _, candidate := range candidates {
+		item := candidate.item
+		if result.Used+item.Tokens > opts.Tokens {
+			result.Omitted = append(result.Omitted, item)
+			continue
+		}
+		if opts.Bodies {
+			if err := addBody(&item, candidate.store, result.Used+item.Tokens, opts.Tokens); err != nil {
+				return nil, err
+			}
+		}
+		result.Used += item.Tokens
+		result.Included = append(result.Included, item)
+	}
+	return result, nil
+}
+
+// severitySignal scales lesson severity to 0..1. Items without a severity
+// sit in the middle so decisions and patterns are not buried under lessons.
+func severitySignal(itemType string, severity models.Severity) float64 {
+	if itemType != "lesson" || !severity.Valid() {
+		return 0.5
+	}
+	return float64(severity.Rank()) / 4
+}
+
+// recencySignal decays from 1 by half every halfLifeDays.
+func recencySignal(age time.Duration, halfLifeDays float64) float64 {
+	if halfLifeDays <= 0 {
+		return 0
+	}
+	days := age.Hours() / 24
+	if days < 0 {
+		days = 0
+	}
+	return math.Pow(0.5, days/halfLifeDays)
+}
+
+// onelinerTokens estimates the cost of one rendered item line.
+func onelinerTokens(item ListItem) int {
+	return EstimateTokens(fmt.Sprintf("%s [%s] %s %s", item.ID, item.Type, item.Severity, item.Oneliner))
+}
+
+// stateTokens estimates the cost of rendering session state.
+func stateTokens(state *ContextState) int {
+	if state == nil {
+		return 0
+	}
+	text := state.Goal + state.Progress + state.Blocker
+	for _, task := range state.Next {
+		if task.Status != "done" {
+			text += task.ID + task.Text
+		}
+	}
+	for _, question := range state.OpenQuestions {
+		if question.Status != "answered" {
+			text += question.ID + question.Text
+		}
+	}
+	return EstimateTokens(text)
+}
@@ -0,0 +1,166 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sibellavia/dory/internal/doryfile"
+	"github.com/sibellavia/dory/internal/query"
+)
+
+// BulkEdit lists the changes applied to every item a bulk edit matches.
+// Zero values leave a field alone.
+type BulkEdit struct {
+	Tag string
+	// TagFrom limits Tag to replacing this tag, leaving other tag fields alone.
+	TagFrom string
+	// Severity is set on lessons only; other types have no severity.
+	Severity   string
+	Pinned     *bool
+	AddRefs    []string
+	RemoveRefs []string
+}
+
+// BulkResult reports which items a bulk edit matched and changed.
+type BulkResult struct {
+	Matched []ListItem `json:"matched" yaml:"matched"`
+	Changed []string   `json:"changed" yaml:"changed"`
+	DryRun  bool       `json:"dry_run,omitempty" yaml:"dry_run,omitempty"`
+}
+
+// BulkUpdate applies edit to every item matching filter. All refs are
+// validated before anything is written, and the changes are appended as one
+// batch under a single write lock. With dryRun set nothing is written.
+func (s *Store) BulkUpdate(filter query.Expr, edit BulkEdit, dryRun bool) (*BulkResult, error) {
+	if filter == nil {
+		return nil, fmt.Errorf("bulk edit needs a filter")
+	}
+	var result *BulkResult
+	run := func() error {
+		var err error
+		result, err = s.bulkUpdate(filter, edit, dryRun)
+		return err
+	}
+	if dryRun {
+		if err := s.openLatest(); err != nil {
+			return nil, err
+		}
+		err := run()
+		return result, err
+	}
+	err := s.withWriteLock(func() error {
+		if err := s.open(); err != nil {
+			return err
+		}
+		return run()
+	})
+	return result, err
+}
+
+// RenameTag moves every item tagged from to the tag to.
+func (s *Store) RenameTag(from, to string, dryRun bool) (*BulkResult, error) {
+	if from == "" || to == "" {
+		return nil, fmt.Errorf("tag names must not be empty")
+	}
+	filter, err := query.Equals("tag", from)
+	if err != nil {
+		return nil, err
+	}
+	return s.BulkUpdate(filter, BulkEdit{Tag: to, TagFrom: from}, dryRun)
+}
+
+func (s *Store) bulkUpdate(filter query.Expr, edit BulkEdit, dryRun bool) (*BulkResult, error) {
+	entries := s.df.Entries()
+	var ids []string
+	for id, entry := range entries {
+		if filter.Match(toQueryItem(id, entry)) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	addRefs, err := s.resolveRefs(edit.AddRefs, nil)
+	if err != nil {
+		return nil, err
+	}
+	removeRefs := make(map[string]bool, len(edit.RemoveRefs))
+	for _, ref := range edit.RemoveRefs {
+		if id, _ := matchID(entries, ref); id != "" {
+			ref = id
+		}
+		removeRefs[ref] = true
+	}
+
+	result := &BulkResult{Matched: make([]ListItem, 0, len(ids)), Changed: make([]string, 0), DryRun: dryRun}
+	var updates []*doryfile.Entry
+	for _, id := range ids {
+		result.Matched = append(result.Matched, toListItem(id, entries[id]))
+		entry, err := s.df.Get(id)
+		if err != nil {
+			return nil, err
+		}
+		if !applyBulkEdit(entry, edit, addRefs, removeRefs) {
+			continue
+		}
+		result.Changed = append(result.Changed, id)
+		updates = append(updates, entry)
+	}
+
+	if dryRun {
+		return result, nil
+	}
+	return result, s.df.AppendBatch(updates)
+}
+
+// applyBulkEdit changes entry in place and reports whether anything changed.
+func applyBulkEdit(entry *doryfile.Entry, edit BulkEdit, addRefs []string, removeRefs map[string]bool) bool {
+	changed := false
+	if edit.Tag != "" && edit.TagFrom != "" {
+		for _, field := range []*string{&entry.Topic, &entry.Domain} {
+			if strings.EqualFold(*field, edit.TagFrom) && *field != edit.Tag {
+				*field = edit.Tag
+				changed = true
+			}
+		}
+	} else if edit.Tag != "" {
+		// Keep the tag in whichever field the item already uses.
+		switch {
+		case entry.Topic == "" && entry.Domain != "":
+			changed = entry.Domain != edit.Tag
+			entry.Domain = edit.Tag
+		case entry.Domain == "":
+			changed = entry.Topic != edit.Tag
+			entry.Topic = edit.Tag
+		default:
+			changed = entry.Topic != edit.Tag || entry.Domain != edit.Tag
+			entry.Topic, entry.Domain = edit.Tag, edit.Tag
+		}
+	}
+	if edit.Severity != "" && entry.Type == "lesson" && entry.Severity != edit.Severity {
+		entry.Severity = edit.Severity
+		changed = true
+	}
+	if edit.Pinned != nil && entry.Pinned != *edit.Pinned {
+		entry.Pinned = *edit.Pinned
+		changed = true
+	}
+	if len(removeRefs) > 0 {
+		refs := make([]string, 0, len(entry.Refs))
+		for _, ref := range entry.Refs {
+			if removeRefs[ref] {
+				changed = true
+				continue
+			}
+			refs = append(refs, ref)
+		}
+		entry.Refs = refs
+	}
+	for _, ref := range addRefs {
+		if ref != entry.ID && !contains(entry.Refs, ref) {
+			entry.Refs = append(entry.Refs, ref)
+			changed = true
+		}
+	}
+	return changed
+}
@@ -0,0 +1,197 @@
+package store
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sibellavia/dory/internal/query"
+)
+
+// ChangeContext returns the items relevant to a set of file paths, merged
+// across all layers.
+func (st *Stack) ChangeContext(paths []string, filter query.Expr) (*ChangesResult, error) {
+	paths = normalizePaths(paths)
+	result := &ChangesResult{Paths: paths, Items: make([]ChangeItem, 0)}
+	seen := make(map[string]bool)
+	for _, layer := range st.Layers {
+		items, err := layer.Store.changeItems(paths, filter)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", layer.Origin, err)
+		}
+		for _, item := range items {
+			if seen[item.ID] {
+				continue
+			}
+			seen[item.ID] = true
+			if st.Merged() {
+				item.Origin = layer.Origin
+			}
+			result.Items = append(result.Items, item)
+		}
+	}
+	sortChangeItems(result.Items)
+	return result, nil
+}
+
+// changeItems matches items to paths in two ways: by tag, when a tag names
+// a directory or file of a path, and by anchor, when an item's oneliner or
+// body mentions a path or its file name.
+func (s *Store) changeItems(paths []string, filter query.Expr) ([]ChangeItem, error) {
+	if err := s.openLatest(); err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	segments := make(map[string]bool)
+	for _, p := range paths {
+		for _, segment := range pathSegments(p) {
+			segments[segment] = true
+		}
+	}
+
+	items := make([]ChangeItem, 0)
+	for id, entry := range s.df.Entries() {
+		if filter != nil && !filter.Match(toQueryItem(id, entry)) {
+			continue
+		}
+
+		var reasons []string
+		for _, tag := range []string{entry.Topic, entry.Domain} {
+			if tag != "" && segments[strings.ToLower(tag)] {
+				reasons = append(reasons, "tag:"+tag)
+			}
+		}
+
+		full, err := s.df.Get(id)
+		if err != nil {
+			return nil, err
+		}
+		text := full.Oneliner + "\n" + full.Body
+		for _, p := range paths {
+			if anchor := anchorFor(text, p); anchor != "" {
+				reasons = append(reasons, "mentions "+anchor)
+			}
+		}
+
+		if len(reasons) > 0 {
+			items = append(items, ChangeItem{ListItem: toListItem(id, entry), Reasons: reasons})
+		}
+	}
+	return items, nil
+}
+
+// anchorFor returns the form of p that text mentions: the path itself, or
+// its file name when that has an extension. It returns "" when neither is
+// mentioned.
+func anchorFor(text, p string) string {
+	if mentions(text, p) {
+		return p
+	}
+	base := path.Base(p)
+	if base != p && strings.Contains(base, ".") && mentions(text, base) {
+		return base
+	}
+	return ""
+}
+
+// mentions reports whether text contains name as a whole path: not inside a
+// longer name, so main.go is not found in domain.go and user.go not in
+// user.go.bak. A "/" may precede it, and a sentence's closing period or a
+// :line suffix may follow.
+func mentions(text, name string) bool {
+	if name == "" {
+		return false
+	}
+	for from := 0; ; {
+		i := strings.Index(text[from:], name)
+		if i < 0 {
+			return false
+		}
+		start, end := from+i, from+i+len(name)
+		if pathBoundaryBefore(text[:start]) && pathBoundaryAfter(text[end:]) {
+			return true
+		}
+		from = start + 1
+	}
+}
+
+func pathBoundaryBefore(before string) bool {
+	r, _ := utf8.DecodeLastRuneInString(before)
+	return before == "" || !isPathRune(r)
+}
+
+func pathBoundaryAfter(after string) bool {
+	r, size := utf8.DecodeRuneInString(after)
+	if after == "" || !isPathRune(r) {
+		return true
+	}
+	// A period ends the mention only when it ends the sentence.
+	if r == '.' {
+		next, _ := utf8.DecodeRuneInString(after[size:])
+		return len(after) == size || !isPathRune(next)
+	}
+	return false
+}
+
+// isPathRune reports whether r can be part of a file name.
+func isPathRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '.'
+}
+
+// pathSegments returns the lowercased directories and file stem of a path.
+func pathSegments(p string) []string {
+	parts := strings.Split(strings.ToLower(p), "/")
+	if last := len(parts) - 1; last >= 0 {
+		if ext := path.Ext(parts[last]); ext != "" && ext != parts[last] {
+			parts = append(parts, strings.TrimSuffix(parts[last], ext))
+		}
+	}
+	segments := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part != "" && part != "." && part != ".." {
+			segments = append(segments, part)
+		}
+	}
+	return segments
+}
+
+// normalizePaths cleans paths to slash form and drops duplicates and files
+// inside dory stores.
+func normalizePaths(paths []string) []string {
+	seen := make(map[string]bool)
+	result := make([]string, 0, len(paths))
+	for _, p := range paths {
+		p = strings.TrimSpace(strings.ReplaceAll(p, "\\", "/"))
+		if p == "" {
+			continue
+		}
+		p = strings.TrimPrefix(path.Clean(p), "./")
+		if seen[p] || p == DoryDir || strings.HasPrefix(p, DoryDir+"/") || strings.Contains(p, "/"+DoryDir+"/") {
+			continue
+		}
+		seen[p] = true
+		result = append(result, p)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// sortChangeItems puts items with the most reasons first, then more severe
+// lessons, then by ID.
+func sortChangeItems(items []ChangeItem) {
+	sort.Slice(items, func(i, j int) bool {
+		if len(items[i].Reasons) != len(items[j].Reasons) {
+			return len(items[i].Reasons) > len(items[j].Reasons)
+		}
+		if c := items[i].Severity.Compare(items[j].Severity); c != 0 {
+			return c > 0
+		}
+		return items[i].ID < items[j].ID
+	})
+}
@@ -89,7 +89,7 @@ func TestConcurrentStoreWrites(t *testing.T) {
 
 	// Verify all successful writes are readable
 	finalStore := New(root)
-	items, err := finalStore.List("", "lesson", "", time.Time{}, time.Time{})
+	items, err := finalStore.List(ListFilter{Type: "lesson"})
 	if err != nil {
 		t.Fatalf("Final list failed: %v", err)
 	}
@@ -181,7 +181,7 @@ func TestConcurrentReadsWhileWriting(t *testing.T) {
 				}
 
 				store := New(root)
-				items, err := store.List("", "", "", time.Time{}, time.Time{})
+				items, err := store.List(ListFilter{})
 				store.Close()
 
 				if err != nil {
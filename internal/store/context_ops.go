@@ -3,10 +3,13 @@ package store
 import (
 	"sort"
 	"time"
+
+	"github.com/sibellavia/dory/internal/query"
 )
 
 // Context returns smart context for agent session start.
-func (s *Store) Context(topic string, recentDays int, full bool) (*ContextResult, error) {
+// A non-nil filter restricts every item section to matching items.
+func (s *Store) Context(topic string, recentDays int, full bool, filter query.Expr) (*ContextResult, error) {
 	if err := s.openLatest(); err != nil {
 		return nil, err
 	}
@@ -14,26 +17,28 @@ func (s *Store) Context(topic string, recentDays int, full bool) (*ContextResult
 	result := &ContextResult{Project: s.df.Index.Project}
 
 	if s.df.Index.State != nil {
-		st := s.df.Index.State
-		result.State = &ContextState{
-			Goal:        st.Goal,
-			Progress:    st.Progress,
-			Blocker:     st.Blocker,
-			Next:        st.Next,
-			LastUpdated: st.LastUpdated,
-		}
+		result.State = toContextState(s.df.Index.State)
 	}
 
 	recentCutoff := time.Now().AddDate(0, 0, -recentDays)
 	entries := s.df.Entries()
 
+	pinned := make([]ListItem, 0)
 	critical := make([]ListItem, 0)
 	recent := make([]ListItem, 0)
 	topicItems := make([]ListItem, 0)
 
 	for id, entry := range entries {
+		if filter != nil && !filter.Match(toQueryItem(id, entry)) {
+			continue
+		}
 		item := toListItem(id, entry)
 
+		if entry.Pinned {
+			pinned = append(pinned, item)
+			continue
+		}
+
 		if entry.Type == "lesson" && (entry.Severity == "critical" || entry.Severity == "high") {
 			critical = append(critical, item)
 		}
@@ -57,6 +62,7 @@ func (s *Store) Context(topic string, recentDays int, full bool) (*ContextResult
 		})
 	}
 
+	sortItems(pinned)
 	sortItems(critical)
 	sortItems(recent)
 	sortItems(topicItems)
@@ -72,6 +78,9 @@ func (s *Store) Context(topic string, recentDays int, full bool) (*ContextResult
 		}
 	}
 
+	if len(pinned) > 0 {
+		result.Pinned = pinned
+	}
 	result.Critical = critical
 	result.Recent = dedupedRecent
 	if topic != "" {
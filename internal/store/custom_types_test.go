@@ -3,7 +3,6 @@ package store
 import (
 	"path/filepath"
 	"testing"
-	"time"
 )
 
 func TestCreateCustomAndList(t *testing.T) {
@@ -13,6 +12,7 @@ func TestCreateCustomAndList(t *testing.T) {
 		t.Fatalf("init: %v", err)
 	}
 	defer s.Close()
+	s.AllowMissingRefs = true
 
 	id, err := s.CreateCustom("incident", "DB outage", "ops", "", []string{"L001"})
 	if err != nil {
@@ -22,7 +22,7 @@ func TestCreateCustomAndList(t *testing.T) {
 		t.Fatal("expected id")
 	}
 
-	items, err := s.List("", "incident", "", time.Time{}, time.Time{})
+	items, err := s.List(ListFilter{Type: "incident"})
 	if err != nil {
 		t.Fatalf("list: %v", err)
 	}
@@ -0,0 +1,294 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sibellavia/dory/internal/doryfile"
+)
+
+// diffContext is the number of unchanged lines kept around body changes.
+const diffContext = 2
+
+// Snapshot is a store's items and session state at one point in its history.
+type Snapshot struct {
+	Label string
+	Seq   uint64
+	Items map[string]*doryfile.Entry
+	State *doryfile.State
+}
+
+// DiffResult lists what changed between two snapshots.
+type DiffResult struct {
+	From     string        `json:"from" yaml:"from"`
+	To       string        `json:"to" yaml:"to"`
+	Added    []ListItem    `json:"added" yaml:"added"`
+	Removed  []ListItem    `json:"removed" yaml:"removed"`
+	Modified []ItemDiff    `json:"modified" yaml:"modified"`
+	State    []FieldChange `json:"state,omitempty" yaml:"state,omitempty"`
+}
+
+// ItemDiff is an item present on both sides whose fields or body changed.
+// Body holds diff lines prefixed with " ", "-" or "+"; "..." separates hunks.
+type ItemDiff struct {
+	ListItem `yaml:",inline"`
+	Fields   []FieldChange `json:"fields,omitempty" yaml:"fields,omitempty"`
+	Body     []string      `json:"body,omitempty" yaml:"body,omitempty"`
+}
+
+// FieldChange is one field whose value differs between the two sides.
+type FieldChange struct {
+	Field string `json:"field" yaml:"field"`
+	From  string `json:"from" yaml:"from"`
+	To    string `json:"to" yaml:"to"`
+}
+
+// Empty reports whether the two sides are identical.
+func (d *DiffResult) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Modified) == 0 && len(d.State) == 0
+}
+
+// LoadSnapshot reads the store in root as it was after event seq. A zero
+// seq reads the latest state.
+func LoadSnapshot(root string, seq uint64) (*Snapshot, error) {
+	df, err := doryfile.OpenAt(root, seq)
+	if err != nil {
+		return nil, err
+	}
+	defer df.Close()
+	if seq > df.Seq() {
+		return nil, fmt.Errorf("seq %d is past the end of the log (last seq is %d)", seq, df.Seq())
+	}
+
+	snap := &Snapshot{
+		Seq:   df.Seq(),
+		Items: make(map[string]*doryfile.Entry),
+		State: df.Index.State,
+	}
+	for id := range df.Entries() {
+		entry, err := df.Get(id)
+		if err != nil {
+			return nil, err
+		}
+		snap.Items[id] = entry
+	}
+	return snap, nil
+}
+
+// EmptySnapshot is the state of a store before its first event.
+func EmptySnapshot() *Snapshot {
+	return &Snapshot{Items: make(map[string]*doryfile.Entry), State: &doryfile.State{}}
+}
+
+// Diff compares two snapshots item by item and reports state changes.
+func Diff(from, to *Snapshot) *DiffResult {
+	result := &DiffResult{
+		From:     from.Label,
+		To:       to.Label,
+		Added:    make([]ListItem, 0),
+		Removed:  make([]ListItem, 0),
+		Modified: make([]ItemDiff, 0),
+	}
+
+	for _, id := range sortedIDs(from.Items, to.Items) {
+		before, after := from.Items[id], to.Items[id]
+		switch {
+		case before == nil:
+			result.Added = append(result.Added, entryListItem(after))
+		case after == nil:
+			result.Removed = append(result.Removed, entryListItem(before))
+		default:
+			fields := entryFieldChanges(before, after)
+			var body []string
+			if before.Body != after.Body {
+				body = lineDiff(before.Body, after.Body)
+			}
+			if len(fields) > 0 || len(body) > 0 {
+				result.Modified = append(result.Modified, ItemDiff{
+					ListItem: entryListItem(after),
+					Fields:   fields,
+					Body:     body,
+				})
+			}
+		}
+	}
+
+	result.State = stateChanges(from.State, to.State)
+	return result
+}
+
+func sortedIDs(sides ...map[string]*doryfile.Entry) []string {
+	seen := make(map[string]bool)
+	var ids []string
+	for _, side := range sides {
+		for id := range side {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func entryListItem(entry *doryfile.Entry) ListItem {
+	return toListItem(entry.ID, &doryfile.MemoryEntry{
+		Type:     entry.Type,
+		Topic:    entry.Topic,
+		Domain:   entry.Domain,
+		Severity: entry.Severity,
+		Oneliner: entry.Oneliner,
+		Created:  entry.Created,
+		Alias:    entry.Alias,
+		Pinned:   entry.Pinned,
+	})
+}
+
+func entryFieldChanges(before, after *doryfile.Entry) []FieldChange {
+	var changes []FieldChange
+	add := func(field, from, to string) {
+		if from != to {
+			changes = append(changes, FieldChange{Field: field, From: from, To: to})
+		}
+	}
+	add("type", before.Type, after.Type)
+	add("oneliner", before.Oneliner, after.Oneliner)
+	add("topic", before.Topic, after.Topic)
+	add("domain", before.Domain, after.Domain)
+	add("severity", before.Severity, after.Severity)
+	add("refs", strings.Join(before.Refs, ", "), strings.Join(after.Refs, ", "))
+	add("alias", before.Alias, after.Alias)
+	add("pinned", strconv.FormatBool(before.Pinned), strconv.FormatBool(after.Pinned))
+	return changes
+}
+
+func stateChanges(before, after *doryfile.State) []FieldChange {
+	if before == nil {
+		before = &doryfile.State{}
+	}
+	if after == nil {
+		after = &doryfile.State{}
+	}
+	var changes []FieldChange
+	add := func(field, from, to string) {
+		if from != to {
+			changes = append(changes, FieldChange{Field: field, From: from, To: to})
+		}
+	}
+	add("goal", before.Goal, after.Goal)
+	add("progress", before.Progress, after.Progress)
+	add("blocker", before.Blocker, after.Blocker)
+	add("working_files", strings.Join(before.WorkingFiles, ", "), strings.Join(after.WorkingFiles, ", "))
+
+	tasks := func(state *doryfile.State) map[string]string {
+		m := make(map[string]string)
+		for _, task := range state.Next {
+			m[task.ID] = fmt.Sprintf("[%s] %s", task.Status, task.Text)
+		}
+		return m
+	}
+	questions := func(state *doryfile.State) map[string]string {
+		m := make(map[string]string)
+		for _, q := range state.OpenQuestions {
+			text := fmt.Sprintf("[%s] %s", q.Status, q.Text)
+			if q.AnsweredBy != "" {
+				text += " -> " + q.AnsweredBy
+			} else if q.Answer != "" {
+				text += " -> " + q.Answer
+			}
+			m[q.ID] = text
+		}
+		return m
+	}
+	for _, pair := range []struct {
+		before, after map[string]string
+	}{
+		{tasks(before), tasks(after)},
+		{questions(before), questions(after)},
+	} {
+		var ids []string
+		for id := range pair.before {
+			ids = append(ids, id)
+		}
+		for id := range pair.after {
+			if !hasKey(pair.before, id) {
+				ids = append(ids, id)
+			}
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			add(id, pair.before[id], pair.after[id])
+		}
+	}
+	return changes
+}
+
+// lineDiff returns a line diff of two texts, keeping diffContext unchanged
+// lines around each change.
+func lineDiff(a, b string) []string {
+	x, y := splitLines(a), splitLines(b)
+
+	// lcs[i][j] is the length of the longest common subsequence of x[i:] and y[j:].
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var lines []string
+	for i, j := 0, 0; i < len(x) || j < len(y); {
+		switch {
+		case i < len(x) && j < len(y) && x[i] == y[j]:
+			lines = append(lines, " "+x[i])
+			i++
+			j++
+		case i < len(x) && (j == len(y) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, "-"+x[i])
+			i++
+		default:
+			lines = append(lines, "+"+y[j])
+			j++
+		}
+	}
+
+	// Keep only changed lines and their context.
+	keep := make([]bool, len(lines))
+	for i, line := range lines {
+		if line[0] == ' ' {
+			continue
+		}
+		for k := max(0, i-diffContext); k <= min(len(lines)-1, i+diffContext); k++ {
+			keep[k] = true
+		}
+	}
+	var hunks []string
+	for i, line := range lines {
+		if !keep[i] {
+			continue
+		}
+		if len(hunks) > 0 && !keep[i-1] {
+			hunks = append(hunks, "...")
+		}
+		hunks = append(hunks, line)
+	}
+	return hunks
+}
+
+func splitLines(text string) []string {
+	text = strings.TrimRight(text, "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
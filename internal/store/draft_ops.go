@@ -0,0 +1,217 @@
+package store
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sibellavia/dory/internal/fileio"
+	"github.com/sibellavia/dory/internal/idgen"
+	"github.com/sibellavia/dory/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+const draftsFile = "drafts.yaml"
+
+// Draft statuses.
+const (
+	DraftPending  = "pending"
+	DraftAccepted = "accepted"
+	DraftRejected = "rejected"
+)
+
+// Draft is a proposed lesson waiting for review. Drafts live in
+// drafts.yaml, outside the log, until they are accepted as items.
+type Draft struct {
+	ID       string    `json:"id" yaml:"id"`
+	Status   string    `json:"status" yaml:"status"`
+	Signal   string    `json:"signal" yaml:"signal"`
+	Oneliner string    `json:"oneliner" yaml:"oneliner"`
+	Topic    string    `json:"tag,omitempty" yaml:"tag,omitempty"`
+	Severity string    `json:"severity" yaml:"severity"`
+	Source   string    `json:"source" yaml:"source"`
+	Line     int       `json:"line" yaml:"line"`
+	Created  time.Time `json:"created" yaml:"created"`
+	ItemID   string    `json:"item,omitempty" yaml:"item,omitempty"`
+	Body     string    `json:"body" yaml:"body"`
+}
+
+type draftQueue struct {
+	Next   int     `yaml:"next"`
+	Drafts []Draft `yaml:"drafts"`
+}
+
+// Drafts returns the review queue, oldest first, including drafts already
+// accepted or rejected.
+func (s *Store) Drafts() ([]Draft, error) {
+	queue, err := s.loadDrafts()
+	if err != nil {
+		return nil, err
+	}
+	return queue.Drafts, nil
+}
+
+// Draft returns one draft by ID.
+func (s *Store) Draft(id string) (*Draft, error) {
+	queue, err := s.loadDrafts()
+	if err != nil {
+		return nil, err
+	}
+	if d := queue.find(id); d != nil {
+		return d, nil
+	}
+	return nil, fmt.Errorf("draft %s not found", id)
+}
+
+// AddDrafts queues drafts as pending and returns the ones added. A draft
+// from the same source, line, and signal as one already queued (in any
+// status) is skipped, so harvesting a transcript again adds only what is new.
+func (s *Store) AddDrafts(drafts []Draft) ([]Draft, error) {
+	added := make([]Draft, 0, len(drafts))
+	err := s.withWriteLock(func() error {
+		queue, err := s.loadDrafts()
+		if err != nil {
+			return err
+		}
+		seen := make(map[string]bool, len(queue.Drafts))
+		for _, d := range queue.Drafts {
+			seen[d.key()] = true
+		}
+		for _, d := range drafts {
+			if seen[d.key()] {
+				continue
+			}
+			seen[d.key()] = true
+			queue.Next++
+			d.ID = "DR-" + strconv.Itoa(queue.Next)
+			d.Status = DraftPending
+			if d.Created.IsZero() {
+				d.Created = time.Now()
+			}
+			queue.Drafts = append(queue.Drafts, d)
+			added = append(added, d)
+		}
+		if len(added) == 0 {
+			return nil
+		}
+		return s.saveDrafts(queue)
+	})
+	return added, err
+}
+
+// ResolveDraft marks a pending draft accepted (as item itemID) or rejected.
+func (s *Store) ResolveDraft(id, status, itemID string) (*Draft, error) {
+	switch status {
+	case DraftAccepted, DraftRejected:
+	default:
+		return nil, fmt.Errorf("invalid draft status %q", status)
+	}
+	var result *Draft
+	err := s.withWriteLock(func() error {
+		queue, err := s.loadDrafts()
+		if err != nil {
+			return err
+		}
+		d := queue.find(id)
+		if d == nil {
+			return fmt.Errorf("draft %s not found", id)
+		}
+		if d.Status != DraftPending {
+			return fmt.Errorf("draft %s is already %s", d.ID, d.Status)
+		}
+		d.Status = status
+		d.ItemID = itemID
+		resolved := *d
+		result = &resolved
+		return s.saveDrafts(queue)
+	})
+	return result, err
+}
+
+// AcceptDraft creates a lesson from a pending draft and marks the draft
+// accepted with its ID, under one write lock. The draft is claimed before the
+// lesson is written and released if the write fails, so concurrent or
+// retried accepts of one draft never create two lessons.
+func (s *Store) AcceptDraft(id, oneliner, topic string, severity models.Severity, body string) (string, error) {
+	var itemID string
+	err := s.withWriteLock(func() error {
+		queue, err := s.loadDrafts()
+		if err != nil {
+			return err
+		}
+		d := queue.find(id)
+		if d == nil {
+			return fmt.Errorf("draft %s not found", id)
+		}
+		if d.Status != DraftPending {
+			return fmt.Errorf("draft %s is already %s", d.ID, d.Status)
+		}
+		if err := s.open(); err != nil {
+			return err
+		}
+		itemID, err = idgen.NewItemID("lesson")
+		if err != nil {
+			return err
+		}
+
+		d.Status, d.ItemID = DraftAccepted, itemID
+		if err := s.saveDrafts(queue); err != nil {
+			return err
+		}
+		entry := newEntry(itemID, "lesson", oneliner, topic, "", string(severity), lessonBody(oneliner, body), nil)
+		if err := s.appendNew(entry); err != nil {
+			d.Status, d.ItemID = DraftPending, ""
+			if releaseErr := s.saveDrafts(queue); releaseErr != nil {
+				return fmt.Errorf("failed to append lesson: %w (draft %s left accepted: %v)", err, d.ID, releaseErr)
+			}
+			return fmt.Errorf("failed to append lesson: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return itemID, nil
+}
+
+func (q *draftQueue) find(id string) *Draft {
+	for i := range q.Drafts {
+		if strings.EqualFold(q.Drafts[i].ID, id) {
+			return &q.Drafts[i]
+		}
+	}
+	return nil
+}
+
+func (d Draft) key() string {
+	return d.Source + "\x00" + strconv.Itoa(d.Line) + "\x00" + d.Signal
+}
+
+func (s *Store) loadDrafts() (*draftQueue, error) {
+	data, err := os.ReadFile(filepath.Join(s.Root, draftsFile))
+	if os.IsNotExist(err) {
+		return &draftQueue{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var queue draftQueue
+	if err := yaml.Unmarshal(data, &queue); err != nil {
+		return nil, fmt.Errorf("%s: %w", draftsFile, err)
+	}
+	return &queue, nil
+}
+
+func (s *Store) saveDrafts(queue *draftQueue) error {
+	data, err := yaml.Marshal(queue)
+	if err != nil {
+		return err
+	}
+	if err := ignoreLocalFiles(s.Root); err != nil {
+		return err
+	}
+	return fileio.WriteFileAtomic(filepath.Join(s.Root, draftsFile), data, 0600)
+}
@@ -0,0 +1,238 @@
+package store
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/sibellavia/dory/internal/config"
+	"github.com/sibellavia/dory/internal/doryfile"
+)
+
+// DumpFormat identifies a dory dump stream.
+const DumpFormat = "dory-dump-v1"
+
+// Dump record kinds.
+const (
+	DumpKindHeader = "header"
+	DumpKindItem   = "item"
+	DumpKindState  = "state"
+	DumpKindEvent  = "event"
+)
+
+// DumpRecord is one record of a dump stream. The first record is a header.
+// A snapshot dump follows it with every live item and the session state; a
+// history dump follows it with every event of the log instead.
+type DumpRecord struct {
+	Kind string `json:"kind" yaml:"kind"`
+
+	// Header fields.
+	Format      string                `json:"format,omitempty" yaml:"format,omitempty"`
+	Project     string                `json:"project,omitempty" yaml:"project,omitempty"`
+	Description string                `json:"description,omitempty" yaml:"description,omitempty"`
+	DumpedAt    string                `json:"dumped_at,omitempty" yaml:"dumped_at,omitempty"`
+	History     bool                  `json:"history,omitempty" yaml:"history,omitempty"`
+	AliasSeq    map[string]int        `json:"alias_seq,omitempty" yaml:"alias_seq,omitempty"`
+	Config      *config.ProjectConfig `json:"config,omitempty" yaml:"config,omitempty"`
+
+	// Event fields. ID is only set for deletes.
+	Seq uint64 `json:"seq,omitempty" yaml:"seq,omitempty"`
+	Op  string `json:"op,omitempty" yaml:"op,omitempty"`
+	ID  string `json:"id,omitempty" yaml:"id,omitempty"`
+
+	Item     *doryfile.Entry    `json:"item,omitempty" yaml:"item,omitempty"`
+	State    *doryfile.State    `json:"state,omitempty" yaml:"state,omitempty"`
+	Task     *doryfile.Task     `json:"task,omitempty" yaml:"task,omitempty"`
+	Question *doryfile.Question `json:"question,omitempty" yaml:"question,omitempty"`
+}
+
+// LoadResult summarizes a load.
+type LoadResult struct {
+	Project string `json:"project" yaml:"project"`
+	Path    string `json:"path" yaml:"path"`
+	History bool   `json:"history" yaml:"history"`
+	Items   int    `json:"items" yaml:"items"`
+	Events  int    `json:"events" yaml:"events"`
+}
+
+// Dump streams the store to emit, header first. With history every event
+// of the log is dumped, including deleted items and past versions.
+func (s *Store) Dump(history bool, emit func(*DumpRecord) error) error {
+	if err := s.openLatest(); err != nil {
+		return err
+	}
+
+	header := &DumpRecord{
+		Kind:        DumpKindHeader,
+		Format:      DumpFormat,
+		Project:     s.df.Index.Project,
+		Description: s.df.Index.Description,
+		DumpedAt:    time.Now().UTC().Format(time.RFC3339),
+		History:     history,
+		AliasSeq:    s.df.Index.AliasSeq,
+	}
+	if _, err := os.Stat(config.Path(s.Root)); err == nil {
+		cfg, err := config.Load(s.Root)
+		if err != nil {
+			return err
+		}
+		header.Config = cfg
+	}
+	if err := emit(header); err != nil {
+		return err
+	}
+
+	if history {
+		var emitErr error
+		err := s.df.Events(func(ev doryfile.Event) bool {
+			record := &DumpRecord{
+				Kind:     DumpKindEvent,
+				Seq:      ev.Seq,
+				Op:       ev.Op,
+				Item:     ev.Item,
+				State:    ev.State,
+				Task:     ev.Task,
+				Question: ev.Question,
+			}
+			if ev.Op == doryfile.OpItemDelete {
+				record.ID = ev.ID
+			}
+			emitErr = emit(record)
+			return emitErr == nil
+		})
+		if err != nil {
+			return err
+		}
+		return emitErr
+	}
+
+	entries := s.df.Entries()
+	ids := make([]string, 0, len(entries))
+	for id := range entries {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		entry, err := s.df.Get(id)
+		if err != nil {
+			return err
+		}
+		if err := emit(&DumpRecord{Kind: DumpKindItem, Item: entry}); err != nil {
+			return err
+		}
+	}
+	if state := s.df.Index.State; !state.IsEmpty() {
+		return emit(&DumpRecord{Kind: DumpKindState, State: state})
+	}
+	return nil
+}
+
+// Load rebuilds a store at root from a dump, keeping every ID, alias, and
+// (for history dumps) event seq. The store must not exist yet or must be
+// empty, as left by init.
+func Load(root string, records []DumpRecord) (*LoadResult, error) {
+	if len(records) == 0 || records[0].Kind != DumpKindHeader {
+		return nil, fmt.Errorf("not a dory dump: missing %s record", DumpKindHeader)
+	}
+	header := records[0]
+	if header.Format != DumpFormat {
+		return nil, fmt.Errorf("unsupported dump format %q (expected %s)", header.Format, DumpFormat)
+	}
+	events, items, err := dumpEvents(header.History, records[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	s := New(root)
+	if s.Exists() {
+		if err := s.open(); err != nil {
+			return nil, err
+		}
+		seq := s.df.Seq()
+		s.Close()
+		if seq > 0 {
+			return nil, fmt.Errorf("%s already holds knowledge; load into a new or empty store", root)
+		}
+	}
+	if err := ensureDir(root); err != nil {
+		return nil, fmt.Errorf("failed to create root directory: %w", err)
+	}
+
+	err = s.withWriteLock(func() error {
+		df, err := doryfile.Create(root, header.Project, header.Description)
+		if err != nil {
+			return fmt.Errorf("failed to create dory storage: %w", err)
+		}
+		defer df.Close()
+		if err := df.AppendEvents(events); err != nil {
+			return err
+		}
+		if err := df.RaiseAliasSeq(header.AliasSeq); err != nil {
+			return err
+		}
+		if header.Config != nil {
+			return config.Save(root, header.Config)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &LoadResult{
+		Project: header.Project,
+		Path:    root,
+		History: header.History,
+		Items:   items,
+		Events:  len(events),
+	}, nil
+}
+
+// dumpEvents turns dump records into the events that recreate them, and
+// counts the live items they leave.
+func dumpEvents(history bool, records []DumpRecord) ([]doryfile.Event, int, error) {
+	events := make([]doryfile.Event, 0, len(records))
+	live := make(map[string]bool)
+	for i, record := range records {
+		event := doryfile.Event{
+			Seq:      uint64(i + 1),
+			Op:       record.Op,
+			ID:       record.ID,
+			Item:     record.Item,
+			State:    record.State,
+			Task:     record.Task,
+			Question: record.Question,
+		}
+		switch {
+		case history && record.Kind == DumpKindEvent:
+			if record.Seq != event.Seq {
+				return nil, 0, fmt.Errorf("dump event %d out of order (expected seq %d)", record.Seq, event.Seq)
+			}
+		case !history && record.Kind == DumpKindItem && record.Item != nil:
+			if live[record.Item.ID] {
+				return nil, 0, fmt.Errorf("dump lists item %s twice", record.Item.ID)
+			}
+			event.Op = doryfile.OpItemCreate
+		case !history && record.Kind == DumpKindState:
+			event.Op = doryfile.OpState
+		default:
+			return nil, 0, fmt.Errorf("unexpected %q record %d in a %s dump", record.Kind, i+2, dumpMode(history))
+		}
+
+		switch {
+		case event.Item != nil:
+			live[event.Item.ID] = true
+		case event.Op == doryfile.OpItemDelete:
+			delete(live, event.ID)
+		}
+		events = append(events, event)
+	}
+	return events, len(live), nil
+}
+
+func dumpMode(history bool) string {
+	if history {
+		return "history"
+	}
+	return "snapshot"
+}
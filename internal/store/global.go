@@ -0,0 +1,46 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+)
+
+const (
+	// GlobalDirEnv overrides the location of the user-global store.
+	GlobalDirEnv = "DORY_GLOBAL_DIR"
+
+	// GlobalOrigin labels items read from the user-global store.
+	GlobalOrigin = "global"
+)
+
+// GlobalRoot returns the user-global store directory.
+// It honors DORY_GLOBAL_DIR, then XDG_DATA_HOME, then ~/.local/share/dory.
+func GlobalRoot() (string, error) {
+	if dir := os.Getenv(GlobalDirEnv); dir != "" {
+		return filepath.Abs(dir)
+	}
+	if dataHome := os.Getenv("XDG_DATA_HOME"); dataHome != "" {
+		return filepath.Join(dataHome, "dory"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share", "dory"), nil
+}
+
+// EnsureGlobal initializes the user-global store on first use.
+func EnsureGlobal() (string, error) {
+	root, err := GlobalRoot()
+	if err != nil {
+		return "", err
+	}
+	s := New(root)
+	defer s.Close()
+	if !s.Exists() {
+		if err := s.Init(GlobalOrigin, "User-global knowledge shared across projects"); err != nil {
+			return "", err
+		}
+	}
+	return root, nil
+}
@@ -3,6 +3,8 @@ package store
 import (
 	"fmt"
 	"sort"
+
+	"github.com/sibellavia/dory/internal/doryfile"
 )
 
 // Refs returns relationship information for an item.
@@ -89,12 +91,7 @@ func (s *Store) Expand(id string, depth int) (*ExpandResult, error) {
 		return nil, fmt.Errorf("item %s not found", id)
 	}
 
-	referencedBy := make(map[string][]string)
-	for entryID, entry := range entries {
-		for _, ref := range entry.Refs {
-			referencedBy[ref] = append(referencedBy[ref], entryID)
-		}
-	}
+	referencedBy := reverseRefs(entries)
 
 	visited := make(map[string]bool)
 	visited[id] = true
@@ -177,3 +174,18 @@ func (s *Store) Expand(id string, depth int) (*ExpandResult, error) {
 
 	return result, nil
 }
+
+// reverseRefs maps each referenced ID to the sorted IDs of the items that
+// reference it. Referenced IDs need not exist.
+func reverseRefs(entries map[string]*doryfile.MemoryEntry) map[string][]string {
+	referencedBy := make(map[string][]string)
+	for entryID, entry := range entries {
+		for _, ref := range entry.Refs {
+			referencedBy[ref] = append(referencedBy[ref], entryID)
+		}
+	}
+	for _, ids := range referencedBy {
+		sort.Strings(ids)
+	}
+	return referencedBy
+}
@@ -0,0 +1,162 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sibellavia/dory/internal/config"
+	"github.com/sibellavia/dory/internal/doryfile"
+)
+
+// minIDPrefix is the shortest prefix accepted in place of a full ID ("L-0").
+const minIDPrefix = 3
+
+// maxAmbiguousCandidates caps how many candidates an ambiguity error lists.
+const maxAmbiguousCandidates = 10
+
+// AmbiguousIDError reports an ID prefix that matches more than one item.
+type AmbiguousIDError struct {
+	Ref        string
+	Candidates []ListItem
+}
+
+func (e *AmbiguousIDError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "ambiguous ID %q matches %d items:", e.Ref, len(e.Candidates))
+	for i, item := range e.Candidates {
+		if i == maxAmbiguousCandidates {
+			fmt.Fprintf(&b, "\n  ... and %d more", len(e.Candidates)-i)
+			break
+		}
+		fmt.Fprintf(&b, "\n  %s  %s", item.ID, item.Oneliner)
+		if item.Origin != "" {
+			fmt.Fprintf(&b, "  (%s)", item.Origin)
+		}
+	}
+	return b.String()
+}
+
+// ResolveID expands a full ID, an alias (L-42), or a unique ID prefix
+// to the full item ID.
+func (s *Store) ResolveID(ref string) (string, error) {
+	if err := s.openLatest(); err != nil {
+		return "", err
+	}
+	return resolveID(s.df.Entries(), ref)
+}
+
+func resolveID(entries map[string]*doryfile.MemoryEntry, ref string) (string, error) {
+	exact, candidates := matchID(entries, ref)
+	if exact != "" {
+		return exact, nil
+	}
+	switch len(candidates) {
+	case 0:
+		return "", fmt.Errorf("item %s not found", ref)
+	case 1:
+		return candidates[0], nil
+	default:
+		err := &AmbiguousIDError{Ref: ref}
+		for _, id := range candidates {
+			err.Candidates = append(err.Candidates, toListItem(id, entries[id]))
+		}
+		return "", err
+	}
+}
+
+// matchID returns the exact or alias match for ref, or else every ID
+// starting with ref. Matching ignores case.
+func matchID(entries map[string]*doryfile.MemoryEntry, ref string) (string, []string) {
+	ref = strings.ToUpper(strings.TrimSpace(ref))
+	if _, ok := entries[ref]; ok {
+		return ref, nil
+	}
+	if _, _, ok := doryfile.ParseAlias(ref); ok {
+		for id, entry := range entries {
+			if entry.Alias == ref {
+				return id, nil
+			}
+		}
+		return "", nil
+	}
+	if len(ref) < minIDPrefix {
+		return "", nil
+	}
+
+	var candidates []string
+	for id := range entries {
+		if strings.HasPrefix(id, ref) {
+			candidates = append(candidates, id)
+		}
+	}
+	sort.Strings(candidates)
+	return "", candidates
+}
+
+// MissingRefsError reports refs that match no live item, task, or question.
+type MissingRefsError struct {
+	Refs []string
+}
+
+func (e *MissingRefsError) Error() string {
+	return fmt.Sprintf("refs not found: %s (not a live item, task, or question)", strings.Join(e.Refs, ", "))
+}
+
+// resolveRefs expands aliases and ID prefixes in refs. Ambiguous refs are an
+// error. Refs to session tasks and questions are kept. Other refs that match
+// no item fail with a MissingRefsError unless AllowMissingRefs is set or the
+// ref is in existing, the refs an entry already had.
+func (s *Store) resolveRefs(refs, existing []string) ([]string, error) {
+	if len(refs) == 0 {
+		return refs, nil
+	}
+	entries := s.df.Entries()
+	stateRefs := s.stateRefIDs()
+	resolved := make([]string, 0, len(refs))
+	var missing []string
+	for _, ref := range refs {
+		id, err := resolveID(entries, ref)
+		if err != nil {
+			if _, ambiguous := err.(*AmbiguousIDError); ambiguous {
+				return nil, err
+			}
+			id = ref
+			if upper := strings.ToUpper(ref); stateRefs[upper] {
+				id = upper
+			} else if !s.AllowMissingRefs && !contains(existing, ref) {
+				missing = append(missing, ref)
+			}
+		}
+		resolved = append(resolved, id)
+	}
+	if len(missing) > 0 {
+		return nil, &MissingRefsError{Refs: missing}
+	}
+	return resolved, nil
+}
+
+// appendNew writes a newly created entry, resolving its refs and assigning
+// an alias when the project enables them. Callers hold the write lock.
+func (s *Store) appendNew(entry *doryfile.Entry) error {
+	refs, err := s.resolveRefs(entry.Refs, nil)
+	if err != nil {
+		return err
+	}
+	entry.Refs = refs
+
+	if s.aliasesEnabled() {
+		if prefix, _, ok := strings.Cut(entry.ID, "-"); ok {
+			entry.Alias = s.df.NextAlias(prefix)
+		}
+	}
+	return s.df.Append(entry)
+}
+
+func (s *Store) aliasesEnabled() bool {
+	cfg, err := config.Load(s.Root)
+	if err != nil {
+		return false
+	}
+	return cfg.Aliases
+}
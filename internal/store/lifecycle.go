@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/sibellavia/dory/internal/doryfile"
 	"github.com/sibellavia/dory/internal/lock"
@@ -43,7 +44,42 @@ func (s *Store) Init(project, description string) error {
 	}
 	defer df.Close()
 
-	return nil
+	return ignoreLocalFiles(s.Root)
+}
+
+// localFiles are kept out of git even when the store itself is committed:
+// the caches are rebuilt from the log on demand and differ per checkout, and
+// the drafts queue holds raw transcript excerpts (command output, file
+// contents, possibly secrets) that were never reviewed.
+var localFiles = []string{searchIndexFile, vectorsFile, writeLockFile, draftsFile}
+
+// ignoreLocalFiles adds the local files to the store's .gitignore, creating
+// it if needed and leaving lines already there alone.
+func ignoreLocalFiles(root string) error {
+	path := filepath.Join(root, ".gitignore")
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	content := string(data)
+	present := make(map[string]bool)
+	for _, line := range strings.Split(content, "\n") {
+		present[strings.TrimSpace(line)] = true
+	}
+	var missing []string
+	for _, name := range localFiles {
+		if !present[name] && !present["/"+name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	content += strings.Join(missing, "\n") + "\n"
+	return os.WriteFile(path, []byte(content), 0644)
 }
 
 // open opens the dory storage if not already open.
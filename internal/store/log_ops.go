@@ -0,0 +1,198 @@
+package store
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sibellavia/dory/internal/doryfile"
+)
+
+// LogEntry is one event of the knowledge log.
+type LogEntry struct {
+	Seq      uint64 `json:"seq" yaml:"seq"`
+	Op       string `json:"op" yaml:"op"`
+	ID       string `json:"id,omitempty" yaml:"id,omitempty"`
+	Type     string `json:"type,omitempty" yaml:"type,omitempty"`
+	Oneliner string `json:"oneliner,omitempty" yaml:"oneliner,omitempty"`
+	Status   string `json:"status,omitempty" yaml:"status,omitempty"`
+	// Time is when the event was written. Events from logs written before
+	// event times were recorded have it inferred (see Log), and Inferred set.
+	Time     string `json:"time,omitempty" yaml:"time,omitempty"`
+	Inferred bool   `json:"time_inferred,omitempty" yaml:"time_inferred,omitempty"`
+}
+
+// LogFilter selects events for Log. Zero values match everything.
+type LogFilter struct {
+	Ops      []string
+	ID       string
+	Type     string
+	Since    time.Time
+	Until    time.Time
+	AfterSeq uint64
+}
+
+// logOpNames maps the short op names accepted by filters to log ops.
+var logOpNames = map[string]string{
+	"create":   doryfile.OpItemCreate,
+	"update":   doryfile.OpItemUpdate,
+	"delete":   doryfile.OpItemDelete,
+	"state":    doryfile.OpState,
+	"task":     doryfile.OpTask,
+	"question": doryfile.OpQuestion,
+	"compact":  doryfile.OpCompact,
+}
+
+// ParseLogOp expands a short op name (create, update, delete, state, task,
+// question, compact) or a full one (item.create) to the logged op.
+func ParseLogOp(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if op, ok := logOpNames[name]; ok {
+		return op, nil
+	}
+	for _, op := range logOpNames {
+		if name == op {
+			return op, nil
+		}
+	}
+	short := make([]string, 0, len(logOpNames))
+	for key := range logOpNames {
+		short = append(short, key)
+	}
+	sort.Strings(short)
+	return "", fmt.Errorf("unknown op %q (expected: %s)", name, strings.Join(short, ", "))
+}
+
+// Log returns the events matching filter, oldest first, and the seq of the
+// last event in the log. Seqs restart from 1 after compaction.
+//
+// Events carry the time they were written. Events from older logs, which do
+// not, are placed at the latest time known before them: their own creation
+// or state update time, or an earlier event's. Such times are marked
+// inferred.
+func (s *Store) Log(filter LogFilter) ([]LogEntry, uint64, error) {
+	if err := s.openLatest(); err != nil {
+		return nil, 0, err
+	}
+	ids, err := s.logIDs(filter.ID)
+	if err != nil {
+		return nil, 0, err
+	}
+	ops := make(map[string]bool, len(filter.Ops))
+	for _, op := range filter.Ops {
+		ops[op] = true
+	}
+
+	var entries []LogEntry
+	var head uint64
+	var at time.Time
+	last := make(map[string]*doryfile.Entry)
+	err = s.df.Events(func(ev doryfile.Event) bool {
+		head = ev.Seq
+		entry, recorded := logEntry(ev, last)
+		if !ev.At.IsZero() {
+			at = ev.At
+		} else if !recorded.IsZero() && recorded.After(at) {
+			at = recorded
+		}
+		if !at.IsZero() {
+			entry.Time = at.UTC().Format(time.RFC3339)
+			entry.Inferred = ev.At.IsZero()
+		}
+		if ev.Item != nil {
+			last[ev.ID] = ev.Item
+		}
+
+		switch {
+		case ev.Seq <= filter.AfterSeq:
+		case len(ops) > 0 && !ops[ev.Op]:
+		case ids != nil && !ids[strings.ToUpper(ev.ID)]:
+		case filter.Type != "" && entry.Type != filter.Type:
+		case !filter.Since.IsZero() && (at.IsZero() || at.Before(filter.Since)):
+		case !filter.Until.IsZero() && at.After(filter.Until):
+		default:
+			entries = append(entries, entry)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return entries, head, nil
+}
+
+// LogFile returns the file info of the log. Compaction replaces the file,
+// so comparing infos with os.SameFile tells an appended log from a
+// rewritten one.
+func (s *Store) LogFile() (os.FileInfo, error) {
+	return os.Stat(filepath.Join(s.Root, doryfile.KnowledgeFile))
+}
+
+// logEntry describes ev, using the last version of each item for deletes,
+// and returns the time its payload records, if any, for events that carry
+// no time of their own.
+func logEntry(ev doryfile.Event, last map[string]*doryfile.Entry) (LogEntry, time.Time) {
+	entry := LogEntry{Seq: ev.Seq, Op: ev.Op, ID: ev.ID}
+	var recorded time.Time
+	switch {
+	case ev.Item != nil:
+		entry.Type = ev.Item.Type
+		entry.Oneliner = ev.Item.Oneliner
+		// A create for an item seen before is a restore and keeps the
+		// original creation time.
+		if ev.Op == doryfile.OpItemCreate && last[ev.ID] == nil {
+			recorded = ev.Item.Created
+		}
+	case ev.Op == doryfile.OpItemDelete:
+		if prev := last[ev.ID]; prev != nil {
+			entry.Type = prev.Type
+			entry.Oneliner = prev.Oneliner
+		}
+	case ev.Task != nil:
+		entry.Type = "task"
+		entry.Oneliner = ev.Task.Text
+		entry.Status = ev.Task.Status
+	case ev.Question != nil:
+		entry.Type = "question"
+		entry.Oneliner = ev.Question.Text
+		entry.Status = ev.Question.Status
+	case ev.State != nil:
+		entry.Oneliner = ev.State.Goal
+		if t, err := time.Parse(time.RFC3339, ev.State.LastUpdated); err == nil {
+			recorded = t
+		}
+	}
+	return entry, recorded
+}
+
+// logIDs returns the IDs an ID filter selects, or nil for no filter. Live
+// items resolve like any ID; deleted items, tasks, and questions match by
+// exact ID or prefix.
+func (s *Store) logIDs(ref string) (map[string]bool, error) {
+	if ref == "" {
+		return nil, nil
+	}
+	id, err := resolveID(s.df.Entries(), ref)
+	if err == nil {
+		return map[string]bool{id: true}, nil
+	}
+	if _, ambiguous := err.(*AmbiguousIDError); ambiguous {
+		return nil, err
+	}
+
+	ref = strings.ToUpper(strings.TrimSpace(ref))
+	ids := map[string]bool{ref: true}
+	if len(ref) < minIDPrefix {
+		return ids, nil
+	}
+	err = s.df.Events(func(ev doryfile.Event) bool {
+		if strings.HasPrefix(strings.ToUpper(ev.ID), ref) {
+			ids[strings.ToUpper(ev.ID)] = true
+		}
+		return true
+	})
+	return ids, err
+}
@@ -0,0 +1,30 @@
+package store
+
+// SetPinned pins or unpins an item, returning its full ID and whether the
+// flag changed. Pinned items always appear in context and exports.
+func (s *Store) SetPinned(ref string, pinned bool) (string, bool, error) {
+	var id string
+	var changed bool
+	err := s.withWriteLock(func() error {
+		if err := s.open(); err != nil {
+			return err
+		}
+		resolved, err := resolveID(s.df.Entries(), ref)
+		if err != nil {
+			return err
+		}
+		id = resolved
+
+		entry, err := s.df.Get(id)
+		if err != nil {
+			return err
+		}
+		if entry.Pinned == pinned {
+			return nil
+		}
+		entry.Pinned = pinned
+		changed = true
+		return s.df.Append(entry)
+	})
+	return id, changed, err
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/sibellavia/dory/internal/doryfile"
 	"github.com/sibellavia/dory/internal/models"
+	"github.com/sibellavia/dory/internal/query"
 	"gopkg.in/yaml.v3"
 )
 
@@ -36,6 +37,12 @@ func (s *Store) Show(id string) (string, error) {
 	if len(entry.Refs) > 0 {
 		frontmatter["refs"] = entry.Refs
 	}
+	if entry.Alias != "" {
+		frontmatter["alias"] = entry.Alias
+	}
+	if entry.Pinned {
+		frontmatter["pinned"] = true
+	}
 
 	yamlData, err := yaml.Marshal(frontmatter)
 	if err != nil {
@@ -59,30 +66,58 @@ func (s *Store) GetEntry(id string) (*doryfile.Entry, error) {
 	return s.df.Get(id)
 }
 
-// List returns items matching the filters.
-func (s *Store) List(topic, itemType string, severity models.Severity, since, until time.Time) ([]ListItem, error) {
+// Project returns the project name and description from the index.
+func (s *Store) Project() (string, string, error) {
+	if err := s.openLatest(); err != nil {
+		return "", "", err
+	}
+	return s.df.Index.Project, s.df.Index.Description, nil
+}
+
+// ListFilter selects items for List. Zero values match everything.
+type ListFilter struct {
+	Topic    string
+	Type     string
+	Severity models.Severity
+	Since    time.Time
+	Until    time.Time
+	Query    query.Expr
+}
+
+// Match reports whether an entry passes every filter.
+func (f ListFilter) Match(id string, entry *doryfile.MemoryEntry) bool {
+	if f.Type != "" && entry.Type != f.Type {
+		return false
+	}
+	if f.Topic != "" && entry.Topic != f.Topic && entry.Domain != f.Topic {
+		return false
+	}
+	if f.Severity != "" && models.Severity(entry.Severity) != f.Severity {
+		return false
+	}
+	if !f.Since.IsZero() && entry.Created.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && entry.Created.After(f.Until) {
+		return false
+	}
+	if f.Query != nil && !f.Query.Match(toQueryItem(id, entry)) {
+		return false
+	}
+	return true
+}
+
+// List returns items matching the filter.
+func (s *Store) List(filter ListFilter) ([]ListItem, error) {
 	if err := s.openLatest(); err != nil {
 		return nil, err
 	}
 
 	items := make([]ListItem, 0)
 	for id, entry := range s.df.Entries() {
-		if itemType != "" && entry.Type != itemType {
-			continue
-		}
-		if topic != "" && entry.Topic != topic && entry.Domain != topic {
-			continue
-		}
-		if severity != "" && models.Severity(entry.Severity) != severity {
-			continue
-		}
-		if !since.IsZero() && entry.Created.Before(since) {
-			continue
-		}
-		if !until.IsZero() && entry.Created.After(until) {
+		if !filter.Match(id, entry) {
 			continue
 		}
-
 		items = append(items, toListItem(id, entry))
 	}
 
@@ -181,6 +216,20 @@ func (s *Store) DumpIndex() (string, error) {
 	return s.df.DumpIndex()
 }
 
+func toQueryItem(id string, entry *doryfile.MemoryEntry) query.Item {
+	return query.Item{
+		ID:       id,
+		Type:     entry.Type,
+		Topic:    entry.Topic,
+		Domain:   entry.Domain,
+		Severity: models.Severity(entry.Severity),
+		Oneliner: entry.Oneliner,
+		Created:  entry.Created,
+		Refs:     entry.Refs,
+		Pinned:   entry.Pinned,
+	}
+}
+
 func toListItem(id string, entry *doryfile.MemoryEntry) ListItem {
 	item := ListItem{
 		ID:        id,
@@ -198,5 +247,7 @@ func toListItem(id string, entry *doryfile.MemoryEntry) ListItem {
 	if entry.Severity != "" {
 		item.Severity = models.Severity(entry.Severity)
 	}
+	item.Alias = entry.Alias
+	item.Pinned = entry.Pinned
 	return item
 }
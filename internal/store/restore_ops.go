@@ -0,0 +1,87 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sibellavia/dory/internal/doryfile"
+)
+
+// Deleted returns the last version of every deleted item that is still in
+// the log. Compaction drops them for good.
+func (s *Store) Deleted() ([]ListItem, error) {
+	if err := s.openLatest(); err != nil {
+		return nil, err
+	}
+	versions, err := s.deletedVersions()
+	if err != nil {
+		return nil, err
+	}
+	items := make([]ListItem, 0, len(versions))
+	for _, entry := range versions {
+		items = append(items, entryListItem(entry))
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+	return items, nil
+}
+
+// Restore brings a deleted item back with its last content, returning its ID.
+// The ID may be any unique prefix of a deleted item's ID.
+func (s *Store) Restore(ref string) (string, error) {
+	var id string
+	err := s.withWriteLock(func() error {
+		if err := s.open(); err != nil {
+			return err
+		}
+		versions, err := s.deletedVersions()
+		if err != nil {
+			return err
+		}
+
+		var matches []string
+		for deletedID := range versions {
+			if strings.EqualFold(deletedID, ref) {
+				matches = []string{deletedID}
+				break
+			}
+			if strings.HasPrefix(strings.ToUpper(deletedID), strings.ToUpper(ref)) {
+				matches = append(matches, deletedID)
+			}
+		}
+		switch len(matches) {
+		case 0:
+			return fmt.Errorf("no deleted item matches %s", ref)
+		case 1:
+			id = matches[0]
+		default:
+			sort.Strings(matches)
+			candidates := make([]ListItem, 0, len(matches))
+			for _, match := range matches {
+				candidates = append(candidates, entryListItem(versions[match]))
+			}
+			return &AmbiguousIDError{Ref: ref, Candidates: candidates}
+		}
+		return s.df.Append(versions[id])
+	})
+	return id, err
+}
+
+// deletedVersions maps each deleted ID to its last version in the log.
+func (s *Store) deletedVersions() (map[string]*doryfile.Entry, error) {
+	deleted := make(map[string]bool, len(s.df.Index.Deleted))
+	for _, id := range s.df.Index.Deleted {
+		deleted[id] = true
+	}
+	versions := make(map[string]*doryfile.Entry)
+	if len(deleted) == 0 {
+		return versions, nil
+	}
+	err := s.df.Events(func(ev doryfile.Event) bool {
+		if ev.Item != nil && deleted[ev.ID] {
+			versions[ev.ID] = ev.Item
+		}
+		return true
+	})
+	return versions, err
+}
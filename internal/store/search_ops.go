@@ -0,0 +1,221 @@
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sibellavia/dory/internal/doryfile"
+	"github.com/sibellavia/dory/internal/fileio"
+	"github.com/sibellavia/dory/internal/models"
+	"github.com/sibellavia/dory/internal/search"
+)
+
+const (
+	searchIndexFile   = "search.idx"
+	searchIndexFormat = "dory-search-v2"
+	searchSnippetSize = 160
+)
+
+// SearchOptions filters and limits a full-text search.
+type SearchOptions struct {
+	Query    string
+	Type     string
+	Tag      string
+	Severity models.Severity
+	Limit    int
+}
+
+// SearchHit is a ranked full-text match. Highlights are the byte ranges of
+// Snippet that matched the query.
+type SearchHit struct {
+	ListItem   `yaml:",inline"`
+	Score      float64       `json:"score" yaml:"score"`
+	Snippet    string        `json:"snippet,omitempty" yaml:"snippet,omitempty"`
+	Highlights []search.Span `json:"highlights,omitempty" yaml:"highlights,omitempty"`
+}
+
+// searchIndexDisk is the on-disk search cache. LogSize and LogHash record
+// the log it was built from: item versions are log offsets, which only stay
+// valid while the log still starts with the same bytes (a checkout of
+// another branch can put different events at the same offsets).
+type searchIndexDisk struct {
+	Format  string        `json:"format"`
+	LogSize int64         `json:"log_size"`
+	LogHash string        `json:"log_hash"`
+	Index   *search.Index `json:"index"`
+}
+
+// Search runs a ranked full-text query over oneliners, tags, and bodies.
+func (s *Store) Search(opts SearchOptions) ([]SearchHit, error) {
+	q, err := search.ParseQuery(opts.Query)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.openLatest(); err != nil {
+		return nil, err
+	}
+
+	ix, err := s.syncSearchIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := s.df.Entries()
+	results := ix.Search(q, searchFilter(opts, entries))
+	if opts.Limit > 0 && len(results) > opts.Limit {
+		results = results[:opts.Limit]
+	}
+
+	hits := make([]SearchHit, 0, len(results))
+	for _, result := range results {
+		entry, err := s.df.Get(result.ID)
+		if err != nil {
+			return nil, err
+		}
+		snippet, highlights := search.Snippet(entry.Body, q, searchSnippetSize)
+		if len(highlights) == 0 {
+			snippet, highlights = search.Snippet(entry.Oneliner, q, searchSnippetSize)
+		}
+		hits = append(hits, SearchHit{
+			ListItem:   toListItem(result.ID, entries[result.ID]),
+			Score:      result.Score,
+			Snippet:    snippet,
+			Highlights: highlights,
+		})
+	}
+	return hits, nil
+}
+
+// searchFilter applies the type, tag, and severity options of a search.
+func searchFilter(opts SearchOptions, entries map[string]*doryfile.MemoryEntry) func(id string) bool {
+	return func(id string) bool {
+		entry, ok := entries[id]
+		if !ok {
+			return false
+		}
+		if opts.Type != "" && entry.Type != opts.Type {
+			return false
+		}
+		if opts.Tag != "" && entry.Topic != opts.Tag && entry.Domain != opts.Tag {
+			return false
+		}
+		if opts.Severity != "" && models.Severity(entry.Severity) != opts.Severity {
+			return false
+		}
+		return true
+	}
+}
+
+// syncSearchIndex loads the on-disk search index and brings it up to date
+// with the current heads. Item versions are keyed by their log offset, so
+// only created, updated, or deleted items are re-indexed. The whole index is
+// rebuilt when the log was rewritten rather than appended to.
+func (s *Store) syncSearchIndex() (*search.Index, error) {
+	path := filepath.Join(s.Root, searchIndexFile)
+	disk := loadSearchIndex(path)
+	prefixHash, logSize, logHash, err := hashLog(filepath.Join(s.Root, doryfile.KnowledgeFile), disk.LogSize)
+	if err != nil {
+		return nil, err
+	}
+	ix := disk.Index
+	changed := logSize != disk.LogSize
+	if prefixHash != disk.LogHash {
+		ix = search.NewIndex()
+		changed = true
+	}
+
+	entries := s.df.Entries()
+	for _, id := range ix.IDs() {
+		if _, ok := entries[id]; !ok {
+			ix.Remove(id)
+			changed = true
+		}
+	}
+	for id, mem := range entries {
+		if version, ok := ix.Version(id); ok && version == mem.Offset {
+			continue
+		}
+		entry, err := s.df.Get(id)
+		if err != nil {
+			return nil, err
+		}
+		ix.Add(id, mem.Offset, searchText(entry))
+		changed = true
+	}
+
+	if changed {
+		// The index is a cache; a failed write only costs a rebuild next time.
+		_ = saveSearchIndex(s.Root, &searchIndexDisk{LogSize: logSize, LogHash: logHash, Index: ix})
+	}
+	return ix, nil
+}
+
+// hashLog hashes the log at path, returning the hash of its first prefix
+// bytes (empty if the log is shorter) along with its size and full hash.
+func hashLog(path string, prefix int64) (string, int64, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	n, err := io.CopyN(h, f, prefix)
+	if err != nil && err != io.EOF {
+		return "", 0, "", err
+	}
+	var prefixHash string
+	if n == prefix {
+		prefixHash = hex.EncodeToString(h.Sum(nil))
+	}
+	rest, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, "", err
+	}
+	return prefixHash, n + rest, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func searchText(entry *doryfile.Entry) string {
+	return strings.Join([]string{entry.Oneliner, entry.Topic, entry.Domain, entry.Body}, "\n")
+}
+
+func loadSearchIndex(path string) *searchIndexDisk {
+	empty := &searchIndexDisk{Index: search.NewIndex()}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return empty
+	}
+	var disk searchIndexDisk
+	if err := json.Unmarshal(data, &disk); err != nil || disk.Format != searchIndexFormat || disk.Index == nil {
+		return empty
+	}
+	if disk.Index.Docs == nil || disk.Index.Postings == nil {
+		return empty
+	}
+	return &disk
+}
+
+func saveSearchIndex(root string, disk *searchIndexDisk) error {
+	disk.Format = searchIndexFormat
+	data, err := json.Marshal(disk)
+	if err != nil {
+		return err
+	}
+	if err := ignoreLocalFiles(root); err != nil {
+		return err
+	}
+	return fileio.WriteFileAtomic(filepath.Join(root, searchIndexFile), data, 0644)
+}
+
+// removeSearchIndex drops the search cache; compaction moves every item.
+func (s *Store) removeSearchIndex() error {
+	err := os.Remove(filepath.Join(s.Root, searchIndexFile))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package store
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/sibellavia/dory/internal/search"
+)
+
+// DefaultDuplicateThreshold is the similarity at or above which a new item
+// is reported as a likely duplicate of an existing one.
+const DefaultDuplicateThreshold = 0.5
+
+// placeholderLine matches the "(Add details here)" lines of default bodies.
+var placeholderLine = regexp.MustCompile(`^\(Add .* here\)$`)
+
+// templateHeadings are section headings of the default bodies.
+var templateHeadings = map[string]bool{
+	"details": true, "context": true, "decision": true,
+	"rationale": true, "convention": true, "implementation": true,
+}
+
+// SimilarItem is an existing item ranked by similarity.
+type SimilarItem struct {
+	ListItem `yaml:",inline"`
+	Score    float64 `json:"score" yaml:"score"`
+}
+
+// PendingItem is an item not yet written, such as an earlier entry of an
+// import batch, that new items are also compared against.
+type PendingItem struct {
+	Key      string
+	Type     string
+	Oneliner string
+	Body     string
+}
+
+// Similar returns the items most similar to free text.
+func (s *Store) Similar(text string, limit int) ([]SimilarItem, error) {
+	if err := s.openLatest(); err != nil {
+		return nil, err
+	}
+	return s.similar(text, "", limit)
+}
+
+// SimilarTo returns the items most similar to an existing item.
+func (s *Store) SimilarTo(ref string, limit int) ([]SimilarItem, error) {
+	if err := s.openLatest(); err != nil {
+		return nil, err
+	}
+	id, err := resolveID(s.df.Entries(), ref)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := s.df.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.similar(similarityText(entry.Oneliner, entry.Body), id, limit)
+}
+
+// FindDuplicates returns existing and pending items whose similarity to a
+// new item's oneliner and body reaches threshold. Pending items are reported
+// under their Key.
+func (s *Store) FindDuplicates(oneliner, body string, threshold float64, pending ...PendingItem) ([]SimilarItem, error) {
+	if err := s.openLatest(); err != nil {
+		return nil, err
+	}
+	items, err := s.similar(similarityText(oneliner, body), "", 0, pending...)
+	if err != nil {
+		return nil, err
+	}
+	duplicates := make([]SimilarItem, 0)
+	for _, item := range items {
+		if item.Score < threshold {
+			break
+		}
+		duplicates = append(duplicates, item)
+	}
+	return duplicates, nil
+}
+
+func (s *Store) similar(text, exclude string, limit int, pending ...PendingItem) ([]SimilarItem, error) {
+	corpus := search.NewCorpus()
+	entries := s.df.Entries()
+	for id := range entries {
+		entry, err := s.df.Get(id)
+		if err != nil {
+			return nil, err
+		}
+		corpus.Add(id, similarityText(entry.Oneliner, entry.Body))
+	}
+	queued := make(map[string]PendingItem, len(pending))
+	for _, item := range pending {
+		queued[item.Key] = item
+		corpus.Add(item.Key, similarityText(item.Oneliner, item.Body))
+	}
+
+	results := corpus.Similar(text, func(id string) bool { return id != exclude })
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+
+	items := make([]SimilarItem, 0, len(results))
+	for _, result := range results {
+		listItem := ListItem{ID: result.ID}
+		if item, ok := queued[result.ID]; ok {
+			listItem.Type = item.Type
+			listItem.Oneliner = item.Oneliner
+		} else {
+			listItem = toListItem(result.ID, entries[result.ID])
+		}
+		items = append(items, SimilarItem{ListItem: listItem, Score: result.Score})
+	}
+	return items, nil
+}
+
+// similarityText joins the oneliner and body, dropping the boilerplate of
+// default bodies so that items created without a body do not look alike.
+func similarityText(oneliner, body string) string {
+	return strings.Join(append([]string{oneliner}, bodyLines(oneliner, body)...), "\n")
+}
+
+// bodyLines returns the non-boilerplate lines of a body.
+func bodyLines(oneliner, body string) []string {
+	var lines []string
+	for _, line := range strings.Split(body, "\n") {
+		trimmed := strings.TrimSpace(line)
+		heading := strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+		switch {
+		case trimmed == "", trimmed == oneliner:
+		case placeholderLine.MatchString(trimmed):
+		case strings.HasPrefix(trimmed, "#") && (heading == oneliner || templateHeadings[strings.ToLower(heading)]):
+		default:
+			lines = append(lines, trimmed)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,248 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sibellavia/dory/internal/query"
+)
+
+// Layer is a store that participates in merged reads, labeled with its origin.
+type Layer struct {
+	Origin string
+	Store  *Store
+}
+
+// Stack merges reads across several stores, nearest first.
+// When an ID exists in more than one layer, the nearest layer wins.
+type Stack struct {
+	Layers []Layer
+}
+
+// NewStack creates a stack over the given layers, ordered nearest first.
+func NewStack(layers ...Layer) *Stack {
+	return &Stack{Layers: layers}
+}
+
+// Merged reports whether the stack spans more than one store.
+func (st *Stack) Merged() bool {
+	return len(st.Layers) > 1
+}
+
+// Close closes every store in the stack.
+func (st *Stack) Close() error {
+	var firstErr error
+	for _, layer := range st.Layers {
+		if err := layer.Store.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Locate returns the nearest layer holding the given item, plus its full ID.
+// The reference may be a full ID, an alias (L-42), or a unique ID prefix.
+// IDs may be qualified with a layer origin (e.g. "global:L-01JX...") to pick
+// a specific store when the same ID exists in more than one.
+func (st *Stack) Locate(ref string) (*Layer, string, error) {
+	origin, bare := SplitQualifiedID(ref)
+
+	var prefixLayers []*Layer
+	var prefixIDs []string
+	for i := range st.Layers {
+		layer := &st.Layers[i]
+		if origin != "" && layer.Origin != origin {
+			continue
+		}
+		if err := layer.Store.openLatest(); err != nil {
+			return nil, "", err
+		}
+		exact, candidates := matchID(layer.Store.df.Entries(), bare)
+		if exact != "" {
+			return layer, exact, nil
+		}
+		for _, id := range candidates {
+			prefixLayers = append(prefixLayers, layer)
+			prefixIDs = append(prefixIDs, id)
+		}
+	}
+
+	// The same ID found in several layers is one candidate; the nearest wins.
+	distinct := make(map[string]bool)
+	for _, id := range prefixIDs {
+		distinct[id] = true
+	}
+	switch len(distinct) {
+	case 0:
+		if origin != "" {
+			return nil, "", fmt.Errorf("item %s not found in %s", bare, origin)
+		}
+		return nil, "", fmt.Errorf("item %s not found", bare)
+	case 1:
+		return prefixLayers[0], prefixIDs[0], nil
+	}
+
+	err := &AmbiguousIDError{Ref: ref}
+	seen := make(map[string]bool)
+	for i, id := range prefixIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		item := toListItem(id, prefixLayers[i].Store.df.Entries()[id])
+		if st.Merged() {
+			item.Origin = prefixLayers[i].Origin
+		}
+		err.Candidates = append(err.Candidates, item)
+	}
+	return nil, "", err
+}
+
+// SplitQualifiedID splits an origin-qualified ID ("global:L-01JX...").
+// Unqualified IDs return an empty origin.
+func SplitQualifiedID(ref string) (string, string) {
+	if i := strings.LastIndex(ref, ":"); i > 0 {
+		return ref[:i], ref[i+1:]
+	}
+	return "", ref
+}
+
+// List returns items matching the filter across all layers.
+func (st *Stack) List(filter ListFilter) ([]ListItem, error) {
+	var lists [][]ListItem
+	for _, layer := range st.Layers {
+		items, err := layer.Store.List(filter)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", layer.Origin, err)
+		}
+		lists = append(lists, items)
+	}
+	return st.mergeItems(lists), nil
+}
+
+// Topics returns tag counts summed across all layers.
+func (st *Stack) Topics() ([]TopicInfo, error) {
+	items, err := st.List(ListFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int)
+	for _, item := range items {
+		if item.Topic != "" {
+			counts[item.Topic]++
+		} else if item.Domain != "" {
+			counts[item.Domain]++
+		}
+	}
+
+	topics := make([]TopicInfo, 0, len(counts))
+	for name, count := range counts {
+		topics = append(topics, TopicInfo{Name: name, Count: count})
+	}
+	sort.Slice(topics, func(i, j int) bool {
+		return topics[i].Name < topics[j].Name
+	})
+	return topics, nil
+}
+
+// Context returns session context with items merged across all layers.
+// Session state always comes from the nearest layer.
+func (st *Stack) Context(topic string, recentDays int, full bool, filter query.Expr) (*ContextResult, error) {
+	if len(st.Layers) == 0 {
+		return nil, fmt.Errorf("no stores to read")
+	}
+
+	var result *ContextResult
+	var pinned, critical, recent, topicItems [][]ListItem
+	for i, layer := range st.Layers {
+		ctx, err := layer.Store.Context(topic, recentDays, full, filter)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", layer.Origin, err)
+		}
+		if i == 0 {
+			result = ctx
+		}
+		pinned = append(pinned, ctx.Pinned)
+		critical = append(critical, ctx.Critical)
+		recent = append(recent, ctx.Recent)
+		topicItems = append(topicItems, ctx.Topic)
+	}
+
+	if merged := st.mergeItems(pinned); len(merged) > 0 {
+		result.Pinned = merged
+	}
+	result.Critical = st.mergeItems(critical)
+	result.Recent = st.mergeItems(recent)
+	if topic != "" {
+		result.Topic = st.mergeItems(topicItems)
+	}
+	return result, nil
+}
+
+// Search runs a full-text query against every layer and merges hits by score.
+func (st *Stack) Search(opts SearchOptions) ([]SearchHit, error) {
+	return st.mergeHits(opts.Limit, func(s *Store) ([]SearchHit, error) {
+		return s.Search(opts)
+	})
+}
+
+// SemanticSearch runs an embedding search against every layer and merges hits by score.
+func (st *Stack) SemanticSearch(opts SearchOptions, embedder Embedder) ([]SearchHit, error) {
+	return st.mergeHits(opts.Limit, func(s *Store) ([]SearchHit, error) {
+		return s.SemanticSearch(opts, embedder)
+	})
+}
+
+func (st *Stack) mergeHits(limit int, search func(s *Store) ([]SearchHit, error)) ([]SearchHit, error) {
+	seen := make(map[string]bool)
+	hits := make([]SearchHit, 0)
+	for _, layer := range st.Layers {
+		layerHits, err := search(layer.Store)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", layer.Origin, err)
+		}
+		for _, hit := range layerHits {
+			if seen[hit.ID] {
+				continue
+			}
+			seen[hit.ID] = true
+			if st.Merged() {
+				hit.Origin = layer.Origin
+			}
+			hits = append(hits, hit)
+		}
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].Score > hits[j].Score
+	})
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, nil
+}
+
+// mergeItems combines per-layer item lists, keeping the nearest copy of each ID.
+// Items are tagged with their origin only when the stack spans several stores.
+func (st *Stack) mergeItems(lists [][]ListItem) []ListItem {
+	seen := make(map[string]bool)
+	merged := make([]ListItem, 0)
+	for i, items := range lists {
+		for _, item := range items {
+			if seen[item.ID] {
+				continue
+			}
+			seen[item.ID] = true
+			if st.Merged() {
+				item.Origin = st.Layers[i].Origin
+			}
+			merged = append(merged, item)
+		}
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i].ID < merged[j].ID
+	})
+	return merged
+}
@@ -0,0 +1,78 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/sibellavia/dory/internal/models"
+)
+
+func TestStackMergesLayersNearestFirst(t *testing.T) {
+	base := t.TempDir()
+	rootStore := New(filepath.Join(base, ".dory"))
+	if err := rootStore.Init("mono", ""); err != nil {
+		t.Fatalf("init root: %v", err)
+	}
+	pkgStore := New(filepath.Join(base, "services", "api", ".dory"))
+	if err := pkgStore.Init("api", ""); err != nil {
+		t.Fatalf("init pkg: %v", err)
+	}
+
+	rootID, err := rootStore.Learn("root lesson", "ci", models.SeverityCritical, "", nil)
+	if err != nil {
+		t.Fatalf("learn root: %v", err)
+	}
+	pkgID, err := pkgStore.Learn("pkg lesson", "api", models.SeverityNormal, "", nil)
+	if err != nil {
+		t.Fatalf("learn pkg: %v", err)
+	}
+	if _, err := pkgStore.UpdateStatus("pkg goal", "", "", nil, nil, nil); err != nil {
+		t.Fatalf("update status: %v", err)
+	}
+
+	stack := NewStack(
+		Layer{Origin: "services/api", Store: pkgStore},
+		Layer{Origin: "root", Store: rootStore},
+	)
+	defer stack.Close()
+
+	items, err := stack.List(ListFilter{})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	origins := map[string]string{}
+	for _, item := range items {
+		origins[item.ID] = item.Origin
+	}
+	if len(items) != 2 || origins[rootID] != "root" || origins[pkgID] != "services/api" {
+		t.Fatalf("unexpected merged items: %+v", items)
+	}
+
+	ctx, err := stack.Context("", 7, false, nil)
+	if err != nil {
+		t.Fatalf("context: %v", err)
+	}
+	if ctx.Project != "api" || ctx.State == nil || ctx.State.Goal != "pkg goal" {
+		t.Fatalf("expected state from nearest store, got %+v", ctx)
+	}
+	if len(ctx.Critical) != 1 || ctx.Critical[0].ID != rootID {
+		t.Fatalf("expected critical lesson from root store, got %+v", ctx.Critical)
+	}
+
+	layer, id, err := stack.Locate(rootID)
+	if err != nil {
+		t.Fatalf("locate: %v", err)
+	}
+	if layer.Origin != "root" || id != rootID {
+		t.Fatalf("expected root layer, got %s", layer.Origin)
+	}
+	if _, _, err := stack.Locate("L-missing"); err == nil {
+		t.Fatal("expected missing item to fail")
+	}
+	if _, _, err := stack.Locate("services/api:" + rootID); err == nil {
+		t.Fatal("expected origin-qualified lookup to skip other layers")
+	}
+	if layer, id, err = stack.Locate("root:" + rootID); err != nil || layer.Origin != "root" || id != rootID {
+		t.Fatalf("expected qualified lookup to succeed, got %v %v", layer, err)
+	}
+}
@@ -1,13 +1,18 @@
 package store
 
 import (
+	"errors"
+	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/sibellavia/dory/internal/config"
 	"github.com/sibellavia/dory/internal/doryfile"
 	"github.com/sibellavia/dory/internal/models"
+	"github.com/sibellavia/dory/internal/query"
 )
 
 func TestStoreInitPersistsDescription(t *testing.T) {
@@ -73,7 +78,7 @@ func TestStoreDeleteThenCreateDoesNotReuseIDAcrossReopen(t *testing.T) {
 	s2 := New(root)
 	defer s2.Close()
 
-	items, err := s2.List("", "lesson", "", time.Time{}, time.Time{})
+	items, err := s2.List(ListFilter{Type: "lesson"})
 	if err != nil {
 		t.Fatalf("list: %v", err)
 	}
@@ -249,7 +254,7 @@ func TestStoreReadsSeeLatestWritesAcrossHandles(t *testing.T) {
 		t.Fatalf("learn first: %v", err)
 	}
 
-	items, err := reader.List("", "lesson", "", time.Time{}, time.Time{})
+	items, err := reader.List(ListFilter{Type: "lesson"})
 	if err != nil {
 		t.Fatalf("reader initial list: %v", err)
 	}
@@ -261,7 +266,7 @@ func TestStoreReadsSeeLatestWritesAcrossHandles(t *testing.T) {
 		t.Fatalf("learn second: %v", err)
 	}
 
-	items, err = reader.List("", "lesson", "", time.Time{}, time.Time{})
+	items, err = reader.List(ListFilter{Type: "lesson"})
 	if err != nil {
 		t.Fatalf("reader refreshed list: %v", err)
 	}
@@ -269,3 +274,1330 @@ func TestStoreReadsSeeLatestWritesAcrossHandles(t *testing.T) {
 		t.Fatalf("expected reader to see latest writes, got %d items", len(items))
 	}
 }
+
+func TestStoreTasksAndQuestions(t *testing.T) {
+	root := filepath.Join(t.TempDir(), ".dory")
+	s := New(root)
+	if err := s.Init("project", ""); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	defer s.Close()
+
+	if _, err := s.UpdateStatus("goal", "", "", []string{"first", "second"}, nil, []string{"which db?"}); err != nil {
+		t.Fatalf("update status: %v", err)
+	}
+	added, err := s.AddTask("third")
+	if err != nil {
+		t.Fatalf("add task: %v", err)
+	}
+	if added.ID != "T-3" {
+		t.Fatalf("expected T-3, got %s", added.ID)
+	}
+	if _, err := s.SetTaskStatus("T-1", "done"); err != nil {
+		t.Fatalf("set status: %v", err)
+	}
+	if _, err := s.SetTaskStatus("T-9", "done"); err == nil {
+		t.Fatal("expected unknown task to fail")
+	}
+
+	lessonID, err := s.Learn("postgres", "db", models.SeverityNormal, "", []string{"Q-1"})
+	if err != nil {
+		t.Fatalf("learn: %v", err)
+	}
+	if _, err := s.AnswerQuestion("Q-1", "", lessonID, false); err != nil {
+		t.Fatalf("answer: %v", err)
+	}
+	if _, err := s.AnswerQuestion("Q-1", "sqlite", "", false); err == nil {
+		t.Fatal("expected answering an answered question to fail")
+	}
+
+	s2 := New(root)
+	defer s2.Close()
+
+	tasks, err := s2.Tasks()
+	if err != nil {
+		t.Fatalf("tasks: %v", err)
+	}
+	if len(tasks) != 3 || tasks[0].Status != "done" || tasks[1].Status != "todo" || tasks[2].Text != "third" {
+		t.Fatalf("unexpected tasks: %+v", tasks)
+	}
+
+	questions, err := s2.Questions()
+	if err != nil {
+		t.Fatalf("questions: %v", err)
+	}
+	if len(questions) != 1 || questions[0].Status != "answered" || questions[0].AnsweredBy != lessonID {
+		t.Fatalf("unexpected questions: %+v", questions)
+	}
+}
+
+func TestStoreSearchKeepsIndexInSync(t *testing.T) {
+	root := filepath.Join(t.TempDir(), ".dory")
+	s := New(root)
+	if err := s.Init("project", ""); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	defer s.Close()
+
+	poolID, err := s.Learn("Pool exhaustion under load", "database", models.SeverityHigh, "Raise the connection pool size before load tests.", nil)
+	if err != nil {
+		t.Fatalf("learn: %v", err)
+	}
+	if _, err := s.Decide("Use structured logging", "observability", "grep is painful", "Logs are JSON lines.", nil); err != nil {
+		t.Fatalf("decide: %v", err)
+	}
+
+	hits, err := s.Search(SearchOptions{Query: `"connection pool"`})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if len(hits) != 1 || hits[0].ID != poolID {
+		t.Fatalf("expected only %s, got %+v", poolID, hits)
+	}
+	if h := hits[0].Highlights; len(h) != 1 || hits[0].Snippet[h[0].Start:h[0].End] != "connection pool" {
+		t.Fatalf("expected highlighted snippet, got %q %v", hits[0].Snippet, h)
+	}
+
+	// Markdown bold in a body is not a match; the oneliner is used instead.
+	boldID, err := s.Learn("Retry webhooks", "api", models.SeverityNormal, "Always **back off** first.", nil)
+	if err != nil {
+		t.Fatalf("learn: %v", err)
+	}
+	hits, err = s.Search(SearchOptions{Query: "webhooks"})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if len(hits) != 1 || hits[0].ID != boldID || hits[0].Snippet != "Retry webhooks" || len(hits[0].Highlights) != 1 {
+		t.Fatalf("expected the oneliner snippet for %s, got %+v", boldID, hits)
+	}
+
+	hits, err = s.Search(SearchOptions{Query: "log*", Type: "lesson"})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if len(hits) != 0 {
+		t.Fatalf("expected type filter to exclude decision, got %+v", hits)
+	}
+
+	if err := s.Remove(poolID); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	hits, err = s.Search(SearchOptions{Query: "pool"})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if len(hits) != 0 {
+		t.Fatalf("expected removed item to drop out of results, got %+v", hits)
+	}
+
+	if err := s.Compact(); err != nil {
+		t.Fatalf("compact: %v", err)
+	}
+	hits, err = s.Search(SearchOptions{Query: "structured"})
+	if err != nil {
+		t.Fatalf("search after compact: %v", err)
+	}
+	if len(hits) != 1 {
+		t.Fatalf("expected 1 hit after compact, got %+v", hits)
+	}
+}
+
+func TestStoreSearchRebuildsAfterBranchSwitch(t *testing.T) {
+	root := filepath.Join(t.TempDir(), ".dory")
+	s := New(root)
+	if err := s.Init("project", ""); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	defer s.Close()
+
+	id, err := s.Learn("Base lesson", "db", models.SeverityNormal, "", nil)
+	if err != nil {
+		t.Fatalf("learn: %v", err)
+	}
+	files := []string{doryfile.KnowledgeFile, doryfile.IndexFile}
+	base := make(map[string][]byte)
+	for _, name := range files {
+		data, err := os.ReadFile(filepath.Join(root, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		base[name] = data
+	}
+	edit := func(oneliner string) {
+		t.Helper()
+		entry, err := s.GetEntry(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entry.Oneliner = oneliner
+		if err := s.UpdateEntry(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	search := func(query string) []SearchHit {
+		t.Helper()
+		hits, err := s.Search(SearchOptions{Query: query})
+		if err != nil {
+			t.Fatalf("search: %v", err)
+		}
+		return hits
+	}
+
+	// Branch a edits the item, and the search cache records it.
+	edit("Alpha zebra")
+	if hits := search("zebra"); len(hits) != 1 {
+		t.Fatalf("expected a hit on branch a, got %+v", hits)
+	}
+	// Branch b starts from the same base and puts its edit at the same offset.
+	for name, data := range base {
+		if err := os.WriteFile(filepath.Join(root, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	edit("Bravo walrus")
+	if hits := search("walrus"); len(hits) != 1 {
+		t.Fatalf("expected the branch b edit to be found, got %+v", hits)
+	}
+	if hits := search("zebra"); len(hits) != 0 {
+		t.Fatalf("expected the branch a edit to be gone, got %+v", hits)
+	}
+
+	ignore, err := os.ReadFile(filepath.Join(root, ".gitignore"))
+	if err != nil || !strings.Contains(string(ignore), "search.idx\n") || !strings.Contains(string(ignore), "vectors.json\n") {
+		t.Fatalf("expected the caches to be gitignored, got %q (%v)", ignore, err)
+	}
+}
+
+func TestStoreListAppliesQuery(t *testing.T) {
+	root := filepath.Join(t.TempDir(), ".dory")
+	s := New(root)
+	if err := s.Init("project", ""); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	defer s.Close()
+
+	critical, err := s.Learn("Tokens expire silently", "auth", models.SeverityCritical, "", nil)
+	if err != nil {
+		t.Fatalf("learn: %v", err)
+	}
+	if _, err := s.Learn("Paginate endpoints", "api", models.SeverityLow, "", nil); err != nil {
+		t.Fatalf("learn: %v", err)
+	}
+	if _, err := s.Decide("Use OIDC", "auth", "", "", nil); err != nil {
+		t.Fatalf("decide: %v", err)
+	}
+
+	expr, err := query.Parse("type:lesson AND (tag:auth OR tag:api) AND severity>=high")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	items, err := s.List(ListFilter{Query: expr})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(items) != 1 || items[0].ID != critical {
+		t.Fatalf("expected only %s, got %+v", critical, items)
+	}
+}
+
+func TestStoreResolvesPrefixesAndAliases(t *testing.T) {
+	root := filepath.Join(t.TempDir(), ".dory")
+	s := New(root)
+	if err := s.Init("project", ""); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	defer s.Close()
+	if err := config.Save(root, &config.ProjectConfig{Aliases: true}); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+
+	first, err := s.Learn("first", "db", models.SeverityNormal, "", nil)
+	if err != nil {
+		t.Fatalf("learn: %v", err)
+	}
+	second, err := s.Learn("second", "db", models.SeverityNormal, "", []string{"L-1"})
+	if err != nil {
+		t.Fatalf("learn: %v", err)
+	}
+
+	if id, err := s.ResolveID("l-2"); err != nil || id != second {
+		t.Fatalf("expected alias to resolve to %s, got %s (%v)", second, id, err)
+	}
+	if id, err := s.ResolveID(first[:len(first)-2]); err != nil || id != first {
+		t.Fatalf("expected unique prefix to resolve to %s, got %s (%v)", first, id, err)
+	}
+	_, err = s.ResolveID("L-0")
+	var ambiguous *AmbiguousIDError
+	if !errors.As(err, &ambiguous) || len(ambiguous.Candidates) != 2 {
+		t.Fatalf("expected ambiguity listing both lessons, got %v", err)
+	}
+
+	entry, err := s.GetEntry(second)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if len(entry.Refs) != 1 || entry.Refs[0] != first {
+		t.Fatalf("expected alias ref to be stored as %s, got %v", first, entry.Refs)
+	}
+
+	if err := s.Remove(second); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if err := s.Compact(); err != nil {
+		t.Fatalf("compact: %v", err)
+	}
+	third, err := s.Learn("third", "db", models.SeverityNormal, "", nil)
+	if err != nil {
+		t.Fatalf("learn: %v", err)
+	}
+
+	items, err := New(root).List(ListFilter{})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	aliases := make(map[string]string)
+	for _, item := range items {
+		aliases[item.ID] = item.Alias
+	}
+	if aliases[first] != "L-1" || aliases[third] != "L-3" {
+		t.Fatalf("expected aliases to survive compaction without reuse, got %v", aliases)
+	}
+}
+
+func TestStoreFindDuplicatesIgnoresDefaultBodies(t *testing.T) {
+	root := filepath.Join(t.TempDir(), ".dory")
+	s := New(root)
+	if err := s.Init("project", ""); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	defer s.Close()
+
+	poolID, err := s.Learn("Connection pool exhausts under load", "db", models.SeverityHigh, "", nil)
+	if err != nil {
+		t.Fatalf("learn: %v", err)
+	}
+	if _, err := s.Learn("Tokens expire silently", "auth", models.SeverityNormal, "", nil); err != nil {
+		t.Fatalf("learn: %v", err)
+	}
+
+	duplicates, err := s.FindDuplicates("DB connection pool gets exhausted under heavy load", "", DefaultDuplicateThreshold)
+	if err != nil {
+		t.Fatalf("find duplicates: %v", err)
+	}
+	if len(duplicates) != 1 || duplicates[0].ID != poolID {
+		t.Fatalf("expected %s as the only duplicate, got %+v", poolID, duplicates)
+	}
+
+	// Default bodies share boilerplate; it must not make unrelated items match.
+	duplicates, err = s.FindDuplicates("Paginate list endpoints", "", DefaultDuplicateThreshold)
+	if err != nil {
+		t.Fatalf("find duplicates: %v", err)
+	}
+	if len(duplicates) != 0 {
+		t.Fatalf("expected no duplicates, got %+v", duplicates)
+	}
+
+	similar, err := s.SimilarTo(poolID, 5)
+	if err != nil {
+		t.Fatalf("similar to: %v", err)
+	}
+	for _, item := range similar {
+		if item.ID == poolID {
+			t.Fatalf("expected item to be excluded from its own results")
+		}
+	}
+}
+
+// letterEmbedder embeds text as letter counts and records how many texts it saw.
+func letterEmbedder(calls *int) Embedder {
+	return Embedder{
+		Name: "letters",
+		Embed: func(texts []string) ([][]float64, error) {
+			*calls += len(texts)
+			vectors := make([][]float64, 0, len(texts))
+			for _, text := range texts {
+				vector := make([]float64, 26)
+				for _, r := range strings.ToLower(text) {
+					if r >= 'a' && r <= 'z' {
+						vector[r-'a']++
+					}
+				}
+				vectors = append(vectors, vector)
+			}
+			return vectors, nil
+		},
+	}
+}
+
+func TestStoreSemanticSearchCachesVectors(t *testing.T) {
+	root := filepath.Join(t.TempDir(), ".dory")
+	s := New(root)
+	if err := s.Init("project", ""); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	defer s.Close()
+
+	zzz, err := s.Learn("zzz buzz", "misc", models.SeverityNormal, "", nil)
+	if err != nil {
+		t.Fatalf("learn: %v", err)
+	}
+	other, err := s.Learn("abc", "misc", models.SeverityNormal, "", nil)
+	if err != nil {
+		t.Fatalf("learn: %v", err)
+	}
+
+	calls := 0
+	embedder := letterEmbedder(&calls)
+	hits, err := s.SemanticSearch(SearchOptions{Query: "zz"}, embedder)
+	if err != nil {
+		t.Fatalf("semantic search: %v", err)
+	}
+	if len(hits) != 1 || hits[0].ID != zzz {
+		t.Fatalf("expected only %s, got %+v", zzz, hits)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 2 items + 1 query embedded, got %d", calls)
+	}
+
+	// Unchanged items are served from the cache, even after compaction.
+	if err := s.Remove(other); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if err := s.Compact(); err != nil {
+		t.Fatalf("compact: %v", err)
+	}
+	calls = 0
+	if _, err := s.SemanticSearch(SearchOptions{Query: "zz"}, embedder); err != nil {
+		t.Fatalf("semantic search: %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected only the query to be embedded, got %d", calls)
+	}
+	if cache := loadVectorCache(filepath.Join(root, vectorsFile)); len(cache.Vectors) != 1 {
+		t.Fatalf("expected removed item to be pruned, got %d vectors", len(cache.Vectors))
+	}
+
+	// Editing an item invalidates its vector.
+	entry, err := s.GetEntry(zzz)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	entry.Oneliner = "zzz fizz"
+	if err := s.UpdateEntry(entry); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	calls = 0
+	if _, err := s.SemanticSearch(SearchOptions{Query: "zz"}, embedder); err != nil {
+		t.Fatalf("semantic search: %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected the edited item and the query to be embedded, got %d", calls)
+	}
+}
+
+func TestStoreBudgetedContextRanksAndPacks(t *testing.T) {
+	root := filepath.Join(t.TempDir(), ".dory")
+	s := New(root)
+	if err := s.Init("project", ""); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	stack := NewStack(Layer{Origin: "project", Store: s})
+	defer stack.Close()
+
+	critical, err := s.Learn("Tokens expire silently", "auth", models.SeverityCritical, "Refresh five minutes early.", nil)
+	if err != nil {
+		t.Fatalf("learn: %v", err)
+	}
+	low, err := s.Learn("Paginate endpoints", "api", models.SeverityLow, "", nil)
+	if err != nil {
+		t.Fatalf("learn: %v", err)
+	}
+	decision, err := s.Decide("Use OIDC", "auth", "", "", nil)
+	if err != nil {
+		t.Fatalf("decide: %v", err)
+	}
+
+	opts := ContextBudget{Tokens: 40, Topic: "auth", Ranking: config.DefaultContextRanking()}
+	ctx, err := stack.BudgetedContext(opts)
+	if err != nil {
+		t.Fatalf("budgeted context: %v", err)
+	}
+	budget := ctx.Budget
+	if len(budget.Included) != 2 || budget.Included[0].ID != critical || budget.Included[1].ID != decision {
+		t.Fatalf("expected %s then %s, got %+v", critical, decision, budget.Included)
+	}
+	if len(budget.Omitted) != 1 || budget.Omitted[0].ID != low {
+		t.Fatalf("expected %s omitted, got %+v", low, budget.Omitted)
+	}
+	if budget.Used > budget.Tokens {
+		t.Fatalf("used %d of %d tokens", budget.Used, budget.Tokens)
+	}
+
+	opts.Tokens = 1000
+	opts.Bodies = true
+	ctx, err = stack.BudgetedContext(opts)
+	if err != nil {
+		t.Fatalf("budgeted context: %v", err)
+	}
+	if got := ctx.Budget.Included[0].Body; got != "Refresh five minutes early." {
+		t.Fatalf("expected body for %s, got %q", critical, got)
+	}
+	if len(ctx.Budget.Omitted) != 0 {
+		t.Fatalf("expected nothing omitted, got %+v", ctx.Budget.Omitted)
+	}
+
+	// A pinned item is reserved before ranking, even when it alone does not fit.
+	if _, _, err := s.SetPinned(low, true); err != nil {
+		t.Fatalf("pin: %v", err)
+	}
+	opts.Tokens = 1
+	opts.Bodies = false
+	ctx, err = stack.BudgetedContext(opts)
+	if err != nil {
+		t.Fatalf("budgeted context: %v", err)
+	}
+	budget = ctx.Budget
+	if len(budget.Pinned) != 1 || budget.Pinned[0].ID != low {
+		t.Fatalf("expected %s pinned, got %+v", low, budget.Pinned)
+	}
+	if len(budget.Included) != 0 || len(budget.Omitted) != 2 {
+		t.Fatalf("expected the ranked items left out, got %+v", budget)
+	}
+	for _, item := range budget.Omitted {
+		if item.ID == low {
+			t.Fatalf("pinned item omitted: %+v", budget.Omitted)
+		}
+	}
+}
+
+func TestStackChangeContextMatchesTagsAndAnchors(t *testing.T) {
+	root := filepath.Join(t.TempDir(), ".dory")
+	s := New(root)
+	if err := s.Init("project", ""); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	stack := NewStack(Layer{Origin: "project", Store: s})
+	defer stack.Close()
+
+	tagged, err := s.Learn("Tokens expire silently", "auth", models.SeverityCritical, "", nil)
+	if err != nil {
+		t.Fatalf("learn: %v", err)
+	}
+	anchored, err := s.Convention("Set cookie flags", "web", "See handlers/session.go for the setup.", nil)
+	if err != nil {
+		t.Fatalf("convention: %v", err)
+	}
+	if _, err := s.Learn("Paginate endpoints", "api", models.SeverityLow, "", nil); err != nil {
+		t.Fatalf("learn: %v", err)
+	}
+
+	changes, err := stack.ChangeContext([]string{"./internal/auth/token.go", "handlers/session.go", ".dory/index.yaml"}, nil)
+	if err != nil {
+		t.Fatalf("change context: %v", err)
+	}
+	if len(changes.Paths) != 2 {
+		t.Fatalf("expected store files to be dropped, got %v", changes.Paths)
+	}
+	if len(changes.Items) != 2 || changes.Items[0].ID != tagged || changes.Items[1].ID != anchored {
+		t.Fatalf("expected %s then %s, got %+v", tagged, anchored, changes.Items)
+	}
+	if got := changes.Items[1].Reasons; len(got) != 1 || got[0] != "mentions handlers/session.go" {
+		t.Fatalf("unexpected reasons %v", got)
+	}
+}
+
+func TestAnchorForMatchesWholePaths(t *testing.T) {
+	for _, tc := range []struct {
+		text, path, want string
+	}{
+		{"See handlers/session.go for the setup.", "handlers/session.go", "handlers/session.go"},
+		{"Fixed in `main.go`.", "cmd/main.go", "main.go"},
+		{"panic at cmd/main.go:42", "cmd/main.go", "cmd/main.go"},
+		{"Moved from internal/api/user.go", "api/user.go", "api/user.go"},
+		{"Read \"config.yaml\", then start.", "deploy/config.yaml", "config.yaml"},
+		{"The domain.go model", "cmd/main.go", ""},
+		{"Restore api/user.go.bak first", "api/user.go", ""},
+		{"See my_main.go and main.go-old", "main.go", ""},
+		{"Ignore domain.go but read main.go", "cmd/main.go", "main.go"},
+	} {
+		if got := anchorFor(tc.text, tc.path); got != tc.want {
+			t.Errorf("anchorFor(%q, %q) = %q, want %q", tc.text, tc.path, got, tc.want)
+		}
+	}
+}
+
+func TestStorePinnedItemsLeadContext(t *testing.T) {
+	root := filepath.Join(t.TempDir(), ".dory")
+	s := New(root)
+	if err := s.Init("project", ""); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	defer s.Close()
+
+	convention, err := s.Convention("Handlers return {data,error}", "api", "", nil)
+	if err != nil {
+		t.Fatalf("convention: %v", err)
+	}
+	critical, err := s.Learn("Tokens expire silently", "auth", models.SeverityCritical, "", nil)
+	if err != nil {
+		t.Fatalf("learn: %v", err)
+	}
+
+	if _, changed, err := s.SetPinned(convention[:6], true); err != nil || !changed {
+		t.Fatalf("pin: changed=%v err=%v", changed, err)
+	}
+	if _, changed, err := s.SetPinned(convention, true); err != nil || changed {
+		t.Fatalf("repin: changed=%v err=%v", changed, err)
+	}
+	if _, _, err := s.SetPinned(critical, true); err != nil {
+		t.Fatalf("pin: %v", err)
+	}
+	if err := s.Compact(); err != nil {
+		t.Fatalf("compact: %v", err)
+	}
+
+	reopened := New(root)
+	defer reopened.Close()
+	ctx, err := reopened.Context("", 0, false, nil)
+	if err != nil {
+		t.Fatalf("context: %v", err)
+	}
+	if len(ctx.Pinned) != 2 || ctx.Pinned[0].ID != critical || ctx.Pinned[1].ID != convention {
+		t.Fatalf("expected both items pinned, got %+v", ctx.Pinned)
+	}
+	if len(ctx.Critical) != 0 {
+		t.Fatalf("expected pinned lesson to leave the critical section, got %+v", ctx.Critical)
+	}
+
+	if _, _, err := reopened.SetPinned(critical, false); err != nil {
+		t.Fatalf("unpin: %v", err)
+	}
+	ctx, err = reopened.Context("", 0, false, nil)
+	if err != nil {
+		t.Fatalf("context: %v", err)
+	}
+	if len(ctx.Pinned) != 1 || len(ctx.Critical) != 1 || ctx.Critical[0].ID != critical {
+		t.Fatalf("expected unpinned lesson back in critical, got %+v", ctx)
+	}
+}
+
+func TestStoreAnalyzeGraphAndPath(t *testing.T) {
+	root := filepath.Join(t.TempDir(), ".dory")
+	s := New(root)
+	if err := s.Init("project", ""); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	defer s.Close()
+
+	a, err := s.Learn("A", "g", models.SeverityNormal, "", nil)
+	if err != nil {
+		t.Fatalf("learn: %v", err)
+	}
+	b, err := s.Learn("B", "g", models.SeverityNormal, "", []string{a})
+	if err != nil {
+		t.Fatalf("learn: %v", err)
+	}
+	gone, err := s.Learn("Gone", "g", models.SeverityNormal, "", nil)
+	if err != nil {
+		t.Fatalf("learn: %v", err)
+	}
+	question, err := s.AddQuestion("Which IdP?")
+	if err != nil {
+		t.Fatalf("add question: %v", err)
+	}
+	c, err := s.Decide("C", "g", "", "", []string{b, gone, question.ID})
+	if err != nil {
+		t.Fatalf("decide: %v", err)
+	}
+	orphan, err := s.Convention("Alone", "g", "", nil)
+	if err != nil {
+		t.Fatalf("convention: %v", err)
+	}
+	if err := s.Remove(gone); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	entry, err := s.GetEntry(a)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	entry.Refs = []string{c}
+	if err := s.UpdateEntry(entry); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	analysis, err := s.AnalyzeGraph(10)
+	if err != nil {
+		t.Fatalf("analyze: %v", err)
+	}
+	if len(analysis.Orphans) != 1 || analysis.Orphans[0].ID != orphan {
+		t.Fatalf("expected orphan %s, got %+v", orphan, analysis.Orphans)
+	}
+	if len(analysis.BrokenRefs) != 1 || analysis.BrokenRefs[0].Ref != gone || !analysis.BrokenRefs[0].Deleted {
+		t.Fatalf("expected one deleted ref to %s, got %+v", gone, analysis.BrokenRefs)
+	}
+	if len(analysis.Cycles) != 1 || len(analysis.Cycles[0]) != 4 {
+		t.Fatalf("expected one three-item cycle, got %v", analysis.Cycles)
+	}
+	if len(analysis.Hubs) != 3 {
+		t.Fatalf("expected three connected hubs, got %+v", analysis.Hubs)
+	}
+
+	path, err := s.Path(a, b, false)
+	if err != nil {
+		t.Fatalf("path: %v", err)
+	}
+	if len(path) != 2 || path[1].ID != b || path[1].Via != "referenced_by" {
+		t.Fatalf("expected a one-hop path via referenced_by, got %+v", path)
+	}
+	path, err = s.Path(a, b, true)
+	if err != nil {
+		t.Fatalf("directed path: %v", err)
+	}
+	if len(path) != 3 || path[1].ID != c {
+		t.Fatalf("expected directed path through %s, got %+v", c, path)
+	}
+	if _, err := s.Path(a, orphan, false); err == nil {
+		t.Fatal("expected no path to an orphan")
+	}
+}
+
+func TestStoreRefIntegrity(t *testing.T) {
+	root := filepath.Join(t.TempDir(), ".dory")
+	s := New(root)
+	if err := s.Init("project", ""); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	defer s.Close()
+
+	target, err := s.Learn("Target", "g", models.SeverityNormal, "", nil)
+	if err != nil {
+		t.Fatalf("learn: %v", err)
+	}
+	_, err = s.Learn("Bad", "g", models.SeverityNormal, "", []string{"L-missing"})
+	var missing *MissingRefsError
+	if !errors.As(err, &missing) || len(missing.Refs) != 1 {
+		t.Fatalf("expected unknown ref to be rejected, got %v", err)
+	}
+
+	question, err := s.AddQuestion("Which IdP?")
+	if err != nil {
+		t.Fatalf("add question: %v", err)
+	}
+	referrer, err := s.Learn("Referrer", "g", models.SeverityNormal, "", []string{target, strings.ToLower(question.ID)})
+	if err != nil {
+		t.Fatalf("learn with item and question refs: %v", err)
+	}
+
+	s.AllowMissingRefs = true
+	loose, err := s.Learn("Loose", "g", models.SeverityNormal, "", []string{"L-elsewhere"})
+	if err != nil {
+		t.Fatalf("learn with allowed missing ref: %v", err)
+	}
+	s.AllowMissingRefs = false
+
+	// Editing an item keeps refs it already had, even dangling ones.
+	entry, err := s.GetEntry(loose)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	entry.Severity = string(models.SeverityHigh)
+	if err := s.UpdateEntry(entry); err != nil {
+		t.Fatalf("update with existing dangling ref: %v", err)
+	}
+
+	referencing, err := s.ReferencedBy(target)
+	if err != nil {
+		t.Fatalf("referenced by: %v", err)
+	}
+	if len(referencing) != 1 || referencing[0].ID != referrer {
+		t.Fatalf("expected %s to reference %s, got %+v", referrer, target, referencing)
+	}
+
+	unlinked, err := s.RemoveUnlinking(target)
+	if err != nil {
+		t.Fatalf("remove unlinking: %v", err)
+	}
+	if len(unlinked) != 1 || unlinked[0] != referrer {
+		t.Fatalf("expected %s unlinked, got %v", referrer, unlinked)
+	}
+	entry, err = s.GetEntry(referrer)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if len(entry.Refs) != 1 || entry.Refs[0] != question.ID {
+		t.Fatalf("expected only the question ref to remain, got %v", entry.Refs)
+	}
+	reopened := New(root)
+	defer reopened.Close()
+	if _, err := reopened.GetEntry(target); err == nil {
+		t.Fatalf("expected %s to be deleted", target)
+	}
+	if entry, err := reopened.GetEntry(referrer); err != nil || len(entry.Refs) != 1 {
+		t.Fatalf("expected the unlink to persist, got %+v (%v)", entry, err)
+	}
+}
+
+func TestStoreBulkUpdateAndRenameTag(t *testing.T) {
+	root := filepath.Join(t.TempDir(), ".dory")
+	s := New(root)
+	if err := s.Init("project", ""); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	defer s.Close()
+
+	first, err := s.Learn("Token expiry", "auth", models.SeverityNormal, "", nil)
+	if err != nil {
+		t.Fatalf("learn: %v", err)
+	}
+	second, err := s.Learn("Refresh race", "Auth", models.SeverityLow, "", nil)
+	if err != nil {
+		t.Fatalf("learn: %v", err)
+	}
+	decision, err := s.Decide("Use OIDC", "auth", "", "", nil)
+	if err != nil {
+		t.Fatalf("decide: %v", err)
+	}
+	convention, err := s.Convention("Wrap errors", "auth", "", nil)
+	if err != nil {
+		t.Fatalf("convention: %v", err)
+	}
+	other, err := s.Learn("Pool size", "db", models.SeverityNormal, "", nil)
+	if err != nil {
+		t.Fatalf("learn: %v", err)
+	}
+
+	filter, err := query.Parse("tag:auth")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	edit := BulkEdit{Severity: string(models.SeverityHigh), AddRefs: []string{decision}}
+
+	preview, err := s.BulkUpdate(filter, edit, true)
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if len(preview.Matched) != 4 || len(preview.Changed) != 3 {
+		t.Fatalf("expected 4 matched and 3 changed, got %+v", preview)
+	}
+	if entry, _ := s.GetEntry(first); entry.Severity != string(models.SeverityNormal) {
+		t.Fatalf("dry run wrote changes: %+v", entry)
+	}
+
+	if _, err := s.BulkUpdate(filter, BulkEdit{AddRefs: []string{"L-missing"}}, false); err == nil {
+		t.Fatal("expected a missing ref to reject the whole batch")
+	}
+
+	if _, err := s.BulkUpdate(filter, edit, false); err != nil {
+		t.Fatalf("bulk update: %v", err)
+	}
+	for _, id := range []string{first, second} {
+		entry, _ := s.GetEntry(id)
+		if entry.Severity != string(models.SeverityHigh) || !contains(entry.Refs, decision) {
+			t.Fatalf("expected %s to be updated, got %+v", id, entry)
+		}
+	}
+	if entry, _ := s.GetEntry(decision); entry.Severity != "" || len(entry.Refs) != 0 {
+		t.Fatalf("decision should get no severity and no self ref, got %+v", entry)
+	}
+	if entry, _ := s.GetEntry(other); entry.Severity != string(models.SeverityNormal) {
+		t.Fatalf("unmatched item changed: %+v", entry)
+	}
+
+	renamed, err := s.RenameTag("auth", "authentication", false)
+	if err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+	if len(renamed.Changed) != 4 {
+		t.Fatalf("expected 4 renamed items, got %v", renamed.Changed)
+	}
+	if entry, _ := s.GetEntry(convention); entry.Domain != "authentication" || entry.Topic != "" {
+		t.Fatalf("convention tag should stay in its domain field, got %+v", entry)
+	}
+	topics, err := s.Topics()
+	if err != nil {
+		t.Fatalf("topics: %v", err)
+	}
+	for _, topic := range topics {
+		if strings.EqualFold(topic.Name, "auth") {
+			t.Fatalf("old tag still present: %+v", topics)
+		}
+	}
+
+	odd, err := s.Learn("Odd tag", `c\"quoted"*`, models.SeverityNormal, "", nil)
+	if err != nil {
+		t.Fatalf("learn odd tag: %v", err)
+	}
+	renamed, err = s.RenameTag(`c\"quoted"*`, "quoted", false)
+	if err != nil {
+		t.Fatalf("rename odd tag: %v", err)
+	}
+	if len(renamed.Changed) != 1 || renamed.Changed[0] != odd {
+		t.Fatalf("expected only %s renamed, got %v", odd, renamed.Changed)
+	}
+}
+
+func TestStoreDiffSnapshots(t *testing.T) {
+	root := filepath.Join(t.TempDir(), ".dory")
+	s := New(root)
+	if err := s.Init("project", ""); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	defer s.Close()
+
+	kept, err := s.Learn("Kept", "api", models.SeverityNormal, "line one\nline two\n", nil)
+	if err != nil {
+		t.Fatalf("learn: %v", err)
+	}
+	removed, err := s.Learn("Removed", "api", models.SeverityNormal, "", nil)
+	if err != nil {
+		t.Fatalf("learn: %v", err)
+	}
+	before, err := LoadSnapshot(root, 0)
+	if err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+
+	entry, err := s.GetEntry(kept)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	entry.Severity = string(models.SeverityHigh)
+	entry.Body = "line one\nline 2\n"
+	if err := s.UpdateEntry(entry); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if err := s.Remove(removed); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	added, err := s.Decide("Added", "api", "", "", nil)
+	if err != nil {
+		t.Fatalf("decide: %v", err)
+	}
+	if _, err := s.UpdateStatus("Ship it", "", "", nil, nil, nil); err != nil {
+		t.Fatalf("update status: %v", err)
+	}
+
+	after, err := LoadSnapshot(root, 0)
+	if err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	diff := Diff(before, after)
+	if len(diff.Added) != 1 || diff.Added[0].ID != added {
+		t.Fatalf("expected %s added, got %+v", added, diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].ID != removed {
+		t.Fatalf("expected %s removed, got %+v", removed, diff.Removed)
+	}
+	if len(diff.Modified) != 1 || diff.Modified[0].ID != kept {
+		t.Fatalf("expected %s modified, got %+v", kept, diff.Modified)
+	}
+	modified := diff.Modified[0]
+	if len(modified.Fields) != 1 || modified.Fields[0] != (FieldChange{Field: "severity", From: "normal", To: "high"}) {
+		t.Fatalf("unexpected field changes: %+v", modified.Fields)
+	}
+	if got := strings.Join(modified.Body, "|"); got != " line one|-line two|+line 2" {
+		t.Fatalf("unexpected body diff: %q", got)
+	}
+	if len(diff.State) != 1 || diff.State[0].Field != "goal" {
+		t.Fatalf("expected a goal change, got %+v", diff.State)
+	}
+
+	// The log replayed to an earlier seq matches the earlier snapshot.
+	atSeq, err := LoadSnapshot(root, before.Seq)
+	if err != nil {
+		t.Fatalf("snapshot at seq: %v", err)
+	}
+	if !Diff(before, atSeq).Empty() {
+		t.Fatalf("snapshot at seq %d differs: %+v", before.Seq, Diff(before, atSeq))
+	}
+	if _, err := LoadSnapshot(root, after.Seq+1); err == nil {
+		t.Fatal("expected a seq past the end of the log to fail")
+	}
+}
+
+func TestStoreRestoreDeletedItem(t *testing.T) {
+	root := filepath.Join(t.TempDir(), ".dory")
+	s := New(root)
+	if err := s.Init("project", ""); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	defer s.Close()
+
+	id, err := s.Learn("Gone for now", "api", models.SeverityHigh, "body text\n", nil)
+	if err != nil {
+		t.Fatalf("learn: %v", err)
+	}
+	if err := s.Remove(id); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	deleted, err := s.Deleted()
+	if err != nil {
+		t.Fatalf("deleted: %v", err)
+	}
+	if len(deleted) != 1 || deleted[0].ID != id || deleted[0].Oneliner != "Gone for now" {
+		t.Fatalf("expected %s listed as deleted, got %+v", id, deleted)
+	}
+
+	restored, err := s.Restore(id[:6])
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if restored != id {
+		t.Fatalf("expected %s restored, got %s", id, restored)
+	}
+	entry, err := s.GetEntry(id)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if entry.Body != "body text\n" || entry.Severity != string(models.SeverityHigh) {
+		t.Fatalf("restored item lost content: %+v", entry)
+	}
+	if deleted, _ := s.Deleted(); len(deleted) != 0 {
+		t.Fatalf("expected no deleted items after restore, got %+v", deleted)
+	}
+	if _, err := s.Restore(id); err == nil {
+		t.Fatal("expected restoring a live item to fail")
+	}
+}
+
+func TestStoreLog(t *testing.T) {
+	root := filepath.Join(t.TempDir(), ".dory")
+	s := New(root)
+	if err := s.Init("project", ""); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	defer s.Close()
+
+	lesson, err := s.Learn("Logged lesson", "api", models.SeverityNormal, "", nil)
+	if err != nil {
+		t.Fatalf("learn: %v", err)
+	}
+	decision, err := s.Decide("Logged decision", "db", "", "", nil)
+	if err != nil {
+		t.Fatalf("decide: %v", err)
+	}
+	if err := s.Remove(lesson); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if _, err := s.AddTask("write docs"); err != nil {
+		t.Fatalf("add task: %v", err)
+	}
+
+	all, head, err := s.Log(LogFilter{})
+	if err != nil {
+		t.Fatalf("log: %v", err)
+	}
+	if len(all) != 4 || head != all[3].Seq {
+		t.Fatalf("expected 4 events ending at head %d, got %+v", head, all)
+	}
+	if all[0].Op != "item.create" || all[0].ID != lesson {
+		t.Fatalf("expected create first, got %+v", all[0])
+	}
+	if all[2].Op != "item.delete" || all[2].Oneliner != "Logged lesson" || all[2].Type != "lesson" {
+		t.Fatalf("expected delete described by the last version, got %+v", all[2])
+	}
+	for _, entry := range all {
+		if entry.Time == "" || entry.Inferred {
+			t.Fatalf("expected every event to record its time, got %+v", entry)
+		}
+	}
+	if all[3].ID != "T-1" || all[3].Type != "task" || all[3].Status != "todo" {
+		t.Fatalf("expected task event, got %+v", all[3])
+	}
+
+	deleteOp, err := ParseLogOp("delete")
+	if err != nil {
+		t.Fatalf("parse op: %v", err)
+	}
+	deletes, _, err := s.Log(LogFilter{Ops: []string{deleteOp}})
+	if err != nil || len(deletes) != 1 || deletes[0].ID != lesson {
+		t.Fatalf("expected one delete, got %+v (%v)", deletes, err)
+	}
+	history, _, err := s.Log(LogFilter{ID: lesson[:8]})
+	if err != nil || len(history) != 2 {
+		t.Fatalf("expected deleted item history by prefix, got %+v (%v)", history, err)
+	}
+	decisions, _, err := s.Log(LogFilter{Type: "decision"})
+	if err != nil || len(decisions) != 1 || decisions[0].ID != decision {
+		t.Fatalf("expected decision events, got %+v (%v)", decisions, err)
+	}
+	newer, _, err := s.Log(LogFilter{AfterSeq: all[1].Seq})
+	if err != nil || len(newer) != 2 {
+		t.Fatalf("expected events after seq %d, got %+v (%v)", all[1].Seq, newer, err)
+	}
+	future, _, err := s.Log(LogFilter{Since: time.Now().Add(time.Hour)})
+	if err != nil || len(future) != 0 {
+		t.Fatalf("expected no events in the future, got %+v (%v)", future, err)
+	}
+	if _, err := ParseLogOp("bogus"); err == nil {
+		t.Fatal("expected unknown op to fail")
+	}
+
+	// An update or delete is found by --since from its own time, not the
+	// last create before it.
+	if err := s.Remove(decision); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	since, err := time.Parse(time.RFC3339, all[3].Time)
+	if err != nil {
+		t.Fatalf("parse time: %v", err)
+	}
+	recent, _, err := s.Log(LogFilter{Ops: []string{deleteOp}, Since: since})
+	if err != nil || len(recent) != 2 {
+		t.Fatalf("expected both deletes since %s, got %+v (%v)", since, recent, err)
+	}
+
+	// Compaction ends the rewritten log with a marker, so followers can
+	// resume after it.
+	if err := s.Compact(); err != nil {
+		t.Fatalf("compact: %v", err)
+	}
+	compacted, head, err := s.Log(LogFilter{})
+	if err != nil || len(compacted) == 0 {
+		t.Fatalf("log after compact: %+v (%v)", compacted, err)
+	}
+	if last := compacted[len(compacted)-1]; last.Op != "compact" || last.Seq != head {
+		t.Fatalf("expected a compact marker at the end, got %+v", compacted)
+	}
+}
+
+func TestStoreLogInfersLegacyTimes(t *testing.T) {
+	root := filepath.Join(t.TempDir(), ".dory")
+	s := New(root)
+	if err := s.Init("project", ""); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	lesson, err := s.Learn("Legacy lesson", "api", models.SeverityNormal, "", nil)
+	if err != nil {
+		t.Fatalf("learn: %v", err)
+	}
+	if err := s.Remove(lesson); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	s.Close()
+
+	// Rewrite the log as older versions wrote it, without event times.
+	path := filepath.Join(root, "knowledge.dory")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if !strings.HasPrefix(line, "at: ") {
+			lines = append(lines, line)
+		}
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// An index with no heads makes the store replay the log.
+	if err := os.WriteFile(filepath.Join(root, "index.yaml"), []byte("format: doryfile-v1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	legacy := New(root)
+	defer legacy.Close()
+	entries, _, err := legacy.Log(LogFilter{})
+	if err != nil {
+		t.Fatalf("log: %v", err)
+	}
+	if len(entries) != 2 || entries[1].Op != "item.delete" {
+		t.Fatalf("expected create and delete, got %+v", entries)
+	}
+	for _, entry := range entries {
+		if entry.Time != entries[0].Time || !entry.Inferred {
+			t.Fatalf("expected the delete to take the create's time, inferred, got %+v", entries)
+		}
+	}
+}
+
+func TestStoreDumpAndLoad(t *testing.T) {
+	root := filepath.Join(t.TempDir(), ".dory")
+	s := New(root)
+	if err := s.Init("project", "desc"); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	defer s.Close()
+	if err := config.Save(root, &config.ProjectConfig{Aliases: true}); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+
+	first, err := s.Learn("First", "api", models.SeverityHigh, "body\n", nil)
+	if err != nil {
+		t.Fatalf("learn: %v", err)
+	}
+	gone, err := s.Learn("Gone", "api", models.SeverityNormal, "", nil)
+	if err != nil {
+		t.Fatalf("learn: %v", err)
+	}
+	second, err := s.Decide("Second", "db", "", "", []string{first})
+	if err != nil {
+		t.Fatalf("decide: %v", err)
+	}
+	if err := s.Remove(gone); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if _, err := s.UpdateStatus("ship it", "", "", []string{"write docs"}, nil, nil); err != nil {
+		t.Fatalf("update status: %v", err)
+	}
+
+	dump := func(st *Store, history bool) []DumpRecord {
+		var records []DumpRecord
+		if err := st.Dump(history, func(record *DumpRecord) error {
+			records = append(records, *record)
+			return nil
+		}); err != nil {
+			t.Fatalf("dump: %v", err)
+		}
+		return records
+	}
+
+	snapshot := dump(s, false)
+	if len(snapshot) != 4 || snapshot[0].Kind != DumpKindHeader || snapshot[3].Kind != DumpKindState {
+		t.Fatalf("expected header, two items, and state, got %+v", snapshot)
+	}
+	snapRoot := filepath.Join(t.TempDir(), ".dory")
+	result, err := Load(snapRoot, snapshot)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if result.Items != 2 || result.Project != "project" {
+		t.Fatalf("unexpected load result %+v", result)
+	}
+	loaded := New(snapRoot)
+	defer loaded.Close()
+	entry, err := loaded.GetEntry(second)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if entry.Alias != "D-1" || len(entry.Refs) != 1 || entry.Refs[0] != first {
+		t.Fatalf("expected alias and refs kept, got %+v", entry)
+	}
+	if got, _ := loaded.GetEntry(first); got.Body != "body\n" || got.Severity != "high" {
+		t.Fatalf("expected body and severity kept, got %+v", got)
+	}
+	next, err := loaded.Learn("Next", "api", models.SeverityNormal, "", nil)
+	if err != nil {
+		t.Fatalf("learn: %v", err)
+	}
+	if e, _ := loaded.GetEntry(next); e.Alias != "L-3" {
+		t.Fatalf("expected alias counter past the deleted item, got %q", e.Alias)
+	}
+	if _, err := Load(snapRoot, snapshot); err == nil {
+		t.Fatal("expected loading into a non-empty store to fail")
+	}
+
+	history := dump(s, true)
+	histRoot := filepath.Join(t.TempDir(), ".dory")
+	if _, err := Load(histRoot, history); err != nil {
+		t.Fatalf("load history: %v", err)
+	}
+	replayed := New(histRoot)
+	defer replayed.Close()
+	deleted, err := replayed.Deleted()
+	if err != nil || len(deleted) != 1 || deleted[0].ID != gone {
+		t.Fatalf("expected deleted item kept in history, got %+v (%v)", deleted, err)
+	}
+	if got := dump(replayed, true); len(got) != len(history) {
+		t.Fatalf("expected %d records, got %d", len(history), len(got))
+	}
+
+	history[2].Seq = 9
+	if _, err := Load(filepath.Join(t.TempDir(), ".dory"), history); err == nil {
+		t.Fatal("expected out-of-order events to fail")
+	}
+}
+
+func TestStoreDraftQueue(t *testing.T) {
+	root := filepath.Join(t.TempDir(), ".dory")
+	s := New(root)
+	if err := s.Init("project", ""); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	defer s.Close()
+	// Stores created before drafts existed do not ignore them yet.
+	if err := os.Remove(filepath.Join(root, ".gitignore")); err != nil {
+		t.Fatal(err)
+	}
+
+	drafts := []Draft{
+		{Signal: "retry", Oneliner: "go test: undefined: lex", Source: "/s.jsonl", Line: 4, Severity: "normal"},
+		{Signal: "note", Oneliner: "Link libssl explicitly", Source: "/s.jsonl", Line: 9, Severity: "normal"},
+	}
+	added, err := s.AddDrafts(drafts)
+	if err != nil {
+		t.Fatalf("add drafts: %v", err)
+	}
+	if len(added) != 2 || added[0].ID != "DR-1" || added[1].Status != DraftPending {
+		t.Fatalf("unexpected drafts: %+v", added)
+	}
+	// Drafts quote transcripts verbatim, so they stay private and out of git.
+	ignore, err := os.ReadFile(filepath.Join(root, ".gitignore"))
+	if err != nil || !strings.Contains(string(ignore), "drafts.yaml\n") {
+		t.Fatalf("expected drafts.yaml to be gitignored, got %q (%v)", ignore, err)
+	}
+	info, err := os.Stat(filepath.Join(root, draftsFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Fatalf("expected drafts.yaml to be private, got %v", info.Mode())
+	}
+	if _, err := s.ResolveDraft("DR-2", DraftRejected, ""); err != nil {
+		t.Fatalf("reject: %v", err)
+	}
+	if _, err := s.ResolveDraft("DR-2", DraftAccepted, "L-1"); err == nil {
+		t.Fatal("expected a rejected draft to stay rejected")
+	}
+
+	// Harvesting the same transcript again only adds new drafts.
+	more := append(drafts, Draft{Signal: "revert", Oneliner: "Undo the retry loop", Source: "/s.jsonl", Line: 12})
+	added, err = s.AddDrafts(more)
+	if err != nil {
+		t.Fatalf("add drafts again: %v", err)
+	}
+	if len(added) != 1 || added[0].ID != "DR-3" {
+		t.Fatalf("expected only the new draft, got %+v", added)
+	}
+
+	all, err := New(root).Drafts()
+	if err != nil {
+		t.Fatalf("drafts: %v", err)
+	}
+	if len(all) != 3 || all[1].Status != DraftRejected {
+		t.Fatalf("unexpected queue: %+v", all)
+	}
+	if entries, _ := s.List(ListFilter{}); len(entries) != 0 {
+		t.Fatalf("expected drafts to stay out of the log, got %+v", entries)
+	}
+
+	// Concurrent accepts of one draft create a single lesson.
+	var wg sync.WaitGroup
+	ids := make([]string, 4)
+	for i := range ids {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			other := New(root)
+			defer other.Close()
+			ids[i], _ = other.AcceptDraft("dr-1", "go test: undefined: lex", "go", models.SeverityNormal, "")
+		}(i)
+	}
+	wg.Wait()
+	var accepted []string
+	for _, id := range ids {
+		if id != "" {
+			accepted = append(accepted, id)
+		}
+	}
+	if len(accepted) != 1 {
+		t.Fatalf("expected exactly one accept to succeed, got %v", ids)
+	}
+	if entries, _ := s.List(ListFilter{}); len(entries) != 1 || entries[0].ID != accepted[0] {
+		t.Fatalf("expected one lesson, got %+v", entries)
+	}
+	if draft, err := s.Draft("DR-1"); err != nil || draft.Status != DraftAccepted || draft.ItemID != accepted[0] {
+		t.Fatalf("expected DR-1 accepted as %s, got %+v (%v)", accepted[0], draft, err)
+	}
+}
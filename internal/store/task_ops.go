@@ -0,0 +1,197 @@
+package store
+
+import (
+	"fmt"
+
+	"github.com/sibellavia/dory/internal/doryfile"
+)
+
+// Tasks returns the session next-step tasks.
+func (s *Store) Tasks() ([]Task, error) {
+	if err := s.openLatest(); err != nil {
+		return nil, err
+	}
+	return toTasks(s.df.Index.State.Next), nil
+}
+
+// AddTask appends a new todo task to the session state.
+func (s *Store) AddTask(text string) (*Task, error) {
+	if text == "" {
+		return nil, fmt.Errorf("task text is required")
+	}
+
+	var result *Task
+	err := s.withWriteLock(func() error {
+		if err := s.open(); err != nil {
+			return err
+		}
+
+		task := &doryfile.Task{
+			ID:     s.df.NextTaskID(),
+			Text:   text,
+			Status: doryfile.TaskTodo,
+		}
+		if err := s.df.AppendTask(task); err != nil {
+			return err
+		}
+		converted := toTask(*task)
+		result = &converted
+		return nil
+	})
+	return result, err
+}
+
+// SetTaskStatus changes the status of a single task.
+func (s *Store) SetTaskStatus(id, status string) (*Task, error) {
+	switch status {
+	case doryfile.TaskTodo, doryfile.TaskDoing, doryfile.TaskDone:
+	default:
+		return nil, fmt.Errorf("invalid task status %q (use todo, doing, or done)", status)
+	}
+
+	var result *Task
+	err := s.withWriteLock(func() error {
+		if err := s.open(); err != nil {
+			return err
+		}
+
+		existing, ok := s.df.Index.State.FindTask(id)
+		if !ok {
+			return fmt.Errorf("task %s not found", id)
+		}
+		task := *existing
+		task.Status = status
+		if err := s.df.AppendTask(&task); err != nil {
+			return err
+		}
+		converted := toTask(task)
+		result = &converted
+		return nil
+	})
+	return result, err
+}
+
+// Questions returns the session open questions, including answered ones.
+func (s *Store) Questions() ([]Question, error) {
+	if err := s.openLatest(); err != nil {
+		return nil, err
+	}
+	return toQuestions(s.df.Index.State.OpenQuestions), nil
+}
+
+// AddQuestion appends a new open question to the session state.
+func (s *Store) AddQuestion(text string) (*Question, error) {
+	if text == "" {
+		return nil, fmt.Errorf("question text is required")
+	}
+
+	var result *Question
+	err := s.withWriteLock(func() error {
+		if err := s.open(); err != nil {
+			return err
+		}
+
+		question := &doryfile.Question{
+			ID:     s.df.NextQuestionID(),
+			Text:   text,
+			Status: doryfile.QuestionOpen,
+		}
+		if err := s.df.AppendQuestion(question); err != nil {
+			return err
+		}
+		converted := toQuestion(*question)
+		result = &converted
+		return nil
+	})
+	return result, err
+}
+
+// AnswerQuestion marks a question answered, optionally linking the item that answers it.
+// An answered question is only answered again when reanswer is set.
+func (s *Store) AnswerQuestion(id, answer, answeredBy string, reanswer bool) (*Question, error) {
+	if answer == "" && answeredBy == "" {
+		return nil, fmt.Errorf("an answer or an answering item is required")
+	}
+
+	var result *Question
+	err := s.withWriteLock(func() error {
+		if err := s.open(); err != nil {
+			return err
+		}
+
+		existing, ok := s.df.Index.State.FindQuestion(id)
+		if !ok {
+			return fmt.Errorf("question %s not found", id)
+		}
+		if existing.Status == doryfile.QuestionAnswered && !reanswer {
+			return fmt.Errorf("question %s is already answered (use --reanswer to replace the answer)", existing.ID)
+		}
+		if answeredBy != "" {
+			id, err := resolveID(s.df.Entries(), answeredBy)
+			if err != nil {
+				return err
+			}
+			answeredBy = id
+		}
+
+		question := *existing
+		question.Status = doryfile.QuestionAnswered
+		question.Answer = answer
+		question.AnsweredBy = answeredBy
+		if err := s.df.AppendQuestion(&question); err != nil {
+			return err
+		}
+		converted := toQuestion(question)
+		result = &converted
+		return nil
+	})
+	return result, err
+}
+
+func toContextState(state *doryfile.State) *ContextState {
+	return &ContextState{
+		Goal:          state.Goal,
+		Progress:      state.Progress,
+		Blocker:       state.Blocker,
+		Next:          toTasks(state.Next),
+		OpenQuestions: toQuestions(state.OpenQuestions),
+		WorkingFiles:  append([]string(nil), state.WorkingFiles...),
+		LastUpdated:   state.LastUpdated,
+	}
+}
+
+func toTask(task doryfile.Task) Task {
+	return Task{ID: task.ID, Text: task.Text, Status: task.Status}
+}
+
+func toTasks(tasks []doryfile.Task) []Task {
+	if len(tasks) == 0 {
+		return nil
+	}
+	result := make([]Task, 0, len(tasks))
+	for _, task := range tasks {
+		result = append(result, toTask(task))
+	}
+	return result
+}
+
+func toQuestion(question doryfile.Question) Question {
+	return Question{
+		ID:         question.ID,
+		Text:       question.Text,
+		Status:     question.Status,
+		Answer:     question.Answer,
+		AnsweredBy: question.AnsweredBy,
+	}
+}
+
+func toQuestions(questions []doryfile.Question) []Question {
+	if len(questions) == 0 {
+		return nil
+	}
+	result := make([]Question, 0, len(questions))
+	for _, question := range questions {
+		result = append(result, toQuestion(question))
+	}
+	return result
+}
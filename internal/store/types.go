@@ -20,12 +20,16 @@ const (
 // Store manages dory knowledge in a single-file format.
 type Store struct {
 	Root string
-	df   *doryfile.DoryFile
+	// AllowMissingRefs keeps refs to unknown IDs instead of rejecting them.
+	AllowMissingRefs bool
+	df               *doryfile.DoryFile
 }
 
 // ListItem represents an item in list output.
 type ListItem struct {
 	ID        string          `json:"id" yaml:"id"`
+	Alias     string          `json:"alias,omitempty" yaml:"alias,omitempty"`
+	Pinned    bool            `json:"pinned,omitempty" yaml:"pinned,omitempty"`
 	Type      string          `json:"type" yaml:"type"`
 	Oneliner  string          `json:"oneliner" yaml:"oneliner"`
 	Topic     string          `json:"topic,omitempty" yaml:"topic,omitempty"`
@@ -33,6 +37,7 @@ type ListItem struct {
 	Severity  models.Severity `json:"severity,omitempty" yaml:"severity,omitempty"`
 	Created   string          `json:"created" yaml:"created"`
 	CreatedAt string          `json:"created_at" yaml:"created_at"`
+	Origin    string          `json:"origin,omitempty" yaml:"origin,omitempty"`
 }
 
 // TopicInfo represents a topic with its item count.
@@ -76,18 +81,71 @@ type ExpandResult struct {
 
 // ContextResult contains smart context for agent session start.
 type ContextResult struct {
-	Project  string        `json:"project" yaml:"project"`
-	State    *ContextState `json:"state,omitempty" yaml:"state,omitempty"`
-	Critical []ListItem    `json:"critical" yaml:"critical"`
-	Recent   []ListItem    `json:"recent" yaml:"recent"`
-	Topic    []ListItem    `json:"topic,omitempty" yaml:"topic,omitempty"`
+	Project  string         `json:"project" yaml:"project"`
+	State    *ContextState  `json:"state,omitempty" yaml:"state,omitempty"`
+	Pinned   []ListItem     `json:"pinned,omitempty" yaml:"pinned,omitempty"`
+	Critical []ListItem     `json:"critical" yaml:"critical"`
+	Recent   []ListItem     `json:"recent" yaml:"recent"`
+	Topic    []ListItem     `json:"topic,omitempty" yaml:"topic,omitempty"`
+	Budget   *BudgetResult  `json:"budget,omitempty" yaml:"budget,omitempty"`
+	Changes  *ChangesResult `json:"changes,omitempty" yaml:"changes,omitempty"`
+}
+
+// ChangesResult lists items relevant to the files being changed.
+type ChangesResult struct {
+	Paths []string     `json:"paths" yaml:"paths"`
+	Items []ChangeItem `json:"items" yaml:"items"`
+}
+
+// ChangeItem is an item relevant to changed files, with the reasons it matched.
+type ChangeItem struct {
+	ListItem `yaml:",inline"`
+	Reasons  []string `json:"reasons" yaml:"reasons"`
+}
+
+// BudgetResult is the ranked selection made for a token-budgeted context.
+// Pinned items are taken before ranking and are never omitted, so Used can
+// exceed Tokens when they alone do not fit.
+type BudgetResult struct {
+	Tokens   int            `json:"tokens" yaml:"tokens"`
+	Used     int            `json:"used" yaml:"used"`
+	Pinned   []BudgetedItem `json:"pinned,omitempty" yaml:"pinned,omitempty"`
+	Included []BudgetedItem `json:"included" yaml:"included"`
+	Omitted  []BudgetedItem `json:"omitted,omitempty" yaml:"omitted,omitempty"`
+}
+
+// BudgetedItem is a ranked candidate for a token-budgeted context.
+// Tokens counts the oneliner, plus the body when Body is set.
+type BudgetedItem struct {
+	ListItem `yaml:",inline"`
+	Score    float64 `json:"score" yaml:"score"`
+	Tokens   int     `json:"tokens" yaml:"tokens"`
+	Body     string  `json:"body,omitempty" yaml:"body,omitempty"`
 }
 
 // ContextState is session state for context output.
 type ContextState struct {
-	Goal        string   `json:"goal,omitempty" yaml:"goal,omitempty"`
-	Progress    string   `json:"progress,omitempty" yaml:"progress,omitempty"`
-	Blocker     string   `json:"blocker,omitempty" yaml:"blocker,omitempty"`
-	Next        []string `json:"next,omitempty" yaml:"next,omitempty"`
-	LastUpdated string   `json:"last_updated,omitempty" yaml:"last_updated,omitempty"`
+	Goal          string     `json:"goal,omitempty" yaml:"goal,omitempty"`
+	Progress      string     `json:"progress,omitempty" yaml:"progress,omitempty"`
+	Blocker       string     `json:"blocker,omitempty" yaml:"blocker,omitempty"`
+	Next          []Task     `json:"next,omitempty" yaml:"next,omitempty"`
+	OpenQuestions []Question `json:"open_questions,omitempty" yaml:"open_questions,omitempty"`
+	WorkingFiles  []string   `json:"working_files,omitempty" yaml:"working_files,omitempty"`
+	LastUpdated   string     `json:"last_updated,omitempty" yaml:"last_updated,omitempty"`
+}
+
+// Task is a trackable next step in session state.
+type Task struct {
+	ID     string `json:"id" yaml:"id"`
+	Text   string `json:"text" yaml:"text"`
+	Status string `json:"status" yaml:"status"`
+}
+
+// Question is an open question in session state.
+type Question struct {
+	ID         string `json:"id" yaml:"id"`
+	Text       string `json:"text" yaml:"text"`
+	Status     string `json:"status" yaml:"status"`
+	Answer     string `json:"answer,omitempty" yaml:"answer,omitempty"`
+	AnsweredBy string `json:"answered_by,omitempty" yaml:"answered_by,omitempty"`
 }
@@ -0,0 +1,226 @@
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/sibellavia/dory/internal/fileio"
+)
+
+const (
+	vectorsFile    = "vectors.json"
+	vectorsFormat  = "dory-vectors-v1"
+	embedBatchSize = 32
+)
+
+// Embedder turns texts into vectors, one per text. Name identifies the
+// vector space; cached vectors from a different embedder are discarded.
+type Embedder struct {
+	Name  string
+	Embed func(texts []string) ([][]float64, error)
+}
+
+// vectorCache is the on-disk embedding cache. Each vector is keyed by item
+// ID and by a hash of the embedded text, so edits invalidate it while
+// compaction (which only moves items in the log) does not.
+type vectorCache struct {
+	Format   string                 `json:"format"`
+	Embedder string                 `json:"embedder"`
+	Vectors  map[string]vectorEntry `json:"vectors"`
+}
+
+type vectorEntry struct {
+	Version string    `json:"version"`
+	Vector  []float64 `json:"vector"`
+}
+
+// SemanticSearch ranks items by cosine similarity between their embeddings
+// and the embedding of the query.
+func (s *Store) SemanticSearch(opts SearchOptions, embedder Embedder) ([]SearchHit, error) {
+	if opts.Query == "" {
+		return nil, fmt.Errorf("search query is empty")
+	}
+	if err := s.openLatest(); err != nil {
+		return nil, err
+	}
+
+	cache, err := s.syncVectors(embedder)
+	if err != nil {
+		return nil, err
+	}
+	queryVectors, err := embedder.Embed([]string{opts.Query})
+	if err != nil {
+		return nil, err
+	}
+	if len(queryVectors) != 1 {
+		return nil, fmt.Errorf("embedder returned %d vectors for the query", len(queryVectors))
+	}
+	query := queryVectors[0]
+
+	entries := s.df.Entries()
+	allow := searchFilter(opts, entries)
+	hits := make([]SearchHit, 0)
+	for id, cached := range cache.Vectors {
+		if !allow(id) {
+			continue
+		}
+		score, err := cosine(query, cached.Vector)
+		if err != nil {
+			return nil, err
+		}
+		if score <= 0 {
+			continue
+		}
+		hits = append(hits, SearchHit{ListItem: toListItem(id, entries[id]), Score: score})
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	if opts.Limit > 0 && len(hits) > opts.Limit {
+		hits = hits[:opts.Limit]
+	}
+	return hits, nil
+}
+
+// syncVectors embeds items that are new or changed since they were cached
+// and drops vectors of deleted items.
+func (s *Store) syncVectors(embedder Embedder) (*vectorCache, error) {
+	path := filepath.Join(s.Root, vectorsFile)
+	cache := loadVectorCache(path)
+	if cache.Embedder != embedder.Name {
+		cache = &vectorCache{Embedder: embedder.Name, Vectors: make(map[string]vectorEntry)}
+	}
+
+	entries := s.df.Entries()
+	changed := false
+	for id := range cache.Vectors {
+		if _, ok := entries[id]; !ok {
+			delete(cache.Vectors, id)
+			changed = true
+		}
+	}
+
+	var staleIDs, staleTexts, staleVersions []string
+	ids := make([]string, 0, len(entries))
+	for id := range entries {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		entry, err := s.df.Get(id)
+		if err != nil {
+			return nil, err
+		}
+		text := similarityText(entry.Oneliner, entry.Body)
+		version := textVersion(text)
+		if cached, ok := cache.Vectors[id]; ok && cached.Version == version {
+			continue
+		}
+		staleIDs = append(staleIDs, id)
+		staleTexts = append(staleTexts, text)
+		staleVersions = append(staleVersions, version)
+	}
+
+	for start := 0; start < len(staleIDs); start += embedBatchSize {
+		end := min(start+embedBatchSize, len(staleIDs))
+		vectors, err := embedder.Embed(staleTexts[start:end])
+		if err != nil {
+			// Keep what was embedded so far; the next search resumes from there.
+			if changed {
+				_ = saveVectorCache(path, cache)
+			}
+			return nil, err
+		}
+		if len(vectors) != end-start {
+			return nil, fmt.Errorf("embedder returned %d vectors for %d items", len(vectors), end-start)
+		}
+		for i, vector := range vectors {
+			cache.Vectors[staleIDs[start+i]] = vectorEntry{Version: staleVersions[start+i], Vector: vector}
+		}
+		changed = true
+	}
+
+	if changed {
+		if err := saveVectorCache(path, cache); err != nil {
+			return nil, err
+		}
+	}
+	return cache, nil
+}
+
+// pruneVectors drops cached vectors of items that no longer exist.
+func (s *Store) pruneVectors() error {
+	path := filepath.Join(s.Root, vectorsFile)
+	if _, err := os.Stat(path); err != nil {
+		return nil
+	}
+	cache := loadVectorCache(path)
+	entries := s.df.Entries()
+	changed := false
+	for id := range cache.Vectors {
+		if _, ok := entries[id]; !ok {
+			delete(cache.Vectors, id)
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return saveVectorCache(path, cache)
+}
+
+func loadVectorCache(path string) *vectorCache {
+	empty := &vectorCache{Vectors: make(map[string]vectorEntry)}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return empty
+	}
+	var cache vectorCache
+	if err := json.Unmarshal(data, &cache); err != nil || cache.Format != vectorsFormat || cache.Vectors == nil {
+		return empty
+	}
+	return &cache
+}
+
+func saveVectorCache(path string, cache *vectorCache) error {
+	cache.Format = vectorsFormat
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return err
+	}
+	if err := ignoreLocalFiles(filepath.Dir(path)); err != nil {
+		return err
+	}
+	return fileio.WriteFileAtomic(path, data, 0644)
+}
+
+func textVersion(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:16])
+}
+
+func cosine(a, b []float64) (float64, error) {
+	if len(a) != len(b) {
+		return 0, fmt.Errorf("embedding dimensions differ (%d vs %d); remove %s to re-embed", len(a), len(b), vectorsFile)
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0, nil
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB)), nil
+}
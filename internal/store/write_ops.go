@@ -23,13 +23,8 @@ func (s *Store) Learn(oneliner, topic string, severity models.Severity, body str
 			return err
 		}
 
-		fullBody := body
-		if fullBody == "" {
-			fullBody = fmt.Sprintf("# %s\n\n## Details\n\n(Add details here)\n", oneliner)
-		}
-
-		entry := newEntry(id, "lesson", oneliner, topic, "", string(severity), fullBody, refs)
-		if err := s.df.Append(entry); err != nil {
+		entry := newEntry(id, "lesson", oneliner, topic, "", string(severity), lessonBody(oneliner, body), refs)
+		if err := s.appendNew(entry); err != nil {
 			return fmt.Errorf("failed to append lesson: %w", err)
 		}
 		return nil
@@ -40,6 +35,14 @@ func (s *Store) Learn(oneliner, topic string, severity models.Severity, body str
 	return id, nil
 }
 
+// lessonBody returns body, or the lesson template when it is empty.
+func lessonBody(oneliner, body string) string {
+	if body == "" {
+		return fmt.Sprintf("# %s\n\n## Details\n\n(Add details here)\n", oneliner)
+	}
+	return body
+}
+
 // Decide adds a new decision.
 func (s *Store) Decide(oneliner, topic, rationale, body string, refs []string) (string, error) {
 	var id string
@@ -60,7 +63,7 @@ func (s *Store) Decide(oneliner, topic, rationale, body string, refs []string) (
 		}
 
 		entry := newEntry(id, "decision", oneliner, topic, "", "", fullBody, refs)
-		if err := s.df.Append(entry); err != nil {
+		if err := s.appendNew(entry); err != nil {
 			return fmt.Errorf("failed to append decision: %w", err)
 		}
 		return nil
@@ -91,7 +94,7 @@ func (s *Store) Convention(oneliner, domain, body string, refs []string) (string
 		}
 
 		entry := newEntry(id, "convention", oneliner, "", domain, "", fullBody, refs)
-		if err := s.df.Append(entry); err != nil {
+		if err := s.appendNew(entry); err != nil {
 			return fmt.Errorf("failed to append convention: %w", err)
 		}
 		return nil
@@ -122,7 +125,7 @@ func (s *Store) CreateCustom(itemType, oneliner, topic, body string, refs []stri
 		}
 
 		entry := newEntry(id, itemType, oneliner, topic, "", "", fullBody, refs)
-		if err := s.df.Append(entry); err != nil {
+		if err := s.appendNew(entry); err != nil {
 			return fmt.Errorf("failed to append %s: %w", itemType, err)
 		}
 		return nil
@@ -133,12 +136,51 @@ func (s *Store) CreateCustom(itemType, oneliner, topic, body string, refs []stri
 	return id, nil
 }
 
+// CreateEntry adds entry as a new item with a fresh ID for its type. A zero
+// Created is set to now; importers set it to keep the source's date.
+func (s *Store) CreateEntry(entry *doryfile.Entry) (string, error) {
+	err := s.withWriteLock(func() error {
+		if err := s.open(); err != nil {
+			return err
+		}
+		id, err := idgen.NewItemID(entry.Type)
+		if err != nil {
+			return err
+		}
+		entry.ID = id
+		if entry.Created.IsZero() {
+			entry.Created = time.Now().UTC()
+		}
+		if err := s.appendNew(entry); err != nil {
+			return fmt.Errorf("failed to append %s: %w", entry.Type, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return entry.ID, nil
+}
+
 // UpdateEntry appends a new version of an existing entry.
 func (s *Store) UpdateEntry(entry *doryfile.Entry) error {
 	return s.withWriteLock(func() error {
 		if err := s.open(); err != nil {
 			return err
 		}
+		existing := s.df.Entries()[entry.ID]
+		var existingRefs []string
+		if existing != nil {
+			existingRefs = existing.Refs
+		}
+		refs, err := s.resolveRefs(entry.Refs, existingRefs)
+		if err != nil {
+			return err
+		}
+		entry.Refs = refs
+		if entry.Alias == "" && existing != nil {
+			entry.Alias = existing.Alias
+		}
 		return s.df.Append(entry)
 	})
 }
@@ -153,6 +195,59 @@ func (s *Store) Remove(id string) error {
 	})
 }
 
+// ReferencedBy returns the items whose refs include id.
+func (s *Store) ReferencedBy(id string) ([]ListItem, error) {
+	if err := s.openLatest(); err != nil {
+		return nil, err
+	}
+	entries := s.df.Entries()
+	items := make([]ListItem, 0)
+	for _, refID := range reverseRefs(entries)[id] {
+		if refID != id {
+			items = append(items, toListItem(refID, entries[refID]))
+		}
+	}
+	return items, nil
+}
+
+// RemoveUnlinking deletes an item and strips its ID from the refs of every
+// item that references it. The ref updates and the delete are written as one
+// batch, so the log and index never hold a partial unlink. It returns the
+// IDs of the items that were unlinked.
+func (s *Store) RemoveUnlinking(id string) ([]string, error) {
+	var unlinked []string
+	err := s.withWriteLock(func() error {
+		if err := s.open(); err != nil {
+			return err
+		}
+		if _, ok := s.df.Entries()[id]; !ok {
+			return fmt.Errorf("item %s not found", id)
+		}
+		var events []doryfile.Event
+		for _, refID := range reverseRefs(s.df.Entries())[id] {
+			if refID == id {
+				continue
+			}
+			entry, err := s.df.Get(refID)
+			if err != nil {
+				return err
+			}
+			refs := make([]string, 0, len(entry.Refs))
+			for _, ref := range entry.Refs {
+				if ref != id {
+					refs = append(refs, ref)
+				}
+			}
+			entry.Refs = refs
+			events = append(events, doryfile.Event{Op: doryfile.OpItemUpdate, Item: entry})
+			unlinked = append(unlinked, refID)
+		}
+		events = append(events, doryfile.Event{Op: doryfile.OpItemDelete, ID: id})
+		return s.df.AppendEvents(events)
+	})
+	return unlinked, err
+}
+
 // UpdateStatus updates the session state and returns the full state after update.
 func (s *Store) UpdateStatus(goal, progress, blocker string, next, workingFiles, openQuestions []string) (*ContextState, error) {
 	var result *ContextState
@@ -176,13 +271,19 @@ func (s *Store) UpdateStatus(goal, progress, blocker string, next, workingFiles,
 			state.Blocker = blocker
 		}
 		if len(next) > 0 {
-			state.Next = next
+			state.Next = make([]doryfile.Task, 0, len(next))
+			for _, text := range next {
+				state.Next = append(state.Next, doryfile.Task{Text: text, Status: doryfile.TaskTodo})
+			}
 		}
 		if len(workingFiles) > 0 {
 			state.WorkingFiles = workingFiles
 		}
 		if len(openQuestions) > 0 {
-			state.OpenQuestions = openQuestions
+			state.OpenQuestions = make([]doryfile.Question, 0, len(openQuestions))
+			for _, text := range openQuestions {
+				state.OpenQuestions = append(state.OpenQuestions, doryfile.Question{Text: text, Status: doryfile.QuestionOpen})
+			}
 		}
 		state.LastUpdated = time.Now().UTC().Format(time.RFC3339)
 
@@ -190,14 +291,8 @@ func (s *Store) UpdateStatus(goal, progress, blocker string, next, workingFiles,
 			return err
 		}
 
-		// Return the full state
-		result = &ContextState{
-			Goal:        state.Goal,
-			Progress:    state.Progress,
-			Blocker:     state.Blocker,
-			Next:        state.Next,
-			LastUpdated: state.LastUpdated,
-		}
+		// Return the full state, including IDs assigned on write.
+		result = toContextState(s.df.Index.State)
 		return nil
 	})
 	return result, err
@@ -209,7 +304,13 @@ func (s *Store) Compact() error {
 		if err := s.open(); err != nil {
 			return err
 		}
-		return s.df.Compact()
+		if err := s.df.Compact(); err != nil {
+			return err
+		}
+		if err := s.removeSearchIndex(); err != nil {
+			return err
+		}
+		return s.pruneVectors()
 	})
 }
 